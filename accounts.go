@@ -1,6 +1,7 @@
 package intuit
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,27 +13,15 @@ const (
 	AccountStatusInactive = "INACTIVE"
 )
 
-// Constants representing aggregation status codes.
-// See https://developer.intuit.com/docs/0020_customeraccountdata/0000_api/0700_error_codes#/Error_Code_and_Messages_with_Resolution
+// Constants representing the kind of an account, given by which of the type
+// fields CAD reports for it
 const (
-	AggrStatusOK                        string = "0"
-	AggrStatusUnknown                          = "100"
-	AggrStatusGeneralError                     = "101"
-	AggrStatusAggrError                        = "102"
-	AggrStatusLoginError                       = "103"
-	AggrStatusJSONParsingError                 = "104"
-	AggrStatusUnavailable                      = "105"
-	AggrStatusAccountMismatch                  = "106"
-	AggrStatusEndUserActionRequired            = "108"
-	AggrStatusPasswordChangeRequired           = "109"
-	AggrStatusFinancialInstitutionError        = "155"
-	AggrStatusApplicationError                 = "163"
-	AggrStatusMultipleLogins                   = "179"
-	AggrStatusMFARequired                      = "185"
-	AggrStatusIncorrectMFAAnswer               = "187"
-	AggrStatusInvalidPersonalAccessCode        = "199"
-	AggrStatusDuplicateAccount                 = "323"
-	AggrStatusAccountNumberChanged             = "324"
+	AccountKindBanking    = "banking"
+	AccountKindCredit     = "credit"
+	AccountKindInvestment = "investment"
+	AccountKindLoan       = "loan"
+	AccountKindRewards    = "rewards"
+	AccountKindOther      = "other"
 )
 
 type accountList struct {
@@ -41,17 +30,25 @@ type accountList struct {
 
 // Account is an account at a financial institution
 type Account struct {
-	ID                     int64               `json:"accountId"`
-	LoginID                int64               `json:"institutionLoginId"`
-	Name                   string              `json:"accountNickname"`
-	Balance                float64             `json:"balanceAmount"`
-	BalanceDate            unixTimestampMillis `json:"balanceDate"`
-	Status                 string              `json:"status"`
-	AggrSuccessDate        unixTimestampMillis `json:"aggrSuccessDate"`
-	AggrAttemptDate        unixTimestampMillis `json:"aggrAttemptDate"`
-	AggrStatusCode         string              `json:"aggrStatusCode"`
-	Currency               string              `json:"currencyCode"`
-	FinancialInstitutionID int64               `json:"institutionId"`
+	ID                     int64             `json:"accountId"`
+	LoginID                int64             `json:"institutionLoginId"`
+	Name                   string            `json:"accountNickname"`
+	Balance                Money             `json:"balanceAmount"`
+	BalanceDate            Timestamp         `json:"balanceDate"`
+	Status                 string            `json:"status"`
+	AggrSuccessDate        Timestamp         `json:"aggrSuccessDate"`
+	AggrAttemptDate        Timestamp         `json:"aggrAttemptDate"`
+	AggrStatusCode         AggregationStatus `json:"aggrStatusCode"`
+	Currency               string            `json:"currencyCode"`
+	FinancialInstitutionID int64             `json:"institutionId"`
+
+	// CAD reports at most one of these, depending on the kind of account,
+	// such as "CHECKING" for a checking account or "MORTGAGE" for a mortgage
+	BankingAccountType    string `json:"bankingAccountType,omitempty"`
+	CreditAccountType     string `json:"creditAccountType,omitempty"`
+	InvestmentAccountType string `json:"investmentAccountType,omitempty"`
+	LoanType              string `json:"loanType,omitempty"`
+	RewardsAccountType    string `json:"rewardsAccountType,omitempty"`
 }
 
 // IsActive returns true if the account status is active
@@ -59,25 +56,56 @@ func (a Account) IsActive() bool {
 	return a.Status == AccountStatusActive
 }
 
+// Kind returns the kind of the account, or AccountKindOther if CAD reports no
+// type for it
+func (a Account) Kind() string {
+	switch {
+	case a.BankingAccountType != "":
+		return AccountKindBanking
+	case a.CreditAccountType != "":
+		return AccountKindCredit
+	case a.InvestmentAccountType != "":
+		return AccountKindInvestment
+	case a.LoanType != "":
+		return AccountKindLoan
+	case a.RewardsAccountType != "":
+		return AccountKindRewards
+	}
+
+	return AccountKindOther
+}
+
 // GetCustomerAccounts returns all accounts for a customer across all of their
 // logins
-func (c *Client) GetCustomerAccounts() ([]Account, error) {
+func (c *Client) GetCustomerAccounts(opts ...RequestOption) ([]Account, error) {
+	return c.GetCustomerAccountsContext(context.Background(), opts...)
+}
+
+// GetCustomerAccountsContext is like GetCustomerAccounts, but sends the request
+// with ctx
+func (c *Client) GetCustomerAccountsContext(ctx context.Context, opts ...RequestOption) (accounts []Account, err error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	ctx, span := c.startSpan(ctx, "GetCustomerAccounts", SpanKindInternal)
+	defer func() { endSpan(span, err) }()
+
 	req, err := c.request("GET", "/accounts", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.do(req)
+	resp, err := c.do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("CAD API returned status code %d", resp.StatusCode)
+		return nil, newAPIError(resp)
 	}
 
-	defer resp.Body.Close()
-
 	decoder := json.NewDecoder(resp.Body)
 	decoder.UseNumber()
 
@@ -90,23 +118,79 @@ func (c *Client) GetCustomerAccounts() ([]Account, error) {
 }
 
 // GetLoginAccounts returns all accounts for a login
-func (c *Client) GetLoginAccounts(loginID int64) ([]Account, error) {
+func (c *Client) GetLoginAccounts(loginID int64, opts ...RequestOption) ([]Account, error) {
+	return c.GetLoginAccountsContext(context.Background(), loginID, opts...)
+}
+
+// GetLoginAccountsContext is like GetLoginAccounts, but sends the request with
+// ctx
+func (c *Client) GetLoginAccountsContext(ctx context.Context, loginID int64, opts ...RequestOption) (accounts []Account, err error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	ctx, span := c.startSpan(ctx, "GetLoginAccounts", SpanKindInternal,
+		SpanAttribute{"intuit.login_id", loginID})
+	defer func() { endSpan(span, err) }()
+
 	req, err := c.request("GET", fmt.Sprintf("/logins/%d/accounts", loginID), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.do(req)
+	resp, err := c.do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("CAD API returned status code %d", resp.StatusCode)
+		return nil, newAPIError(resp)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	payload := accountList{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	return payload.Accounts, nil
+}
+
+// RefreshLogin asks CAD to aggregate the login's accounts again and returns
+// them. Aggregation may continue after the call returns; poll the accounts'
+// AggrAttemptDate and AggrStatusCode to learn when it finishes.
+func (c *Client) RefreshLogin(loginID int64, opts ...RequestOption) ([]Account, error) {
+	return c.RefreshLoginContext(context.Background(), loginID, opts...)
+}
+
+// RefreshLoginContext is like RefreshLogin, but sends the request with ctx
+func (c *Client) RefreshLoginContext(ctx context.Context, loginID int64, opts ...RequestOption) (accounts []Account, err error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	ctx, span := c.startSpan(ctx, "RefreshLogin", SpanKindInternal,
+		SpanAttribute{"intuit.login_id", loginID})
+	defer func() { endSpan(span, err) }()
+
+	req, err := c.request("PUT", fmt.Sprintf("/logins/%d?refresh=true", loginID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
 	}
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newAPIError(resp)
+	}
+
 	decoder := json.NewDecoder(resp.Body)
 	decoder.UseNumber()
 
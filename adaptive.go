@@ -0,0 +1,185 @@
+package intuit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// concurrencyLimiter bounds the number of requests a client has in flight. The
+// function returned by acquire releases the slot and reports the outcome of
+// the request sent in it.
+type concurrencyLimiter interface {
+	acquire(ctx context.Context) (func(*http.Response, error), error)
+}
+
+// semaphore is a concurrencyLimiter with a fixed limit
+type semaphore chan struct{}
+
+func (s semaphore) acquire(ctx context.Context) (func(*http.Response, error), error) {
+	select {
+	case s <- struct{}{}:
+		return func(*http.Response, error) { <-s }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// AdaptiveConcurrency is an AIMD concurrency controller. Its limit grows by
+// about one request per round trip while responses are healthy, and halves
+// when a request is throttled (429), fails with a 5xx or network error, or
+// takes longer than LatencyTarget. This finds the highest concurrency Intuit
+// will sustain without configuring a fixed pool size.
+//
+// An AdaptiveConcurrency may be shared by several clients, so that they adapt
+// to a common limit.
+type AdaptiveConcurrency struct {
+	// Min and Max bound the limit. Min defaults to 1 and Max to 64.
+	Min int
+	Max int
+
+	// LatencyTarget, if positive, treats slower responses as a sign of
+	// overload
+	LatencyTarget time.Duration
+
+	mu           sync.Mutex
+	limit        float64
+	inflight     int
+	lastDecrease time.Time
+	wake         chan struct{}
+}
+
+// NewAdaptiveConcurrency returns a controller whose limit starts at min and
+// varies between min and max
+func NewAdaptiveConcurrency(min, max int) *AdaptiveConcurrency {
+	return &AdaptiveConcurrency{Min: min, Max: max}
+}
+
+// WithAdaptiveConcurrency limits the number of requests the client has in
+// flight with `a`, in place of a fixed limit set by WithMaxConcurrency
+func WithAdaptiveConcurrency(a *AdaptiveConcurrency) Option {
+	return func(c *Client) {
+		c.concurrency = a
+	}
+}
+
+// Limit returns the current concurrency limit
+func (a *AdaptiveConcurrency) Limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.init()
+
+	return int(a.limit)
+}
+
+// max returns the highest the limit can grow to
+func (a *AdaptiveConcurrency) max() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.init()
+
+	return a.Max
+}
+
+// Acquire blocks until fewer than Limit requests are in flight, or ctx is
+// done. The returned function must be called with the outcome of the request
+// once it completes; bulk helpers call it with a nil response and error for
+// work that isn't a single request.
+func (a *AdaptiveConcurrency) Acquire(ctx context.Context) (func(*http.Response, error), error) {
+	return a.acquire(ctx)
+}
+
+func (a *AdaptiveConcurrency) acquire(ctx context.Context) (func(*http.Response, error), error) {
+	for {
+		a.mu.Lock()
+		a.init()
+
+		if a.inflight < int(a.limit) {
+			a.inflight++
+			a.mu.Unlock()
+
+			start := time.Now()
+			var once sync.Once
+
+			return func(resp *http.Response, err error) {
+				once.Do(func() { a.release(start, resp, err) })
+			}, nil
+		}
+
+		wake := a.wake
+		a.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// init applies the defaults. Callers must hold a.mu.
+func (a *AdaptiveConcurrency) init() {
+	if a.Min < 1 {
+		a.Min = 1
+	}
+	if a.Max == 0 {
+		a.Max = 64
+	}
+	if a.Max < a.Min {
+		a.Max = a.Min
+	}
+	if a.limit < float64(a.Min) {
+		a.limit = float64(a.Min)
+	}
+	if a.wake == nil {
+		a.wake = make(chan struct{})
+	}
+}
+
+func (a *AdaptiveConcurrency) release(start time.Time, resp *http.Response, err error) {
+	latency := time.Since(start)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.inflight--
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		// the caller gave up; this says nothing about the API's health
+	case a.overloaded(resp, err, latency):
+		// only decrease once per round of requests sent under the current
+		// limit, so that a burst of failures doesn't collapse it to Min
+		if start.After(a.lastDecrease) {
+			a.limit /= 2
+			if a.limit < float64(a.Min) {
+				a.limit = float64(a.Min)
+			}
+			a.lastDecrease = time.Now()
+		}
+	default:
+		a.limit += 1 / a.limit
+		if a.limit > float64(a.Max) {
+			a.limit = float64(a.Max)
+		}
+	}
+
+	close(a.wake)
+	a.wake = make(chan struct{})
+}
+
+func (a *AdaptiveConcurrency) overloaded(resp *http.Response, err error, latency time.Duration) bool {
+	if err != nil {
+		return true
+	}
+
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500) {
+		return true
+	}
+
+	return a.LatencyTarget > 0 && latency > a.LatencyTarget
+}
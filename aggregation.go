@@ -0,0 +1,114 @@
+package intuit
+
+import "time"
+
+// ReaggregationAction is what should happen to an account after an aggregation
+// attempt finishes with a given status code.
+type ReaggregationAction int
+
+// Constants representing re-aggregation actions
+const (
+	// ReaggregationNone means the account aggregated successfully and can be
+	// refreshed on its normal schedule.
+	ReaggregationNone ReaggregationAction = iota
+
+	// ReaggregationRetry means the failure is transient and aggregation should
+	// be retried after the rule's delay.
+	ReaggregationRetry
+
+	// ReaggregationUserAction means aggregation will keep failing until the end
+	// user updates credentials or answers a challenge. Refreshing should stop
+	// until they do.
+	ReaggregationUserAction
+
+	// ReaggregationEscalate means the failure is not something the end user can
+	// fix and should be reported to operators.
+	ReaggregationEscalate
+)
+
+func (a ReaggregationAction) String() string {
+	switch a {
+	case ReaggregationNone:
+		return "none"
+	case ReaggregationRetry:
+		return "retry"
+	case ReaggregationUserAction:
+		return "user-action"
+	case ReaggregationEscalate:
+		return "escalate"
+	}
+
+	return "unknown"
+}
+
+// ReaggregationRule pairs an action with the delay before the account should be
+// aggregated again. Delay is only meaningful for ReaggregationRetry.
+type ReaggregationRule struct {
+	Action ReaggregationAction
+	Delay  time.Duration
+}
+
+// ReaggregationPolicy maps aggregation status codes to rules. Status codes that
+// are not present in Rules use Default.
+type ReaggregationPolicy struct {
+	Rules   map[AggregationStatus]ReaggregationRule
+	Default ReaggregationRule
+}
+
+// DefaultReaggregationPolicy returns the policy that RefreshCoordinator and
+// Watcher use when their Policy is nil:
+//
+//   - 0 (OK) needs no action
+//   - 100, 101, 102 and 105 (unknown, general, aggregation errors and
+//     institution unavailable) are retried after 30 minutes
+//   - 155 (financial institution error) is retried after 4 hours
+//   - 103, 108, 109, 179, 185, 187 and 199 (login, MFA and other end-user
+//     problems) require user action
+//   - everything else, including 104, 106, 163, 323 and 324, is escalated
+//
+// The returned policy is a copy and may be modified freely.
+func DefaultReaggregationPolicy() ReaggregationPolicy {
+	retry := ReaggregationRule{Action: ReaggregationRetry, Delay: time.Minute * 30}
+	userAction := ReaggregationRule{Action: ReaggregationUserAction}
+
+	return ReaggregationPolicy{
+		Rules: map[AggregationStatus]ReaggregationRule{
+			AggrStatusOK: {Action: ReaggregationNone},
+
+			AggrStatusUnknown:                   retry,
+			AggrStatusGeneralError:              retry,
+			AggrStatusAggrError:                 retry,
+			AggrStatusUnavailable:               retry,
+			AggrStatusFinancialInstitutionError: {Action: ReaggregationRetry, Delay: time.Hour * 4},
+
+			AggrStatusLoginError:                userAction,
+			AggrStatusEndUserActionRequired:     userAction,
+			AggrStatusPasswordChangeRequired:    userAction,
+			AggrStatusMultipleLogins:            userAction,
+			AggrStatusMFARequired:               userAction,
+			AggrStatusIncorrectMFAAnswer:        userAction,
+			AggrStatusInvalidPersonalAccessCode: userAction,
+		},
+		Default: ReaggregationRule{Action: ReaggregationEscalate},
+	}
+}
+
+// Rule returns the rule for the given aggregation status code. Unless Rules
+// says otherwise, the empty status of an account that has not been aggregated
+// yet needs no action.
+func (p ReaggregationPolicy) Rule(status AggregationStatus) ReaggregationRule {
+	if rule, ok := p.Rules[status]; ok {
+		return rule
+	}
+
+	if status == "" {
+		return ReaggregationRule{Action: ReaggregationNone}
+	}
+
+	return p.Default
+}
+
+// ForAccount returns the rule for the account's most recent aggregation status
+func (p ReaggregationPolicy) ForAccount(a Account) ReaggregationRule {
+	return p.Rule(a.AggrStatusCode)
+}
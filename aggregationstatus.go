@@ -0,0 +1,114 @@
+package intuit
+
+// AggregationStatus is the status code of an account's most recent
+// aggregation attempt
+type AggregationStatus string
+
+// Constants representing aggregation status codes.
+// See https://developer.intuit.com/docs/0020_customeraccountdata/0000_api/0700_error_codes#/Error_Code_and_Messages_with_Resolution
+const (
+	AggrStatusOK                        AggregationStatus = "0"
+	AggrStatusUnknown                   AggregationStatus = "100"
+	AggrStatusGeneralError              AggregationStatus = "101"
+	AggrStatusAggrError                 AggregationStatus = "102"
+	AggrStatusLoginError                AggregationStatus = "103"
+	AggrStatusJSONParsingError          AggregationStatus = "104"
+	AggrStatusUnavailable               AggregationStatus = "105"
+	AggrStatusAccountMismatch           AggregationStatus = "106"
+	AggrStatusEndUserActionRequired     AggregationStatus = "108"
+	AggrStatusPasswordChangeRequired    AggregationStatus = "109"
+	AggrStatusFinancialInstitutionError AggregationStatus = "155"
+	AggrStatusApplicationError          AggregationStatus = "163"
+	AggrStatusMultipleLogins            AggregationStatus = "179"
+	AggrStatusMFARequired               AggregationStatus = "185"
+	AggrStatusIncorrectMFAAnswer        AggregationStatus = "187"
+	AggrStatusInvalidPersonalAccessCode AggregationStatus = "199"
+	AggrStatusDuplicateAccount          AggregationStatus = "323"
+	AggrStatusAccountNumberChanged      AggregationStatus = "324"
+)
+
+var aggregationStatusMessages = map[AggregationStatus]string{
+	AggrStatusOK:                        "Aggregation succeeded",
+	AggrStatusUnknown:                   "An unknown error occurred during aggregation",
+	AggrStatusGeneralError:              "A general error occurred during aggregation",
+	AggrStatusAggrError:                 "The account could not be aggregated",
+	AggrStatusLoginError:                "The login credentials were rejected by the financial institution",
+	AggrStatusJSONParsingError:          "The request could not be parsed",
+	AggrStatusUnavailable:               "The financial institution is temporarily unavailable",
+	AggrStatusAccountMismatch:           "The account no longer matches the account at the financial institution",
+	AggrStatusEndUserActionRequired:     "The end user must sign in to the financial institution's website to resolve an issue",
+	AggrStatusPasswordChangeRequired:    "The financial institution requires the end user to change their password",
+	AggrStatusFinancialInstitutionError: "The financial institution returned an error",
+	AggrStatusApplicationError:          "An application error occurred",
+	AggrStatusMultipleLogins:            "The financial institution reported multiple simultaneous logins",
+	AggrStatusMFARequired:               "The financial institution requires a multi-factor authentication challenge to be answered",
+	AggrStatusIncorrectMFAAnswer:        "The answer to the multi-factor authentication challenge was incorrect",
+	AggrStatusInvalidPersonalAccessCode: "The personal access code is invalid",
+	AggrStatusDuplicateAccount:          "The account duplicates an account that is already linked",
+	AggrStatusAccountNumberChanged:      "The account number has changed at the financial institution",
+}
+
+// IsError returns true if the status reports a failed aggregation. An empty
+// status, as on accounts that have not been aggregated yet, is not an error.
+func (s AggregationStatus) IsError() bool {
+	return s != AggrStatusOK && s != ""
+}
+
+// NeedsUserAction returns true if aggregation will keep failing until the end
+// user updates their credentials or answers a challenge
+func (s AggregationStatus) NeedsUserAction() bool {
+	switch s {
+	case AggrStatusLoginError,
+		AggrStatusEndUserActionRequired,
+		AggrStatusPasswordChangeRequired,
+		AggrStatusMultipleLogins,
+		AggrStatusMFARequired,
+		AggrStatusIncorrectMFAAnswer,
+		AggrStatusInvalidPersonalAccessCode:
+		return true
+	}
+
+	return false
+}
+
+// NeedsCredentialUpdate returns true if the end user must submit new
+// credentials for the login
+func (s AggregationStatus) NeedsCredentialUpdate() bool {
+	switch s {
+	case AggrStatusLoginError,
+		AggrStatusPasswordChangeRequired,
+		AggrStatusInvalidPersonalAccessCode:
+		return true
+	}
+
+	return false
+}
+
+// IsRetryable returns true if the failure is transient and aggregating again
+// later may succeed
+func (s AggregationStatus) IsRetryable() bool {
+	switch s {
+	case AggrStatusUnknown,
+		AggrStatusGeneralError,
+		AggrStatusAggrError,
+		AggrStatusUnavailable,
+		AggrStatusFinancialInstitutionError:
+		return true
+	}
+
+	return false
+}
+
+// Message returns a description of the status suitable for showing to users,
+// based on Intuit's error code table
+func (s AggregationStatus) Message() string {
+	if message, ok := aggregationStatusMessages[s]; ok {
+		return message
+	}
+
+	return "Unrecognized aggregation status " + string(s)
+}
+
+func (s AggregationStatus) String() string {
+	return string(s)
+}
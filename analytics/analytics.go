@@ -0,0 +1,327 @@
+// Package analytics rolls transactions up by category, payee and month.
+//
+// Amounts are summed exactly with intuit.Money. Transactions in different
+// currencies are never added together: each rollup is kept per currency, and
+// can be combined into one currency with ConvertSummaries or ConvertCashflow.
+// Pending transactions are skipped, since their amounts may still change.
+package analytics
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+// Uncategorized is the category of transactions no categorization context
+// assigns a category to
+const Uncategorized = "Uncategorized"
+
+// Totals are the sums of a group of transactions. Outflow is the sum of the
+// negative amounts, so it is zero or negative, and Net is Inflow + Outflow.
+type Totals struct {
+	Currency string
+	Count    int
+	Inflow   intuit.Money
+	Outflow  intuit.Money
+	Net      intuit.Money
+}
+
+func (t *Totals) add(txn intuit.Transaction) {
+	t.Count++
+	t.Net += txn.Amount
+
+	if txn.Amount < 0 {
+		t.Outflow += txn.Amount
+	} else {
+		t.Inflow += txn.Amount
+	}
+}
+
+// Convert returns the totals converted to the currency `to` with `conv`, or
+// with intuit.NopCurrencyConverter if `conv` is nil
+func (t Totals) Convert(ctx context.Context, conv intuit.CurrencyConverter, to string) (Totals, error) {
+	inflow, err := intuit.ConvertMoney(ctx, conv, t.Inflow, t.Currency, to)
+	if err != nil {
+		return Totals{}, err
+	}
+
+	outflow, err := intuit.ConvertMoney(ctx, conv, t.Outflow, t.Currency, to)
+	if err != nil {
+		return Totals{}, err
+	}
+
+	return Totals{Currency: to, Count: t.Count, Inflow: inflow, Outflow: outflow, Net: inflow + outflow}, nil
+}
+
+func (t *Totals) merge(other Totals) {
+	t.Count += other.Count
+	t.Inflow += other.Inflow
+	t.Outflow += other.Outflow
+	t.Net += other.Net
+}
+
+// Summary totals the transactions of a category or payee
+type Summary struct {
+	Key string
+	Totals
+}
+
+// Cashflow totals the transactions posted in a month
+type Cashflow struct {
+	Year  int
+	Month time.Month
+	Totals
+}
+
+// SummarizeByCategory totals the transactions by the category of their
+// primary categorization context, or Uncategorized. Summaries are ordered by
+// net amount, largest outflow first.
+func SummarizeByCategory(txns []intuit.Transaction) []Summary {
+	return summarize(txns, func(t intuit.Transaction) string {
+		if category := t.Categorization.PrimaryCategory(); category != "" {
+			return category
+		}
+
+		return Uncategorized
+	})
+}
+
+// SummarizeByPayee totals the transactions by their normalized payee name,
+// falling back to the payee name reported by the institution. Summaries are
+// ordered by net amount, largest outflow first.
+func SummarizeByPayee(txns []intuit.Transaction) []Summary {
+	return summarize(txns, payee)
+}
+
+// MonthlyCashflow totals the transactions by the month of their posted day
+// (in UTC, as with Transaction.PostedDay), in chronological order. Months
+// without transactions are omitted.
+func MonthlyCashflow(txns []intuit.Transaction) []Cashflow {
+	type month struct {
+		year     int
+		month    time.Month
+		currency string
+	}
+
+	groups := map[month]*Cashflow{}
+	for _, t := range txns {
+		if t.Pending {
+			continue
+		}
+
+		day := t.PostedDay()
+		key := month{day.Year, day.Month, t.CurrencyType}
+
+		flow, ok := groups[key]
+		if !ok {
+			flow = &Cashflow{Year: day.Year, Month: day.Month, Totals: Totals{Currency: t.CurrencyType}}
+			groups[key] = flow
+		}
+		flow.add(t)
+	}
+
+	flows := make([]Cashflow, 0, len(groups))
+	for _, flow := range groups {
+		flows = append(flows, *flow)
+	}
+
+	sortCashflow(flows)
+
+	return flows
+}
+
+// sortCashflow orders cashflow chronologically
+func sortCashflow(flows []Cashflow) {
+	sort.Slice(flows, func(i, j int) bool {
+		a, b := flows[i], flows[j]
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		if a.Month != b.Month {
+			return a.Month < b.Month
+		}
+		return a.Currency < b.Currency
+	})
+}
+
+// summarize totals the posted transactions by the key `keyFn` gives them
+func summarize(txns []intuit.Transaction, keyFn func(intuit.Transaction) string) []Summary {
+	type group struct {
+		key      string
+		currency string
+	}
+
+	groups := map[group]*Summary{}
+	for _, t := range txns {
+		if t.Pending {
+			continue
+		}
+
+		key := group{keyFn(t), t.CurrencyType}
+
+		summary, ok := groups[key]
+		if !ok {
+			summary = &Summary{Key: key.key, Totals: Totals{Currency: t.CurrencyType}}
+			groups[key] = summary
+		}
+		summary.add(t)
+	}
+
+	summaries := make([]Summary, 0, len(groups))
+	for _, summary := range groups {
+		summaries = append(summaries, *summary)
+	}
+
+	sortSummaries(summaries)
+
+	return summaries
+}
+
+// sortSummaries orders summaries by net amount, largest outflow first
+func sortSummaries(summaries []Summary) {
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.Net != b.Net {
+			return a.Net < b.Net
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Currency < b.Currency
+	})
+}
+
+// payee returns the name transactions are grouped under by SummarizeByPayee
+func payee(t intuit.Transaction) string {
+	if name := strings.TrimSpace(t.Categorization.Common.NormalizedPayeeName); name != "" {
+		return name
+	}
+
+	return strings.TrimSpace(t.PayeeName)
+}
+
+// TaxSummary totals the transactions of a Schedule C line
+type TaxSummary struct {
+	Category intuit.TaxCategory
+	Line     string
+	Totals
+}
+
+// SummarizeScheduleC totals the transactions by the Schedule C line their
+// categorization assigns, for small-business tax preparation. Transactions
+// without a known line are skipped. Summaries are in form order.
+func SummarizeScheduleC(txns []intuit.Transaction) []TaxSummary {
+	type group struct {
+		category intuit.TaxCategory
+		currency string
+	}
+
+	groups := map[group]*TaxSummary{}
+	for _, t := range txns {
+		if t.Pending {
+			continue
+		}
+
+		category, ok := t.Categorization.TaxCategory()
+		if !ok {
+			continue
+		}
+
+		key := group{category, t.CurrencyType}
+
+		summary, ok := groups[key]
+		if !ok {
+			summary = &TaxSummary{Category: category, Line: category.Line(), Totals: Totals{Currency: t.CurrencyType}}
+			groups[key] = summary
+		}
+		summary.add(t)
+	}
+
+	order := make(map[intuit.TaxCategory]int, len(intuit.TaxCategories))
+	for i, category := range intuit.TaxCategories {
+		order[category] = i
+	}
+
+	summaries := make([]TaxSummary, 0, len(groups))
+	for _, summary := range groups {
+		summaries = append(summaries, *summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.Category != b.Category {
+			return order[a.Category] < order[b.Category]
+		}
+		return a.Currency < b.Currency
+	})
+
+	return summaries
+}
+
+// ConvertSummaries converts the summaries to the currency `to` with `conv`,
+// combining the summaries of each key across currencies. If `conv` is nil,
+// intuit.NopCurrencyConverter is used.
+func ConvertSummaries(ctx context.Context, summaries []Summary, conv intuit.CurrencyConverter, to string) ([]Summary, error) {
+	byKey := map[string]*Summary{}
+	for _, summary := range summaries {
+		totals, err := summary.Convert(ctx, conv, to)
+		if err != nil {
+			return nil, err
+		}
+
+		combined, ok := byKey[summary.Key]
+		if !ok {
+			combined = &Summary{Key: summary.Key, Totals: Totals{Currency: to}}
+			byKey[summary.Key] = combined
+		}
+		combined.merge(totals)
+	}
+
+	converted := make([]Summary, 0, len(byKey))
+	for _, summary := range byKey {
+		converted = append(converted, *summary)
+	}
+
+	sortSummaries(converted)
+
+	return converted, nil
+}
+
+// ConvertCashflow converts the cashflow to the currency `to` with `conv`,
+// combining each month's cashflow across currencies. If `conv` is nil,
+// intuit.NopCurrencyConverter is used.
+func ConvertCashflow(ctx context.Context, flows []Cashflow, conv intuit.CurrencyConverter, to string) ([]Cashflow, error) {
+	type month struct {
+		year  int
+		month time.Month
+	}
+
+	byMonth := map[month]*Cashflow{}
+	for _, flow := range flows {
+		totals, err := flow.Convert(ctx, conv, to)
+		if err != nil {
+			return nil, err
+		}
+
+		key := month{flow.Year, flow.Month}
+
+		combined, ok := byMonth[key]
+		if !ok {
+			combined = &Cashflow{Year: flow.Year, Month: flow.Month, Totals: Totals{Currency: to}}
+			byMonth[key] = combined
+		}
+		combined.merge(totals)
+	}
+
+	converted := make([]Cashflow, 0, len(byMonth))
+	for _, flow := range byMonth {
+		converted = append(converted, *flow)
+	}
+
+	sortCashflow(converted)
+
+	return converted, nil
+}
@@ -0,0 +1,217 @@
+package analytics_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/analytics"
+	"github.com/bodetree/intuit-cad/intuittest"
+)
+
+const testCustomer = "customer-1"
+
+// fixtureTransactions returns the banking and credit card transactions of the
+// fixture accounts in April 2014, fetched from a fake CAD server. One of them
+// is pending.
+func fixtureTransactions(t *testing.T) []intuit.Transaction {
+	t.Helper()
+
+	srv := intuittest.NewServer()
+	t.Cleanup(srv.Close)
+
+	if err := srv.Seed(testCustomer); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := srv.NewClient(testCustomer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accounts, err := client.GetCustomerAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var txns []intuit.Transaction
+	for _, account := range accounts {
+		if !account.IsActive() {
+			continue
+		}
+
+		list, err := client.AccountTransactionsForDates(account.ID, intuit.Date{Year: 2014, Month: 4, Day: 1}, intuit.Date{Year: 2014, Month: 4, Day: 30})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		txns = append(txns, list["bankingTransactions"]...)
+		txns = append(txns, list["creditCardTransactions"]...)
+	}
+
+	if len(txns) != 5 {
+		t.Fatalf("fetched %d transactions, want 5", len(txns))
+	}
+
+	return txns
+}
+
+func money(f float64) intuit.Money {
+	return intuit.MoneyFromFloat(f)
+}
+
+// describe formats summaries for comparison
+func describe(summaries []analytics.Summary) string {
+	var s string
+	for _, summary := range summaries {
+		s += fmt.Sprintf("%s %s %d %s; ", summary.Key, summary.Currency, summary.Count, summary.Net)
+	}
+
+	return s
+}
+
+func TestSummarize(t *testing.T) {
+	txns := fixtureTransactions(t)
+
+	tests := []struct {
+		name      string
+		summarize func([]intuit.Transaction) []analytics.Summary
+		want      []analytics.Summary
+	}{
+		{
+			name:      "category",
+			summarize: analytics.SummarizeByCategory,
+			want: []analytics.Summary{
+				{Key: "Travel", Totals: analytics.Totals{Currency: "USD", Count: 1, Net: money(-412.80)}},
+				{Key: "Groceries", Totals: analytics.Totals{Currency: "USD", Count: 1, Net: money(-54.23)}},
+				{Key: "Credit Card Payment", Totals: analytics.Totals{Currency: "USD", Count: 1, Net: money(500)}},
+				{Key: "Paycheck", Totals: analytics.Totals{Currency: "USD", Count: 1, Net: money(2100)}},
+			},
+		},
+		{
+			name:      "payee",
+			summarize: analytics.SummarizeByPayee,
+			want: []analytics.Summary{
+				{Key: "Airline", Totals: analytics.Totals{Currency: "USD", Count: 1, Net: money(-412.80)}},
+				{Key: "Grocery Mart", Totals: analytics.Totals{Currency: "USD", Count: 1, Net: money(-54.23)}},
+				{Key: "Payment", Totals: analytics.Totals{Currency: "USD", Count: 1, Net: money(500)}},
+				{Key: "Acme Corp", Totals: analytics.Totals{Currency: "USD", Count: 1, Net: money(2100)}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, want := describe(test.summarize(txns)), describe(test.want); got != want {
+				t.Errorf("got %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+func TestMonthlyCashflow(t *testing.T) {
+	txns := fixtureTransactions(t)
+
+	// a transaction in another currency and month is kept apart
+	euro := txns[0]
+	euro.CurrencyType = "EUR"
+	euro.Amount = money(-100)
+	euro.Pending = false
+	euro.PostedDate = intuit.Timestamp(time.Date(2014, 5, 2, 0, 0, 0, 0, time.UTC))
+	txns = append(txns, euro)
+
+	flows := analytics.MonthlyCashflow(txns)
+
+	want := []analytics.Cashflow{
+		{Year: 2014, Month: time.April, Totals: analytics.Totals{Currency: "USD", Count: 4, Inflow: money(2600), Outflow: money(-467.03), Net: money(2132.97)}},
+		{Year: 2014, Month: time.May, Totals: analytics.Totals{Currency: "EUR", Count: 1, Outflow: money(-100), Net: money(-100)}},
+	}
+	if fmt.Sprint(flows) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", flows, want)
+	}
+
+	// euros are worth two dollars
+	conv := intuit.CurrencyConverterFunc(func(ctx context.Context, amount intuit.Money, from, to string) (intuit.Money, error) {
+		if from == "EUR" {
+			return amount * 2, nil
+		}
+		return amount, nil
+	})
+
+	converted, err := analytics.ConvertCashflow(context.Background(), flows, conv, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(converted) != 2 || converted[1].Currency != "USD" || converted[1].Net != money(-200) {
+		t.Errorf("converted %v, want May's cashflow in dollars", converted)
+	}
+
+	if _, err := analytics.ConvertCashflow(context.Background(), flows, nil, "USD"); err == nil {
+		t.Error("ConvertCashflow() without a converter converted euros")
+	}
+}
+
+func TestConvertSummaries(t *testing.T) {
+	txns := fixtureTransactions(t)
+
+	euro := txns[0]
+	euro.CurrencyType = "EUR"
+	euro.Amount = money(-10)
+	euro.Pending = false
+	txns = append(txns, euro)
+
+	conv := intuit.CurrencyConverterFunc(func(ctx context.Context, amount intuit.Money, from, to string) (intuit.Money, error) {
+		if from == "EUR" {
+			return amount * 2, nil
+		}
+		return amount, nil
+	})
+
+	converted, err := analytics.ConvertSummaries(context.Background(), analytics.SummarizeByCategory(txns), conv, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the euro transaction joins its category's summary
+	key := analytics.SummarizeByCategory([]intuit.Transaction{euro})[0].Key
+	for _, summary := range converted {
+		if summary.Currency != "USD" {
+			t.Errorf("summary %+v is not in dollars", summary)
+		}
+		if summary.Key == key && summary.Count != 2 {
+			t.Errorf("summary %+v, want both transactions of %s", summary, key)
+		}
+	}
+}
+
+func TestSummarizeScheduleC(t *testing.T) {
+	summaries := analytics.SummarizeScheduleC(fixtureTransactions(t))
+
+	order := map[intuit.TaxCategory]int{}
+	for i, category := range intuit.TaxCategories {
+		order[category] = i
+	}
+
+	// the fixtures' payment has no Schedule C line, and the pending
+	// transaction none at all
+	if len(summaries) != 3 {
+		t.Fatalf("got %d summaries, want 3: %+v", len(summaries), summaries)
+	}
+
+	var net intuit.Money
+	for i, summary := range summaries {
+		if summary.Line == "" || summary.Count != 1 {
+			t.Errorf("summary %+v, want one transaction on a line", summary)
+		}
+		if i > 0 && order[summary.Category] < order[summaries[i-1].Category] {
+			t.Errorf("summary %s comes after %s, want form order", summary.Category, summaries[i-1].Category)
+		}
+		net += summary.Net
+	}
+
+	if want := money(2100 - 54.23 - 412.80); net != want {
+		t.Errorf("net %s, want %s", net, want)
+	}
+}
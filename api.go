@@ -0,0 +1,66 @@
+package intuit
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// AccountsGetter is the part of the API that lists accounts
+type AccountsGetter interface {
+	GetCustomerAccounts(opts ...RequestOption) ([]Account, error)
+	GetCustomerAccountsContext(ctx context.Context, opts ...RequestOption) ([]Account, error)
+	GetLoginAccounts(loginID int64, opts ...RequestOption) ([]Account, error)
+	GetLoginAccountsContext(ctx context.Context, loginID int64, opts ...RequestOption) ([]Account, error)
+	GetCustomerBalances(opts ...RequestOption) ([]AccountBalance, error)
+	GetCustomerBalancesContext(ctx context.Context, opts ...RequestOption) ([]AccountBalance, error)
+}
+
+// TransactionsGetter is the part of the API that fetches transactions
+type TransactionsGetter interface {
+	AccountTransactions(accountID int64, startDate time.Time, endDate *time.Time) (TransactionList, error)
+	AccountTransactionsForDates(accountID int64, start, end Date, opts ...RequestOption) (TransactionList, error)
+	AccountTransactionsForDatesContext(ctx context.Context, accountID int64, start, end Date, opts ...RequestOption) (TransactionList, error)
+	AccountTransactionsProjected(accountID int64, start, end Date, projection Projection, opts ...RequestOption) (TransactionList, error)
+	AccountTransactionsProjectedContext(ctx context.Context, accountID int64, start, end Date, projection Projection, opts ...RequestOption) (TransactionList, error)
+	AccountTransactionsRange(accountID int64, start, end Date, chunk time.Duration) (TransactionList, error)
+	AccountTransactionsRangeContext(ctx context.Context, accountID int64, start, end Date, chunk time.Duration) (TransactionList, error)
+	FetchAccountTransactions(accountID int64, start, end Date, opts ...RequestOption) (*TransactionsResult, error)
+	FetchAccountTransactionsContext(ctx context.Context, accountID int64, start, end Date, opts ...RequestOption) (*TransactionsResult, error)
+	StreamAccountTransactions(ctx context.Context, accountID int64, start, end Date, opts ...RequestOption) (TransactionIterator, error)
+	AllTransactions(ctx context.Context, accountIDs []int64, start, end Date, concurrency int) (BulkTransactions, error)
+	AllTransactionsProjected(ctx context.Context, accountIDs []int64, start, end Date, concurrency int, projection Projection) (BulkTransactions, error)
+	WaitForNewTransactions(ctx context.Context, accountID int64, since TransactionCursor, pollInterval time.Duration) (Transactions, TransactionCursor, error)
+}
+
+// InstitutionsGetter is the part of the API that describes institutions
+type InstitutionsGetter interface {
+	InstitutionDetails(institutionID int64, opts ...RequestOption) (*InstitutionDetails, error)
+	InstitutionDetailsContext(ctx context.Context, institutionID int64, opts ...RequestOption) (*InstitutionDetails, error)
+	Institutions(opts ...RequestOption) ([]InstitutionDetails, error)
+	InstitutionsContext(ctx context.Context, opts ...RequestOption) ([]InstitutionDetails, error)
+}
+
+// API is implemented by *Client. Accept it, or one of the narrower interfaces
+// it embeds, in code that should be testable against a mock client.
+type API interface {
+	AccountsGetter
+	TransactionsGetter
+	InstitutionsGetter
+
+	Init() error
+	InitContext(ctx context.Context) error
+	Reauthenticate() error
+	ReauthenticateContext(ctx context.Context) error
+	InvalidateToken()
+	RefreshLogin(loginID int64, opts ...RequestOption) ([]Account, error)
+	RefreshLoginContext(ctx context.Context, loginID int64, opts ...RequestOption) ([]Account, error)
+	DiscoverAndAddAccounts(institutionID int64, credentials []Credential, opts ...RequestOption) ([]Account, *Challenge, error)
+	DiscoverAndAddAccountsContext(ctx context.Context, institutionID int64, credentials []Credential, opts ...RequestOption) ([]Account, *Challenge, error)
+	AnswerChallenge(challenge *Challenge, answers []string, opts ...RequestOption) ([]Account, *Challenge, error)
+	AnswerChallengeContext(ctx context.Context, challenge *Challenge, answers []string, opts ...RequestOption) ([]Account, *Challenge, error)
+	Do(ctx context.Context, method, path string, body, out interface{}, opts ...RequestOption) (*http.Response, error)
+	CustomerLabel() string
+}
+
+var _ API = (*Client)(nil)
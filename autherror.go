@@ -0,0 +1,189 @@
+package intuit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SAMLAuthFailure classifies why the token endpoint rejected a SAML assertion
+type SAMLAuthFailure string
+
+// Constants representing SAML authentication failures
+const (
+	SAMLAuthInvalidSignature SAMLAuthFailure = "invalid-signature"
+	SAMLAuthUnknownProvider  SAMLAuthFailure = "unknown-provider"
+	SAMLAuthClockSkew        SAMLAuthFailure = "clock-skew"
+	SAMLAuthInvalidConsumer  SAMLAuthFailure = "invalid-consumer"
+	SAMLAuthOther            SAMLAuthFailure = "other"
+)
+
+// samlAuthFailureHints say which setting to check for each failure
+var samlAuthFailureHints = map[SAMLAuthFailure]string{
+	SAMLAuthInvalidSignature: "check that the private key or signer matches the certificate registered with Intuit, and the SigningHash",
+	SAMLAuthUnknownProvider:  "check SAMLProviderID against the SAML provider registered with Intuit",
+	SAMLAuthClockSkew:        "check the system clock, and AssertionOptions.ClockSkew and Lifetime",
+	SAMLAuthInvalidConsumer:  "check ConsumerKey and ConsumerSecret",
+}
+
+// Hint returns which setting to check for the failure, or "" if there is no
+// specific advice
+func (f SAMLAuthFailure) Hint() string {
+	return samlAuthFailureHints[f]
+}
+
+// SAMLAuthError is returned when the token endpoint rejects an assertion.
+// Problem and Reason are the problem and reason parameters of the response's
+// Www-Authenticate header; Kind classifies them. It wraps ErrUnauthorized for
+// responses with status 401 or 403.
+type SAMLAuthError struct {
+	// Customer is the customer's label; see Client.CustomerLabel
+	Customer string
+
+	StatusCode int
+	Status     string
+
+	Problem string
+	Reason  string
+	Kind    SAMLAuthFailure
+
+	// Header is the decoded Www-Authenticate header
+	Header string
+}
+
+func (e *SAMLAuthError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "authentication error for customer %s: %s", e.Customer, e.Status)
+
+	switch {
+	case e.Problem != "" && e.Reason != "":
+		fmt.Fprintf(&b, ": %s (%s)", e.Problem, e.Reason)
+	case e.Problem != "":
+		fmt.Fprintf(&b, ": %s", e.Problem)
+	case e.Reason != "":
+		fmt.Fprintf(&b, ": %s", e.Reason)
+	}
+
+	return b.String()
+}
+
+// Unwrap returns ErrUnauthorized if the token endpoint responded with status
+// 401 or 403
+func (e *SAMLAuthError) Unwrap() error {
+	if e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden {
+		return ErrUnauthorized
+	}
+
+	return nil
+}
+
+// newSAMLAuthError builds a SAMLAuthError from a token endpoint error response
+func newSAMLAuthError(customer string, resp *http.Response) *SAMLAuthError {
+	header := resp.Header.Get("Www-Authenticate")
+	if unescaped, err := url.QueryUnescape(header); err == nil {
+		header = unescaped
+	}
+
+	params := parseAuthenticateParams(header)
+
+	problem := firstParam(params, "oauth_problem", "problem")
+	reason := firstParam(params, "oauth_problem_advice", "reason", "error_description")
+	if len(params) == 0 {
+		reason = strings.TrimSpace(header)
+	}
+
+	return &SAMLAuthError{
+		Customer:   customer,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Problem:    problem,
+		Reason:     reason,
+		Kind:       classifySAMLAuthFailure(problem, reason),
+		Header:     header,
+	}
+}
+
+// classifySAMLAuthFailure classifies a failure by the wording of its problem
+// and reason
+func classifySAMLAuthFailure(problem, reason string) SAMLAuthFailure {
+	text := strings.ToLower(problem + " " + reason)
+
+	switch {
+	case strings.Contains(text, "signature"):
+		return SAMLAuthInvalidSignature
+	case strings.Contains(text, "provider") || strings.Contains(text, "issuer"):
+		return SAMLAuthUnknownProvider
+	case strings.Contains(text, "skew") || strings.Contains(text, "expired") || strings.Contains(text, "timestamp") ||
+		strings.Contains(text, "not yet valid") || strings.Contains(text, "notbefore") || strings.Contains(text, "notonorafter"):
+		return SAMLAuthClockSkew
+	case strings.Contains(text, "consumer"):
+		return SAMLAuthInvalidConsumer
+	}
+
+	return SAMLAuthOther
+}
+
+// parseAuthenticateParams parses the auth-params of a Www-Authenticate header
+// such as `OAuth oauth_problem="signature_invalid", reason="..."`, with
+// parameter names lower-cased. It returns nil if the header has none.
+func parseAuthenticateParams(header string) map[string]string {
+	header = strings.TrimSpace(header)
+
+	// skip the auth scheme, if any
+	if i := strings.IndexAny(header, " \t"); i > 0 && !strings.Contains(header[:i], "=") {
+		header = header[i+1:]
+	}
+
+	var params map[string]string
+	for header != "" {
+		header = strings.TrimLeft(header, " \t,")
+
+		eq := strings.Index(header, "=")
+		if eq <= 0 {
+			break
+		}
+		name := strings.ToLower(strings.TrimSpace(header[:eq]))
+		header = strings.TrimLeft(header[eq+1:], " \t")
+
+		var value string
+		if strings.HasPrefix(header, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(header) && header[i] != '"'; i++ {
+				if header[i] == '\\' && i+1 < len(header) {
+					i++
+				}
+				b.WriteByte(header[i])
+			}
+			if i < len(header) {
+				i++ // the closing quote
+			}
+			value, header = b.String(), header[i:]
+		} else {
+			end := strings.Index(header, ",")
+			if end < 0 {
+				end = len(header)
+			}
+			value, header = strings.TrimSpace(header[:end]), header[end:]
+		}
+
+		if params == nil {
+			params = map[string]string{}
+		}
+		params[name] = value
+	}
+
+	return params
+}
+
+// firstParam returns the value of the first of `names` present in params
+func firstParam(params map[string]string, names ...string) string {
+	for _, name := range names {
+		if value, ok := params[name]; ok {
+			return value
+		}
+	}
+
+	return ""
+}
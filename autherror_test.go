@@ -0,0 +1,114 @@
+package intuit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseAuthenticateParams(t *testing.T) {
+	tests := []struct {
+		header string
+		want   map[string]string
+	}{
+		{``, nil},
+		{`OAuth`, nil},
+		{`signature invalid`, nil},
+		{
+			`OAuth oauth_problem="signature_invalid", Reason="bad \"sig\""`,
+			map[string]string{"oauth_problem": "signature_invalid", "reason": `bad "sig"`},
+		},
+		{
+			`oauth_problem=timestamp_refused,oauth_problem_advice=clock skew`,
+			map[string]string{"oauth_problem": "timestamp_refused", "oauth_problem_advice": "clock skew"},
+		},
+		{
+			`Bearer error_description="unterminated`,
+			map[string]string{"error_description": "unterminated"},
+		},
+	}
+
+	for _, test := range tests {
+		if got := parseAuthenticateParams(test.header); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseAuthenticateParams(%q) = %q, want %q", test.header, got, test.want)
+		}
+	}
+}
+
+func TestNewSAMLAuthError(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		header      string
+		wantProblem string
+		wantReason  string
+		wantKind    SAMLAuthFailure
+		wantString  string
+	}{
+		{
+			name:        "signature",
+			status:      401,
+			header:      `OAuth oauth_problem="signature_invalid"`,
+			wantProblem: "signature_invalid",
+			wantKind:    SAMLAuthInvalidSignature,
+			wantString:  "authentication error for customer c: 401 Unauthorized: signature_invalid",
+		},
+		{
+			name:        "URL-encoded",
+			status:      401,
+			header:      `oauth_problem%3D%22parameter_rejected%22%2C%20reason%3D%22unknown%20SAML%20provider%22`,
+			wantProblem: "parameter_rejected",
+			wantReason:  "unknown SAML provider",
+			wantKind:    SAMLAuthUnknownProvider,
+			wantString:  "authentication error for customer c: 401 Unauthorized: parameter_rejected (unknown SAML provider)",
+		},
+		{
+			name:       "clock skew",
+			status:     403,
+			header:     `OAuth error_description="Assertion expired"`,
+			wantReason: "Assertion expired",
+			wantKind:   SAMLAuthClockSkew,
+			wantString: "authentication error for customer c: 403 Forbidden: Assertion expired",
+		},
+		{
+			name:        "consumer",
+			status:      401,
+			header:      `OAuth problem="consumer_key_unknown"`,
+			wantProblem: "consumer_key_unknown",
+			wantKind:    SAMLAuthInvalidConsumer,
+			wantString:  "authentication error for customer c: 401 Unauthorized: consumer_key_unknown",
+		},
+		{
+			name:       "free text",
+			status:     500,
+			header:     `  something broke  `,
+			wantReason: "something broke",
+			wantKind:   SAMLAuthOther,
+			wantString: "authentication error for customer c: 500 Internal Server Error: something broke",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := newSAMLAuthError("c", &http.Response{
+				StatusCode: test.status,
+				Status:     fmt.Sprintf("%d %s", test.status, http.StatusText(test.status)),
+				Header:     http.Header{"Www-Authenticate": {test.header}},
+			})
+
+			if got.Problem != test.wantProblem || got.Reason != test.wantReason || got.Kind != test.wantKind {
+				t.Errorf("newSAMLAuthError() = %+v, want problem %q, reason %q, kind %s", got, test.wantProblem, test.wantReason, test.wantKind)
+			}
+			if got.Error() != test.wantString {
+				t.Errorf("Error() = %q, want %q", got.Error(), test.wantString)
+			}
+
+			unauthorized := test.status == 401 || test.status == 403
+			if errors.Is(got, ErrUnauthorized) != unauthorized {
+				t.Errorf("errors.Is(ErrUnauthorized) = %v, want %v", !unauthorized, unauthorized)
+			}
+		})
+	}
+}
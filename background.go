@@ -0,0 +1,63 @@
+package intuit
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+)
+
+// OnBackgroundError is called with errors from background goroutines that have
+// no more specific handler, such as a Manager's OnBackgroundError. Panics in
+// background goroutines are always recovered; if no handler is set, they are
+// logged with their stack by the standard logger, and other errors are
+// dropped.
+var OnBackgroundError func(error)
+
+// BackgroundPanicError is reported when a background goroutine panics
+type BackgroundPanicError struct {
+	Task  string
+	Value interface{}
+	Stack []byte
+}
+
+func (e *BackgroundPanicError) Error() string {
+	return fmt.Sprintf("panic in background task %s: %v", e.Task, e.Value)
+}
+
+// runBackground runs fn, recovering any panic and reporting it to `handler`,
+// or to OnBackgroundError if handler is nil
+func runBackground(task string, handler func(error), fn func()) {
+	defer func() {
+		if value := recover(); value != nil {
+			reportBackgroundError(handler, &BackgroundPanicError{
+				Task:  task,
+				Value: value,
+				Stack: debug.Stack(),
+			})
+		}
+	}()
+
+	fn()
+}
+
+// goBackground runs fn in a new goroutine as with runBackground
+func goBackground(task string, handler func(error), fn func()) {
+	go runBackground(task, handler, fn)
+}
+
+func reportBackgroundError(handler func(error), err error) {
+	if handler == nil {
+		handler = OnBackgroundError
+	}
+
+	if handler != nil {
+		handler(err)
+		return
+	}
+
+	var panicErr *BackgroundPanicError
+	if errors.As(err, &panicErr) {
+		log.Printf("intuit: %v\n%s", panicErr, panicErr.Stack)
+	}
+}
@@ -0,0 +1,38 @@
+package intuit
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestRunBackgroundLogsUnhandledPanics(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	runBackground("test task", nil, func() { panic("boom") })
+
+	out := buf.String()
+	if !strings.Contains(out, "intuit: panic in background task test task: boom") {
+		t.Errorf("log output %q does not report the panic", out)
+	}
+	if !strings.Contains(out, "TestRunBackgroundLogsUnhandledPanics") {
+		t.Errorf("log output %q has no stack", out)
+	}
+
+	buf.Reset()
+	reportBackgroundError(nil, errors.New("not a panic"))
+	if buf.Len() != 0 {
+		t.Errorf("logged %q for an error that is not a panic", buf.String())
+	}
+
+	var handled error
+	runBackground("test task", func(err error) { handled = err }, func() { panic("boom") })
+	if handled == nil || buf.Len() != 0 {
+		t.Errorf("handler got %v and log %q, want only the handler called", handled, buf.String())
+	}
+}
@@ -0,0 +1,277 @@
+// Package boltstore implements intuit.Store on a bbolt database file.
+//
+//	store, err := boltstore.Open("sync.db")
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//
+//	result, err := intuit.NewSyncer(client, store).Sync(ctx)
+//
+// Each customer has a bucket holding an "accounts" bucket keyed by account ID,
+// a "transactions" bucket with a bucket per account keyed by transaction Key,
+// and a "synced" bucket of last sync times keyed by account ID. Values are
+// JSON. Paused customers have a "paused" key in their bucket, so Store is also
+// an intuit.PauseStore and syncs of them fail with intuit.ErrCustomerPaused.
+package boltstore
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	accountsBucket     = []byte("accounts")
+	transactionsBucket = []byte("transactions")
+	syncedBucket       = []byte("synced")
+	pausedKey          = []byte("paused")
+)
+
+// Store is an intuit.Store backed by a bbolt database
+type Store struct {
+	db *bolt.DB
+}
+
+var (
+	_ intuit.Store      = (*Store)(nil)
+	_ intuit.PauseStore = (*Store)(nil)
+)
+
+// Open opens or creates the database file at `path`
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second * 5})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// UpsertAccounts implements intuit.Store
+func (s *Store) UpsertAccounts(customerID string, accounts []intuit.Account) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := customerBucket(tx, customerID, accountsBucket)
+		if err != nil {
+			return err
+		}
+
+		for _, account := range accounts {
+			if err := putJSON(bucket, idKey(account.ID), account); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// UpsertTransactions implements intuit.Store
+func (s *Store) UpsertTransactions(customerID string, accountID int64, txns []intuit.Transaction, syncedAt time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		parent, err := customerBucket(tx, customerID, transactionsBucket)
+		if err != nil {
+			return err
+		}
+
+		bucket, err := parent.CreateBucketIfNotExists(idKey(accountID))
+		if err != nil {
+			return err
+		}
+
+		// collect first; deleting while iterating with ForEach is not allowed
+		var pending [][]byte
+		err = bucket.ForEach(func(k, v []byte) error {
+			var t intuit.Transaction
+			if err := json.Unmarshal(v, &t); err != nil {
+				return err
+			}
+
+			if t.Pending {
+				pending = append(pending, append([]byte(nil), k...))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range pending {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		for _, t := range txns {
+			if err := putJSON(bucket, []byte(t.Key()), t); err != nil {
+				return err
+			}
+		}
+
+		synced, err := customerBucket(tx, customerID, syncedBucket)
+		if err != nil {
+			return err
+		}
+
+		return synced.Put(idKey(accountID), []byte(syncedAt.UTC().Format(time.RFC3339Nano)))
+	})
+}
+
+// LastSyncTime implements intuit.Store
+func (s *Store) LastSyncTime(customerID string, accountID int64) (time.Time, error) {
+	var last time.Time
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := readBucket(tx, customerID, syncedBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		value := bucket.Get(idKey(accountID))
+		if value == nil {
+			return nil
+		}
+
+		var err error
+		last, err = time.Parse(time.RFC3339Nano, string(value))
+
+		return err
+	})
+
+	return last, err
+}
+
+// Paused implements intuit.PauseStore
+func (s *Store) Paused(customerID string) (bool, error) {
+	var paused bool
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		customer := tx.Bucket([]byte(customerID))
+		paused = customer != nil && customer.Get(pausedKey) != nil
+
+		return nil
+	})
+
+	return paused, err
+}
+
+// SetPaused implements intuit.PauseStore
+func (s *Store) SetPaused(customerID string, paused bool) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		customer, err := tx.CreateBucketIfNotExists([]byte(customerID))
+		if err != nil {
+			return err
+		}
+
+		if !paused {
+			return customer.Delete(pausedKey)
+		}
+
+		return customer.Put(pausedKey, []byte(time.Now().UTC().Format(time.RFC3339Nano)))
+	})
+}
+
+// Accounts returns the customer's stored accounts ordered by ID
+func (s *Store) Accounts(customerID string) ([]intuit.Account, error) {
+	var accounts []intuit.Account
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := readBucket(tx, customerID, accountsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var account intuit.Account
+			if err := json.Unmarshal(v, &account); err != nil {
+				return err
+			}
+
+			accounts = append(accounts, account)
+
+			return nil
+		})
+	})
+
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+
+	return accounts, err
+}
+
+// Transactions returns the account's stored transactions ordered by posted
+// date
+func (s *Store) Transactions(customerID string, accountID int64) (intuit.Transactions, error) {
+	var txns intuit.Transactions
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		parent := readBucket(tx, customerID, transactionsBucket)
+		if parent == nil {
+			return nil
+		}
+
+		bucket := parent.Bucket(idKey(accountID))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var t intuit.Transaction
+			if err := json.Unmarshal(v, &t); err != nil {
+				return err
+			}
+
+			txns = append(txns, t)
+
+			return nil
+		})
+	})
+
+	sort.SliceStable(txns, func(i, j int) bool { return txns[i].PostedDate.Before(txns[j].PostedDate) })
+
+	return txns, err
+}
+
+// customerBucket returns the named bucket of the customer, creating it if
+// necessary
+func customerBucket(tx *bolt.Tx, customerID string, name []byte) (*bolt.Bucket, error) {
+	customer, err := tx.CreateBucketIfNotExists([]byte(customerID))
+	if err != nil {
+		return nil, err
+	}
+
+	return customer.CreateBucketIfNotExists(name)
+}
+
+// readBucket returns the named bucket of the customer, or nil if it doesn't
+// exist
+func readBucket(tx *bolt.Tx, customerID string, name []byte) *bolt.Bucket {
+	customer := tx.Bucket([]byte(customerID))
+	if customer == nil {
+		return nil
+	}
+
+	return customer.Bucket(name)
+}
+
+func idKey(id int64) []byte {
+	return []byte(strconv.FormatInt(id, 10))
+}
+
+func putJSON(bucket *bolt.Bucket, key []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put(key, data)
+}
@@ -0,0 +1,186 @@
+package boltstore_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/boltstore"
+	"github.com/bodetree/intuit-cad/intuittest"
+)
+
+const (
+	testCustomer = "customer-1"
+
+	// testBankingAccount is the fixture checking account, whose transactions
+	// were posted on 2014-04-23 and 2014-04-24
+	testBankingAccount int64 = 400107846787
+)
+
+// newSyncer starts a fake server seeded with the fixtures for testCustomer and
+// returns a syncer for the customer into a store opened at `path`
+func newSyncer(t *testing.T, path string) (*intuit.Syncer, *boltstore.Store) {
+	t.Helper()
+
+	srv := intuittest.NewServer()
+	t.Cleanup(srv.Close)
+
+	if err := srv.Seed(testCustomer); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := intuit.NewManualClock(time.Date(2014, 4, 25, 0, 0, 0, 0, time.UTC))
+	client, err := srv.NewClient(testCustomer, intuit.WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := open(t, path)
+
+	return intuit.NewSyncer(client, store), store
+}
+
+func open(t *testing.T, path string) *boltstore.Store {
+	t.Helper()
+
+	store, err := boltstore.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+// transactionKeys returns the keys of the account's stored transactions in
+// order, since those posted on the same day are stored in no set order
+func transactionKeys(t *testing.T, store *boltstore.Store) []string {
+	t.Helper()
+
+	txns, err := store.Transactions(testCustomer, testBankingAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make([]string, len(txns))
+	for i, txn := range txns {
+		keys[i] = txn.Key()
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func equal(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestSync(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intuit.db")
+	syncer, store := newSyncer(t, path)
+
+	result, err := syncer.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Accounts != 7 || len(result.Failed) != 0 {
+		t.Fatalf("result %+v, want 7 accounts and no failures", result)
+	}
+
+	// everything survives reopening the file
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store = open(t, path)
+
+	accounts, err := store.Accounts(testCustomer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 7 || accounts[0].ID != testBankingAccount {
+		t.Errorf("stored %d accounts starting with %d, want 7 starting with %d", len(accounts), accounts[0].ID, testBankingAccount)
+	}
+
+	if got, want := transactionKeys(t, store), []string{"INTUIT-BANK-0001", "INTUIT-BANK-0002", "INTUIT-BANK-0003"}; !equal(got, want) {
+		t.Errorf("stored transactions %v, want %v", got, want)
+	}
+
+	last, err := store.LastSyncTime(testCustomer, testBankingAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2014, 4, 25, 0, 0, 0, 0, time.UTC); !last.Equal(want) {
+		t.Errorf("last sync time %v, want %v", last, want)
+	}
+
+	if last, err := store.LastSyncTime("customer-2", testBankingAccount); err != nil || !last.IsZero() {
+		t.Errorf("last sync time of an unknown customer = %v, %v, want the zero time", last, err)
+	}
+}
+
+func TestPendingReplaced(t *testing.T) {
+	syncer, store := newSyncer(t, filepath.Join(t.TempDir(), "intuit.db"))
+
+	if _, err := syncer.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the pending fixture transaction posts under a new institution ID
+	posted := intuit.Transaction{
+		ID:                       900013,
+		InstitutionTransactionID: "INTUIT-BANK-0013",
+		PostedDate:               intuit.Timestamp(time.Date(2014, 4, 25, 9, 0, 0, 0, time.UTC)),
+	}
+	if err := store.UpsertTransactions(testCustomer, testBankingAccount, []intuit.Transaction{posted}, time.Date(2014, 4, 26, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := transactionKeys(t, store), []string{"INTUIT-BANK-0001", "INTUIT-BANK-0002", "INTUIT-BANK-0013"}; !equal(got, want) {
+		t.Errorf("stored transactions %v, want %v", got, want)
+	}
+}
+
+func TestPaused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intuit.db")
+	syncer, store := newSyncer(t, path)
+
+	if err := store.SetPaused(testCustomer, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := syncer.Sync(context.Background()); !errors.Is(err, intuit.ErrCustomerPaused) {
+		t.Fatalf("Sync() error = %v, want ErrCustomerPaused", err)
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store = open(t, path)
+	syncer.Store, syncer.Pauses = store, store
+
+	if paused, err := store.Paused(testCustomer); err != nil || !paused {
+		t.Fatalf("Paused() after reopening = %v, %v, want true", paused, err)
+	}
+	if paused, err := store.Paused("customer-2"); err != nil || paused {
+		t.Errorf("Paused() of an unknown customer = %v, %v, want false", paused, err)
+	}
+
+	if err := store.SetPaused(testCustomer, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := syncer.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() after resuming: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package intuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultMinStepBudget is the minimum step budget of a client without one; see
+// Client.MinStepBudget
+const DefaultMinStepBudget = time.Second
+
+// DeadlineBudgetError is returned by helpers that issue several sequential calls
+// when the context deadline leaves a step too little time. Step identifies the
+// step that was starved.
+type DeadlineBudgetError struct {
+	Step      string
+	Share     time.Duration
+	Remaining time.Duration
+	Err       error
+}
+
+func (e *DeadlineBudgetError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("deadline budget exhausted at step %s (share %s of %s remaining): %v", e.Step, e.Share, e.Remaining, e.Err)
+	}
+
+	return fmt.Sprintf("deadline budget exhausted at step %s (share %s of %s remaining)", e.Step, e.Share, e.Remaining)
+}
+
+// Unwrap returns the underlying context error, if any
+func (e *DeadlineBudgetError) Unwrap() error {
+	return e.Err
+}
+
+// WithMinStepBudget sets the shortest time a step of a multi-call helper will
+// be started with
+func WithMinStepBudget(d time.Duration) Option {
+	return func(c *Client) {
+		c.MinStepBudget = d
+	}
+}
+
+// minStepBudget returns the client's minimum step budget
+func (c *Client) minStepBudget() time.Duration {
+	if c.MinStepBudget <= 0 {
+		return DefaultMinStepBudget
+	}
+
+	return c.MinStepBudget
+}
+
+// deadlineBudget divides the time remaining before a context's deadline evenly
+// across a known number of steps. It is not safe for concurrent use.
+type deadlineBudget struct {
+	ctx   context.Context
+	steps int
+	min   time.Duration
+}
+
+func newDeadlineBudget(ctx context.Context, steps int, min time.Duration) *deadlineBudget {
+	return &deadlineBudget{ctx: ctx, steps: steps, min: min}
+}
+
+// step returns a context for the next step, limited to an even share of the
+// time remaining. If the parent context has no deadline, it is returned as is.
+func (b *deadlineBudget) step(name string) (context.Context, context.CancelFunc, *DeadlineBudgetError) {
+	deadline, ok := b.ctx.Deadline()
+	if !ok {
+		return b.ctx, func() {}, nil
+	}
+
+	steps := b.steps
+	if steps < 1 {
+		steps = 1
+	}
+	b.steps = steps - 1
+
+	remaining := time.Until(deadline)
+	share := remaining / time.Duration(steps)
+	if share < b.min {
+		return nil, nil, &DeadlineBudgetError{Step: name, Share: share, Remaining: remaining, Err: b.ctx.Err()}
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, share)
+
+	return ctx, cancel, nil
+}
+
+// wrap converts a deadline error from a step's context into a
+// DeadlineBudgetError identifying the step. Other errors are returned as is.
+func (b *deadlineBudget) wrap(name string, err error) error {
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var remaining time.Duration
+	if deadline, ok := b.ctx.Deadline(); ok {
+		remaining = time.Until(deadline)
+	}
+
+	return &DeadlineBudgetError{Step: name, Remaining: remaining, Err: err}
+}
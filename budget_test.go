@@ -0,0 +1,77 @@
+package intuit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+func TestAllTransactionsBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		minStep    time.Duration
+		wantBudget bool
+	}{
+		{"enough time", time.Millisecond, false},
+		{"starved", time.Hour, true},
+	}
+
+	accountIDs := []int64{testBankingAccount, 400107846788, 400107846789}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, client := newTestServer(t, intuit.WithMinStepBudget(test.minStep))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			results, err := client.AllTransactions(ctx, accountIDs, testStart, testEnd, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(results) != len(accountIDs) {
+				t.Fatalf("got %d results, want %d", len(results), len(accountIDs))
+			}
+			for accountID, result := range results {
+				var budgetErr *intuit.DeadlineBudgetError
+				if got := errors.As(result.Err, &budgetErr); got != test.wantBudget {
+					t.Errorf("account %d: error %v, want a DeadlineBudgetError: %v", accountID, result.Err, test.wantBudget)
+				}
+			}
+
+			if got := len(results[testBankingAccount].Transactions.All()); !test.wantBudget && got != 3 {
+				t.Errorf("got %d transactions, want 3", got)
+			}
+		})
+	}
+}
+
+func TestPipelineEnrichBudget(t *testing.T) {
+	_, client := newTestServer(t, intuit.WithMinStepBudget(time.Second))
+
+	var calls int
+	p := intuit.NewPipeline().
+		FetchAccounts().
+		Enrich(func(ctx context.Context, run *intuit.PipelineRun, account *intuit.Account) error {
+			calls++
+			return nil
+		})
+
+	// seven accounts cannot have a second each
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := p.Run(ctx, client)
+
+	var budgetErr *intuit.DeadlineBudgetError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("Run() error = %v, want a DeadlineBudgetError", err)
+	}
+	if calls != 0 {
+		t.Errorf("enriched %d accounts, want none", calls)
+	}
+}
@@ -0,0 +1,132 @@
+package intuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// AccountTransactionsResult is the outcome of fetching one account's
+// transactions in a bulk call
+type AccountTransactionsResult struct {
+	Transactions TransactionList
+	Err          error
+}
+
+// BulkTransactions maps account IDs to the outcome of fetching their
+// transactions
+type BulkTransactions map[int64]AccountTransactionsResult
+
+// Failed returns the IDs of the accounts whose transactions couldn't be
+// fetched
+func (b BulkTransactions) Failed() []int64 {
+	var failed []int64
+	for accountID, result := range b {
+		if result.Err != nil {
+			failed = append(failed, accountID)
+		}
+	}
+
+	return failed
+}
+
+// AllTransactions fetches the transactions of several accounts between start
+// and end, inclusive, with up to `concurrency` requests in flight. A failure
+// for one account doesn't stop the others; it is reported in that account's
+// result.
+//
+// If concurrency <= 0 and the client uses an AdaptiveConcurrency, the number
+// of requests in flight follows its limit; otherwise accounts are fetched one
+// at a time.
+//
+// The time remaining before ctx's deadline is divided evenly between the
+// accounts each worker fetches; an account starved of time fails with a
+// DeadlineBudgetError. If ctx is cancelled, no further accounts are started and
+// the results so far are returned with a *CancelledError.
+func (c *Client) AllTransactions(ctx context.Context, accountIDs []int64, start, end Date, concurrency int) (BulkTransactions, error) {
+	return c.AllTransactionsProjected(ctx, accountIDs, start, end, concurrency, ProjectFull)
+}
+
+// AllTransactionsProjected is like AllTransactions, but decodes only the parts
+// of each transaction selected by `projection`
+func (c *Client) AllTransactionsProjected(ctx context.Context, accountIDs []int64, start, end Date, concurrency int, projection Projection) (_ BulkTransactions, err error) {
+	ctx, span := c.startSpan(ctx, "AllTransactions", SpanKindInternal,
+		SpanAttribute{"intuit.accounts", len(accountIDs)},
+		SpanAttribute{"intuit.start_date", start.String()},
+		SpanAttribute{"intuit.end_date", end.String()},
+	)
+	defer func() { endSpan(span, err) }()
+
+	workers := concurrency
+	if workers <= 0 {
+		workers = 1
+		if a, ok := c.concurrency.(*AdaptiveConcurrency); ok {
+			workers = a.max()
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(BulkTransactions, len(accountIDs))
+	)
+
+	// each worker divides the time before ctx's deadline between the
+	// accounts it is expected to fetch
+	rounds := (len(accountIDs) + workers - 1) / workers
+
+	work := make(chan int64)
+	for n := 0; n < workers && n < len(accountIDs); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			budget := newDeadlineBudget(ctx, rounds, c.minStepBudget())
+			for accountID := range work {
+				name := fmt.Sprintf("transactions of account %d", accountID)
+
+				stepCtx, cancel, budgetErr := budget.step(name)
+				if budgetErr != nil {
+					mu.Lock()
+					results[accountID] = AccountTransactionsResult{Err: budgetErr}
+					mu.Unlock()
+					continue
+				}
+
+				list, err := c.AccountTransactionsProjectedContext(stepCtx, accountID, start, end, projection)
+				cancel()
+				if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+					// cut short by the cancellation; counted as remaining
+					continue
+				}
+				err = budget.wrap(name, err)
+
+				mu.Lock()
+				results[accountID] = AccountTransactionsResult{Transactions: list, Err: err}
+				mu.Unlock()
+			}
+		}()
+	}
+
+schedule:
+	for _, accountID := range accountIDs {
+		select {
+		case work <- accountID:
+		case <-ctx.Done():
+			break schedule
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil && len(results) < len(accountIDs) {
+		return results, &CancelledError{
+			Completed: len(results),
+			Remaining: len(accountIDs) - len(results),
+			Err:       err,
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,272 @@
+package intuit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// xmlElement is a minimal DOM element used for canonicalization. Names keep the
+// prefix from the source document in Space, rather than the namespace URI.
+type xmlElement struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []interface{} // *xmlElement, string or xml.ProcInst
+	parent   *xmlElement
+}
+
+// parseXML parses a document into a tree rooted at its document element.
+// Comments and directives are dropped, as exclusive canonicalization without
+// comments would drop them anyway, as are processing instructions outside the
+// document element.
+func parseXML(data []byte) (*xmlElement, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var root, current *xmlElement
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			elem := &xmlElement{name: t.Name, attrs: t.Copy().Attr, parent: current}
+			if current == nil {
+				if root != nil {
+					return nil, errors.New("xml: multiple root elements")
+				}
+				root = elem
+			} else {
+				current.children = append(current.children, elem)
+			}
+			current = elem
+		case xml.EndElement:
+			if current == nil || current.name != t.Name {
+				return nil, errors.New("xml: mismatched end element")
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, string(t))
+			}
+		case xml.ProcInst:
+			if current != nil {
+				current.children = append(current.children, t.Copy())
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("xml: no root element")
+	}
+	if current != nil {
+		return nil, errors.New("xml: unexpected end of document")
+	}
+
+	return root, nil
+}
+
+// lookupNamespace returns the namespace URI bound to `prefix` ("" for the default
+// namespace) in the scope of e
+func (e *xmlElement) lookupNamespace(prefix string) string {
+	if prefix == "xml" {
+		return xmlNamespace
+	}
+
+	for n := e; n != nil; n = n.parent {
+		for _, attr := range n.attrs {
+			if prefix == "" && attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+				return attr.Value
+			}
+			if prefix != "" && attr.Name.Space == "xmlns" && attr.Name.Local == prefix {
+				return attr.Value
+			}
+		}
+	}
+
+	return ""
+}
+
+// namespace returns the namespace URI of the element
+func (e *xmlElement) namespace() string {
+	return e.lookupNamespace(e.name.Space)
+}
+
+// find returns the first element in the subtree rooted at e (including e) with
+// the given namespace URI and local name
+func (e *xmlElement) find(space, local string) *xmlElement {
+	if e.name.Local == local && e.namespace() == space {
+		return e
+	}
+
+	for _, child := range e.children {
+		if elem, ok := child.(*xmlElement); ok {
+			if found := elem.find(space, local); found != nil {
+				return found
+			}
+		}
+	}
+
+	return nil
+}
+
+// remove detaches child from e
+func (e *xmlElement) remove(child *xmlElement) {
+	for i, c := range e.children {
+		if c == child {
+			e.children = append(e.children[:i:i], e.children[i+1:]...)
+			return
+		}
+	}
+}
+
+// canonicalXML returns the exclusive canonical form (without comments) of the
+// document `data`
+func canonicalXML(data []byte) ([]byte, error) {
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return root.canonicalize(), nil
+}
+
+// canonicalize returns the exclusive canonical form (without comments) of the
+// subtree rooted at e, as defined by
+// http://www.w3.org/2001/10/xml-exc-c14n#. Namespace declarations are emitted
+// only where they are visibly used and not already in effect in the output.
+func (e *xmlElement) canonicalize() []byte {
+	var buf bytes.Buffer
+	e.writeCanonical(&buf, map[string]string{})
+
+	return buf.Bytes()
+}
+
+func (e *xmlElement) writeCanonical(buf *bytes.Buffer, rendered map[string]string) {
+	// collect the prefixes visibly used by the element and its attributes
+	used := map[string]bool{e.name.Space: true}
+	var attrs []xml.Attr
+	for _, attr := range e.attrs {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			continue
+		}
+		if attr.Name.Space != "" && attr.Name.Space != "xml" {
+			used[attr.Name.Space] = true
+		}
+		attrs = append(attrs, attr)
+	}
+
+	var prefixes []string
+	for prefix := range used {
+		uri := e.lookupNamespace(prefix)
+		current, ok := rendered[prefix]
+		if current == uri && (ok || uri == "") {
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	if len(prefixes) > 0 {
+		scope := make(map[string]string, len(rendered)+len(prefixes))
+		for prefix, uri := range rendered {
+			scope[prefix] = uri
+		}
+		for _, prefix := range prefixes {
+			scope[prefix] = e.lookupNamespace(prefix)
+		}
+		rendered = scope
+	}
+
+	sort.SliceStable(attrs, func(i, j int) bool {
+		si, sj := e.lookupNamespace(attrs[i].Name.Space), e.lookupNamespace(attrs[j].Name.Space)
+		if attrs[i].Name.Space == "" {
+			si = ""
+		}
+		if attrs[j].Name.Space == "" {
+			sj = ""
+		}
+		if si != sj {
+			return si < sj
+		}
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+
+	qname := qualifiedName(e.name)
+
+	buf.WriteByte('<')
+	buf.WriteString(qname)
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			buf.WriteString(` xmlns="`)
+		} else {
+			buf.WriteString(` xmlns:` + prefix + `="`)
+		}
+		buf.WriteString(escapeCanonicalAttr(rendered[prefix]))
+		buf.WriteByte('"')
+	}
+	for _, attr := range attrs {
+		buf.WriteString(" " + qualifiedName(attr.Name) + `="`)
+		buf.WriteString(escapeCanonicalAttr(attr.Value))
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('>')
+
+	for _, child := range e.children {
+		switch c := child.(type) {
+		case *xmlElement:
+			c.writeCanonical(buf, rendered)
+		case string:
+			buf.WriteString(escapeCanonicalText(c))
+		case xml.ProcInst:
+			buf.WriteString("<?" + c.Target)
+			if len(c.Inst) > 0 {
+				buf.WriteString(" " + string(c.Inst))
+			}
+			buf.WriteString("?>")
+		}
+	}
+
+	buf.WriteString("</" + qname + ">")
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	return name.Space + ":" + name.Local
+}
+
+var canonicalTextEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\r", "&#xD;",
+)
+
+var canonicalAttrEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	`"`, "&quot;",
+	"\t", "&#x9;",
+	"\n", "&#xA;",
+	"\r", "&#xD;",
+)
+
+func escapeCanonicalText(s string) string {
+	return canonicalTextEscaper.Replace(s)
+}
+
+func escapeCanonicalAttr(s string) string {
+	return canonicalAttrEscaper.Replace(s)
+}
@@ -0,0 +1,97 @@
+package intuit
+
+import "testing"
+
+// The expected outputs were checked against xmllint --exc-c14n, with comments
+// removed since canonicalXML implements exclusive C14N without comments
+func TestCanonicalXML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "attribute order",
+			in:   `<?xml version="1.0"?><a:root xmlns:a="urn:a" xmlns:b="urn:b" z="1" b:y="2" a:x="3" c="0"><a:child/></a:root>`,
+			want: `<a:root xmlns:a="urn:a" xmlns:b="urn:b" c="0" z="1" a:x="3" b:y="2"><a:child></a:child></a:root>`,
+		},
+		{
+			name: "unused namespaces",
+			in:   `<root xmlns:unused="urn:u" xmlns:a="urn:a"><x/><a:y/></root>`,
+			want: `<root><x></x><a:y xmlns:a="urn:a"></a:y></root>`,
+		},
+		{
+			name: "default namespace",
+			in:   `<root xmlns="urn:d"><x xmlns=""><y/></x><z/></root>`,
+			want: `<root xmlns="urn:d"><x xmlns=""><y></y></x><z></z></root>`,
+		},
+		{
+			name: "escaping",
+			in:   `<e a="&lt;&amp;&gt;&quot;'&#9;&#10;&#13;">x &amp; y &lt; z &gt; w "q" 'a'&#13;</e>`,
+			want: `<e a="&lt;&amp;>&quot;'&#x9;&#xA;&#xD;">x &amp; y &lt; z &gt; w "q" 'a'&#xD;</e>`,
+		},
+		{
+			name: "comments, processing instructions and CDATA",
+			in:   `<root><!-- comment --><?pi data?><![CDATA[a < b & c]]></root>`,
+			want: `<root><?pi data?>a &lt; b &amp; c</root>`,
+		},
+		{
+			name: "xml attributes",
+			in:   `<root xml:lang="en"><child xml:space="preserve">  text  </child></root>`,
+			want: `<root xml:lang="en"><child xml:space="preserve">  text  </child></root>`,
+		},
+		{
+			name: "redeclared namespaces",
+			in:   `<p:root xmlns:p="urn:p" xmlns:q="urn:q"><p:a><q:b q:attr="1"/></p:a><p:c xmlns:p="urn:p"/><p:d xmlns:p="urn:other"/></p:root>`,
+			want: `<p:root xmlns:p="urn:p"><p:a><q:b xmlns:q="urn:q" q:attr="1"></q:b></p:a><p:c></p:c><p:d xmlns:p="urn:other"></p:d></p:root>`,
+		},
+		{
+			name: "whitespace",
+			in:   "<root   b = \"2\"    a='1'  ><x/>  </root>\n",
+			want: `<root a="1" b="2"><x></x>  </root>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := canonicalXML([]byte(test.in))
+			if err != nil {
+				t.Fatalf("canonicalXML() error = %v", err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("canonicalXML() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+// TestCanonicalizeSubtree checks that a subtree renders the namespaces it uses
+// that are declared on its ancestors, as SignedInfo does inside a Signature
+func TestCanonicalizeSubtree(t *testing.T) {
+	root, err := parseXML([]byte(`<a:root xmlns:a="urn:a" xmlns:ds="urn:ds" xmlns:unused="urn:u"><ds:Signature><ds:SignedInfo a:x="1"><ds:Method/></ds:SignedInfo></ds:Signature></a:root>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(root.find("urn:ds", "SignedInfo").canonicalize())
+	want := `<ds:SignedInfo xmlns:a="urn:a" xmlns:ds="urn:ds" a:x="1"><ds:Method></ds:Method></ds:SignedInfo>`
+	if got != want {
+		t.Errorf("canonicalize() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCanonicalXMLErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`<a>`,
+		`<a></b>`,
+		`<a/><b/>`,
+	}
+
+	for _, in := range tests {
+		if got, err := canonicalXML([]byte(in)); err == nil {
+			t.Errorf("canonicalXML(%q) = %s, want an error", in, got)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package intuit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Default settings for the client cache used by NewClient
+const (
+	DefaultClientCacheSize = 1000
+	DefaultClientCacheTTL  = time.Minute * 30
+)
+
+// ClientCache stores initialized clients keyed by customer ID. Implementations
+// must be safe for concurrent use.
+type ClientCache interface {
+	Get(customerID string) (*Client, bool)
+	Put(customerID string, client *Client)
+	Evict(customerID string)
+}
+
+// LRUClientCache is a ClientCache holding at most a fixed number of clients,
+// each for at most a fixed TTL. When the cache is full the least recently used
+// client is evicted.
+type LRUClientCache struct {
+	size int
+	ttl  time.Duration
+
+	// OnBackgroundError is called if an eviction timer panics. If nil, the
+	// package-level OnBackgroundError is used.
+	OnBackgroundError func(error)
+
+	// Clock times expiry. If nil, SystemClock is used.
+	Clock Clock
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	customerID string
+	client     *Client
+	expires    time.Time
+	timer      Timer
+}
+
+// NewLRUClientCache returns a cache holding up to `size` clients for `ttl` each.
+// A size <= 0 means the cache is unbounded, and a ttl <= 0 means clients never
+// expire.
+func NewLRUClientCache(size int, ttl time.Duration) *LRUClientCache {
+	return &LRUClientCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// Get returns the cached client for customerID, if it exists and has not
+// expired
+func (l *LRUClientCache) Get(customerID string) (*Client, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[customerID]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if l.ttl > 0 && !clockOrSystem(l.Clock).Now().Before(entry.expires) {
+		l.remove(elem)
+		return nil, false
+	}
+
+	l.order.MoveToFront(elem)
+
+	return entry.client, true
+}
+
+// Put caches client for customerID, replacing any existing client and
+// resetting its TTL
+func (l *LRUClientCache) Put(customerID string, client *Client) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[customerID]; ok {
+		l.remove(elem)
+	}
+
+	entry := &lruEntry{customerID: customerID, client: client}
+	elem := l.order.PushFront(entry)
+	l.entries[customerID] = elem
+
+	if l.ttl > 0 {
+		clock := clockOrSystem(l.Clock)
+		entry.expires = clock.Now().Add(l.ttl)
+
+		// evict the client once it expires so that idle clients don't linger
+		// until they are pushed out by newer ones
+		entry.timer = clock.AfterFunc(l.ttl, func() {
+			runBackground("client cache eviction", l.OnBackgroundError, func() {
+				l.mu.Lock()
+				defer l.mu.Unlock()
+
+				if current, ok := l.entries[customerID]; ok && current == elem {
+					l.remove(elem)
+				}
+			})
+		})
+	}
+
+	if l.size > 0 && l.order.Len() > l.size {
+		l.remove(l.order.Back())
+	}
+}
+
+// Evict removes the cached client for customerID
+func (l *LRUClientCache) Evict(customerID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[customerID]; ok {
+		l.remove(elem)
+	}
+}
+
+// Len returns the number of cached clients, including any that have expired
+// but not yet been removed
+func (l *LRUClientCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
+
+// remove deletes elem from the cache. Callers must hold l.mu.
+func (l *LRUClientCache) remove(elem *list.Element) {
+	entry := elem.Value.(*lruEntry)
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+
+	l.order.Remove(elem)
+	delete(l.entries, entry.customerID)
+}
@@ -0,0 +1,125 @@
+package intuit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUClientCache(t *testing.T) {
+	clients := map[string]*Client{"a": {}, "b": {}, "c": {}}
+
+	tests := []struct {
+		name string
+		size int
+		ops  func(l *LRUClientCache)
+		want []string // the customers still cached
+	}{
+		{
+			name: "unbounded",
+			ops: func(l *LRUClientCache) {
+				l.Put("a", clients["a"])
+				l.Put("b", clients["b"])
+				l.Put("c", clients["c"])
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "evicts least recently put",
+			size: 2,
+			ops: func(l *LRUClientCache) {
+				l.Put("a", clients["a"])
+				l.Put("b", clients["b"])
+				l.Put("c", clients["c"])
+			},
+			want: []string{"b", "c"},
+		},
+		{
+			name: "get refreshes recency",
+			size: 2,
+			ops: func(l *LRUClientCache) {
+				l.Put("a", clients["a"])
+				l.Put("b", clients["b"])
+				l.Get("a")
+				l.Put("c", clients["c"])
+			},
+			want: []string{"a", "c"},
+		},
+		{
+			name: "put replaces",
+			size: 2,
+			ops: func(l *LRUClientCache) {
+				l.Put("a", clients["b"])
+				l.Put("b", clients["b"])
+				l.Put("a", clients["a"])
+				l.Put("c", clients["c"])
+			},
+			want: []string{"a", "c"},
+		},
+		{
+			name: "evict",
+			ops: func(l *LRUClientCache) {
+				l.Put("a", clients["a"])
+				l.Put("b", clients["b"])
+				l.Evict("a")
+				l.Evict("missing")
+			},
+			want: []string{"b"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := NewLRUClientCache(test.size, 0)
+			test.ops(l)
+
+			if l.Len() != len(test.want) {
+				t.Errorf("Len() = %d, want %d", l.Len(), len(test.want))
+			}
+
+			for _, id := range test.want {
+				if got, ok := l.Get(id); !ok || got != clients[id] {
+					t.Errorf("Get(%q) = %p, %v, want %p", id, got, ok, clients[id])
+				}
+			}
+		})
+	}
+}
+
+func TestLRUClientCacheTTL(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	l := NewLRUClientCache(0, time.Minute)
+	l.Clock = clock
+
+	l.Put("a", &Client{})
+	clock.Advance(30 * time.Second)
+	l.Put("b", &Client{})
+
+	if _, ok := l.Get("a"); !ok {
+		t.Error("Get(a) missed before the TTL")
+	}
+
+	// the timer evicts a once it expires, without a Get
+	clock.Advance(30 * time.Second)
+	if l.Len() != 1 {
+		t.Errorf("Len() = %d after a expired, want 1", l.Len())
+	}
+	if _, ok := l.Get("a"); ok {
+		t.Error("Get(a) hit after the TTL")
+	}
+	if _, ok := l.Get("b"); !ok {
+		t.Error("Get(b) missed before its TTL")
+	}
+
+	// replacing b resets its TTL
+	l.Put("b", &Client{})
+	clock.Advance(45 * time.Second)
+	if _, ok := l.Get("b"); !ok {
+		t.Error("Get(b) missed after it was replaced")
+	}
+
+	clock.Advance(15 * time.Second)
+	if l.Len() != 0 {
+		t.Errorf("Len() = %d after every client expired, want 0", l.Len())
+	}
+}
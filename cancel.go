@@ -0,0 +1,22 @@
+package intuit
+
+import "fmt"
+
+// CancelledError is returned by bulk helpers whose context was cancelled before
+// all of their work finished. Such helpers stop scheduling new calls, wait for
+// calls already in flight, and return the results gathered so far alongside
+// this error. Completed and Remaining count units of work (e.g. accounts).
+type CancelledError struct {
+	Completed int
+	Remaining int
+	Err       error
+}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("cancelled with %d of %d items complete: %v", e.Completed, e.Completed+e.Remaining, e.Err)
+}
+
+// Unwrap returns the context error that caused the cancellation
+func (e *CancelledError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,114 @@
+package intuit
+
+import "encoding/json"
+
+// Categorization is Intuit's categorization of a transaction
+type Categorization struct {
+	Common  CategorizationCommon    `json:"common"`
+	Context []CategorizationContext `json:"context"`
+}
+
+// CategorizationCommon holds the categorization data shared by every context
+type CategorizationCommon struct {
+	NormalizedPayeeName string      `json:"normalizedPayeeName"`
+	Merchant            string      `json:"merchant,omitempty"`
+	SIC                 json.Number `json:"sic,omitempty"`
+}
+
+// CategorizationContext is the categorization of a transaction by one source
+type CategorizationContext struct {
+	Source       string      `json:"source"`
+	CategoryID   json.Number `json:"categoryId,omitempty"`
+	CategoryName string      `json:"categoryName"`
+	CategoryType string      `json:"categoryType,omitempty"`
+	ContextType  string      `json:"contextType,omitempty"`
+	ScheduleC    string      `json:"scheduleC"`
+	SIC          json.Number `json:"sic,omitempty"`
+
+	// Other holds the fields of the context this type has no field for, such
+	// as those added by Intuit's money services, so that they survive a round
+	// trip through JSON
+	Other map[string]json.RawMessage `json:"-"`
+}
+
+// categorizationContext has the fields of CategorizationContext without its
+// methods
+type categorizationContext CategorizationContext
+
+var categorizationContextFields = map[string]bool{
+	"source":       true,
+	"categoryId":   true,
+	"categoryName": true,
+	"categoryType": true,
+	"contextType":  true,
+	"scheduleC":    true,
+	"sic":          true,
+}
+
+// UnmarshalJSON implements the json Unmarshaler interface
+func (c *CategorizationContext) UnmarshalJSON(data []byte) error {
+	var fields categorizationContext
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for key, value := range raw {
+		if categorizationContextFields[key] {
+			continue
+		}
+
+		if fields.Other == nil {
+			fields.Other = map[string]json.RawMessage{}
+		}
+		fields.Other[key] = value
+	}
+
+	*c = CategorizationContext(fields)
+
+	return nil
+}
+
+// MarshalJSON implements the json Marshaler interface
+func (c CategorizationContext) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(categorizationContext(c))
+	if err != nil || len(c.Other) == 0 {
+		return data, err
+	}
+
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+
+	for key, value := range c.Other {
+		if _, ok := merged[key]; !ok {
+			merged[key] = value
+		}
+	}
+
+	return json.Marshal(merged)
+}
+
+// PrimaryContext returns the first context that assigns a category, or false
+// if there is none
+func (c Categorization) PrimaryContext() (CategorizationContext, bool) {
+	for _, context := range c.Context {
+		if context.CategoryName != "" {
+			return context, true
+		}
+	}
+
+	return CategorizationContext{}, false
+}
+
+// PrimaryCategory returns the category name of the primary context, or "" if
+// the transaction is uncategorized
+func (c Categorization) PrimaryCategory() string {
+	context, _ := c.PrimaryContext()
+	return context.CategoryName
+}
@@ -2,18 +2,23 @@ package intuit
 
 import (
 	"bytes"
+	"context"
+	"crypto"
 	"crypto/rsa"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
-
-	"github.com/kurrik/oauth1a"
 )
 
 // Client is an interface for accessing the Intuit CAD API
@@ -26,73 +31,152 @@ type Client struct {
 	SAMLProviderID string
 	PrivateKey     *rsa.PrivateKey
 
+	// Signer, if set, signs SAML assertions instead of PrivateKey, e.g. with a
+	// key held in an HSM or KMS. Its public key must be an RSA key.
+	Signer crypto.Signer
+
+	// AssertionOptions controls the lifetime, clock skew and audience of the
+	// SAML assertions the client creates
+	AssertionOptions AssertionOptions
+
+	// KeyRing, if set, supplies the signing key and certificate for each
+	// assertion, taking precedence over Signer and PrivateKey
+	KeyRing *KeyRing
+
+	// SigningCert, if set, is embedded in the KeyInfo of signed assertions
+	SigningCert *x509.Certificate
+
+	// SigningHash is the hash used to sign SAML assertions. If zero,
+	// crypto.SHA1 is used.
+	SigningHash crypto.Hash
+
 	HTTPClient *http.Client
 
-	initialized bool
+	// TokenURL is the SAML access token endpoint and BaseURL is the root of
+	// the CAD API. They default to AccessTokenEndpoint and BaseURL; see also
+	// WithEnvironment.
+	TokenURL string
+	BaseURL  string
 
-	clientConfig *oauth1a.ClientConfig
-	userConfig   *oauth1a.UserConfig
-	signer       oauth1a.Signer
-}
+	// RetryPolicy controls retries of transient failures. If nil, requests are
+	// not retried.
+	RetryPolicy *RetryPolicy
 
-// NewClient returns a client that uses the default settings. The client will be
-// initialized automatically. Clients will be cached for 30 minutes using
-// customerID as the key.
-func NewClient(customerID string) (*Client, error) {
-	clientsMu.Lock()
-	defer clientsMu.Unlock()
+	// RateLimiter, if set, paces every request the client sends
+	RateLimiter RateLimiter
 
-	if client, ok := clients[customerID]; ok {
-		return client, nil
-	}
+	// RangeConcurrency is the number of date windows AccountTransactionsRange
+	// fetches at once. Values <= 1 fetch them one at a time.
+	RangeConcurrency int
 
-	client := &Client{
-		CustomerID: customerID,
+	// MinStepBudget is the shortest time a step of a multi-call helper, such
+	// as a window of AccountTransactionsRange, will be started with. If a
+	// step's share of the time remaining before the context's deadline is
+	// shorter, the helper fails fast with a DeadlineBudgetError. If zero,
+	// DefaultMinStepBudget is used.
+	MinStepBudget time.Duration
 
-		ConsumerKey:    DefaultConsumerKey,
-		ConsumerSecret: DefaultConsumerSecret,
+	// TokenStore, if set, is consulted for a previously acquired OAuth token
+	// before asserting with Intuit, and receives every newly acquired token
+	TokenStore TokenStore
 
-		SAMLProviderID: DefaultSAMLProviderID,
-		PrivateKey:     DefaultPrivateKey,
+	// PrivacyKey enables privacy mode when non-empty; see WithPrivacyKey and
+	// CustomerLabel
+	PrivacyKey []byte
 
-		HTTPClient: DefaultHTTPClient,
-	}
+	// Quota, if set, counts every request the client sends
+	Quota *Quota
 
-	err := client.Init()
-	if err != nil {
-		return nil, err
-	}
+	// InstitutionCache, if set, caches institution details; see
+	// WithInstitutionCache
+	InstitutionCache InstitutionCache
+
+	// Tracer, if set, records spans for API calls and requests; see
+	// WithTracer
+	Tracer Tracer
+
+	// Metrics, if set, receives request, token and cache measurements; see
+	// WithMetrics
+	Metrics Metrics
+
+	// Cohort, if set, labels the customer's group, such as a product tier or
+	// rollout cohort, in the sync outcomes reported to Metrics; see
+	// CohortMetrics
+	Cohort string
+
+	// DebugWriter, if set, receives dumps of failed requests; see
+	// WithDebugWriter
+	DebugWriter io.Writer
 
-	clients[customerID] = client
+	// Logger, if set, receives request and token exchange logs; see WithLogger
+	Logger *slog.Logger
 
-	// spawn a new goroutine which will sleep for 30 minutes and then delete the
-	// cached client (this process will re-acquire the lock)
-	time.AfterFunc(time.Minute*30, func() {
-		clientsMu.Lock()
-		defer clientsMu.Unlock()
+	// Clock decides when tokens need renewing and, unless AssertionOptions.Now
+	// is set, the issue time of assertions. If nil, SystemClock is used.
+	Clock Clock
 
-		delete(clients, customerID)
-	})
+	// middleware wraps the transport of every request; see Use
+	middleware []Middleware
 
-	return client, nil
+	// history records recent requests for support bundles
+	history requestHistory
+
+	// debugMu serializes writes to DebugWriter
+	debugMu sync.Mutex
+
+	// institutionFlights deduplicates concurrent institution lookups
+	institutionFlights flightGroup
+
+	// concurrency bounds the number of concurrent requests; see
+	// WithMaxConcurrency and WithAdaptiveConcurrency
+	concurrency concurrencyLimiter
+
+	// mu guards initialization and the OAuth token, which may be refreshed
+	// while the client is shared between goroutines
+	mu          sync.Mutex
+	initialized bool
+
+	token         *oauthToken
+	tokenIssuedAt time.Time
 }
 
-// Init prepares the client for use by loading OAuth tokens from the Intuit API.
-// It should be only be called once per client, and it should be called before
-// any other method.
+// NewClient returns a client that uses the default settings. The client will be
+// initialized automatically. Clients are cached using customerID as the key;
+// by default up to DefaultClientCacheSize clients are kept for
+// DefaultClientCacheTTL. See SetClientCache.
+//
+// NewClient is a compatibility shim over a Manager built from the package-level
+// defaults. Applications that need more than one set of credentials should use
+// a Manager directly.
+func NewClient(customerID string) (*Client, error) {
+	clientsMu.Lock()
+	m := defaultManager()
+	clientsMu.Unlock()
+
+	return m.clientFor(customerID, &defaultClients)
+}
+
+// Init prepares the client for use by validating its configuration and loading
+// OAuth tokens from the Intuit API. It should be only be called once per
+// client, and it should be called before any other method.
 func (c *Client) Init() error {
+	return c.InitContext(context.Background())
+}
+
+// InitContext is like Init, but uses ctx for the SAML token exchange
+func (c *Client) InitContext(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.initialized {
 		return nil
 	}
 
-	c.clientConfig = &oauth1a.ClientConfig{
-		ConsumerKey:    DefaultConsumerKey,
-		ConsumerSecret: DefaultConsumerSecret,
+	if err := c.Validate(); err != nil {
+		return err
 	}
 
-	c.signer = oauth1a.Signer(&oauth1a.HmacSha1Signer{})
-
-	if err := c.loadOAuthUserConfig(); err != nil {
+	if err := c.loadOAuthUserConfig(ctx); err != nil {
 		return err
 	}
 
@@ -109,7 +193,7 @@ func (c *Client) request(method, endpoint string, body interface{}) (*http.Reque
 
 	buf := bytes.NewBuffer(bodyJSON)
 
-	req, err := http.NewRequest(method, fmt.Sprintf("%s%s", BaseURL, endpoint), buf)
+	req, err := http.NewRequest(method, c.url(endpoint), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -120,60 +204,389 @@ func (c *Client) request(method, endpoint string, body interface{}) (*http.Reque
 	return req, nil
 }
 
-func (c *Client) sign(req *http.Request) error {
-	if err := c.Init(); err != nil {
-		return err
+// Do signs and sends a request to an arbitrary CAD endpoint, for endpoints this
+// package doesn't wrap yet. `path` is relative to the client's BaseURL and may
+// include a query string. If body is non-nil it is sent as JSON, and if out is
+// non-nil a successful response is decoded into it as JSON.
+//
+// The response is returned with its body already consumed and closed, so that
+// callers can inspect the status and headers. A non-2xx status is returned as
+// an *APIError along with the response.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}, opts ...RequestOption) (*http.Response, error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	req, err := c.request(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, newAPIError(resp)
+	}
+
+	if out == nil {
+		return resp, nil
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	if err := decoder.Decode(out); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// sign signs the request with the current OAuth token, refreshing the token
+// first if it is about to expire. It returns the issue time of the token used
+// so that callers can tell whether it has since been replaced.
+func (c *Client) sign(req *http.Request) (time.Time, error) {
+	if err := c.InitContext(req.Context()); err != nil {
+		return time.Time{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == nil || c.now().Sub(c.tokenIssuedAt) > TokenLifetime-TokenRefreshMargin {
+		if err := c.loadOAuthUserConfig(req.Context()); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return c.tokenIssuedAt, signOAuth(req, c.ConsumerKey, c.ConsumerSecret, c.token, c.now())
+}
+
+// InvalidateToken discards the client's OAuth token, so that the next request
+// acquires a new one with a fresh SAML assertion. A token store's copy of the
+// discarded token is not reused.
+func (c *Client) InvalidateToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = nil
+}
+
+// Reauthenticate discards the client's OAuth token and immediately acquires a
+// new one. Use it when a token has been revoked.
+func (c *Client) Reauthenticate() error {
+	return c.ReauthenticateContext(context.Background())
+}
+
+// ReauthenticateContext is like Reauthenticate, but uses ctx for the SAML token
+// exchange
+func (c *Client) ReauthenticateContext(ctx context.Context) error {
+	c.mu.Lock()
+	initialized := c.initialized
+	c.mu.Unlock()
+
+	if !initialized {
+		return c.InitContext(ctx)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = nil
+
+	return c.loadOAuthUserConfig(ctx)
+}
+
+// refreshToken acquires a new OAuth token unless the token issued at
+// `issuedAt` has already been replaced by another request.
+func (c *Client) refreshToken(ctx context.Context, issuedAt time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tokenIssuedAt.After(issuedAt) {
+		return nil
 	}
 
-	return c.signer.Sign(req, c.clientConfig, c.userConfig)
+	return c.loadOAuthUserConfig(ctx)
 }
 
+// do sends the request, retrying transient failures according to the client's
+// retry policy
 func (c *Client) do(req *http.Request) (*http.Response, error) {
-	if err := c.sign(req); err != nil {
+	applyRequestOptions(req)
+
+	req, span := c.traceRequest(req)
+
+	start := c.now()
+	resp, attempt, err := c.doAttempts(req)
+	endRequestSpan(span, resp, attempt-1, err)
+	recordMetadata(req, resp, attempt, c.now().Sub(start))
+
+	return resp, err
+}
+
+// doAttempts implements do, additionally returning the number of attempts made
+func (c *Client) doAttempts(req *http.Request) (*http.Response, int, error) {
+	attempts := c.RetryPolicy.attempts(req)
+
+	for attempt := 1; ; attempt++ {
+		release, err := c.throttle(req.Context())
+		if err != nil {
+			return nil, attempt, err
+		}
+
+		if c.Quota != nil {
+			c.Quota.Record()
+		}
+
+		resp, err := c.send(req)
+		signErr, permanent := err.(*signError)
+		if permanent {
+			err = signErr.err
+		}
+		release(resp, err)
+
+		if permanent || attempt >= attempts || !isTransient(resp, err) || req.Context().Err() != nil {
+			return resp, attempt, err
+		}
+
+		delay := c.RetryPolicy.delay(attempt, resp)
+		c.logRetry(req, attempt, delay, resp, err)
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, attempt, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req, err = rewindRequest(req); err != nil {
+			return nil, attempt, err
+		}
+	}
+}
+
+// signError wraps an error initializing the client, acquiring a token or
+// signing a request, which retrying the request cannot fix
+type signError struct {
+	err error
+}
+
+func (e *signError) Error() string {
+	return e.err.Error()
+}
+
+// send signs and sends the request. If the API rejects the OAuth token, the
+// token is refreshed and the request is sent once more. Challenges, which are
+// also sent with status 401, are returned as is. Errors signing the request
+// are returned as *signError.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	issuedAt, err := c.sign(req)
+	if err != nil {
+		return nil, &signError{err}
+	}
+
+	resp, err := c.roundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || isChallenge(resp) {
+		return resp, err
+	}
+
+	// the token was rejected, most likely because it expired early or was
+	// revoked. Refresh it and retry the request once.
+	resp.Body.Close()
+
+	if err := c.refreshToken(req.Context(), issuedAt); err != nil {
+		return nil, &signError{err}
+	}
+
+	retry, err := rewindRequest(req)
+	if err != nil {
 		return nil, err
 	}
 
-	return c.HTTPClient.Do(req)
+	if _, err := c.sign(retry); err != nil {
+		return nil, &signError{err}
+	}
+
+	return c.roundTrip(retry)
+}
+
+// rewindRequest returns a copy of req with a fresh body so that it can be sent
+// again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody == nil {
+		return retry, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	retry.Body = body
+
+	return retry, nil
+}
+
+// now returns the current time according to the client's clock
+func (c *Client) now() time.Time {
+	return clockOrSystem(c.Clock).Now()
+}
+
+func (c *Client) tokenURL() string {
+	if c.TokenURL == "" {
+		return AccessTokenEndpoint
+	}
+
+	return c.TokenURL
 }
 
 func (c *Client) url(path string) string {
-	return fmt.Sprintf("%s%s", BaseURL, path)
+	if c.BaseURL == "" {
+		return fmt.Sprintf("%s%s", BaseURL, path)
+	}
+
+	return fmt.Sprintf("%s%s", c.BaseURL, path)
+}
+
+// loadOAuthUserConfig acquires an OAuth token for the client, from its token
+// store if that holds a usable token newer than the current one, or else by
+// exchanging a freshly signed SAML assertion. Callers must hold c.mu.
+func (c *Client) loadOAuthUserConfig(ctx context.Context) error {
+	if c.TokenStore != nil {
+		token, secret, issuedAt, err := c.TokenStore.Load(c.CustomerID)
+		usable := err == nil && issuedAt.After(c.tokenIssuedAt) && c.now().Sub(issuedAt) < TokenLifetime-TokenRefreshMargin
+		metricsOrNop(c.Metrics).ObserveCache(CacheTokens, usable)
+
+		switch {
+		case usable:
+			c.token = &oauthToken{key: token, secret: secret}
+			c.tokenIssuedAt = issuedAt
+			return nil
+		case err != nil && !errors.Is(err, ErrTokenNotFound):
+			return fmt.Errorf("token store error: %w", err)
+		}
+	}
+
+	token, secret, err := c.exchangeAssertion(ctx)
+	metricsOrNop(c.Metrics).ObserveTokenRefresh(err)
+	if err != nil {
+		return err
+	}
+
+	c.token = &oauthToken{key: token, secret: secret}
+	c.tokenIssuedAt = c.now()
+
+	if c.TokenStore != nil {
+		if err := c.TokenStore.Save(c.CustomerID, token, secret, c.tokenIssuedAt); err != nil {
+			return fmt.Errorf("token store error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// signAssertion signs the assertion with the active key from the client's key
+// ring, or else with its signer or private key
+func (c *Client) signAssertion(assertion *Assertion) error {
+	assertion.SigningCert = c.SigningCert
+
+	hashFunc := c.SigningHash
+	if hashFunc == 0 {
+		hashFunc = crypto.SHA1
+	}
+
+	if c.KeyRing != nil {
+		key, err := c.KeyRing.Select(c.now())
+		if err != nil {
+			return err
+		}
+
+		if key.Cert != nil {
+			assertion.SigningCert = key.Cert
+		}
+
+		return assertion.SignWithSigner(key.Signer, hashFunc)
+	}
+
+	if c.Signer != nil {
+		return assertion.SignWithSigner(c.Signer, hashFunc)
+	}
+
+	return assertion.SignWith(c.PrivateKey, hashFunc)
 }
 
-func (c *Client) loadOAuthUserConfig() error {
+// exchangeAssertion exchanges a freshly signed SAML assertion for an OAuth
+// token. The exchange goes through the client's HTTP client and middleware, so
+// proxy, timeout and TLS settings apply to it.
+func (c *Client) exchangeAssertion(ctx context.Context) (token, secret string, err error) {
+	ctx, span := c.startSpan(ctx, "CAD token exchange", SpanKindClient)
+	defer func() { endSpan(span, err) }()
+
 	if c.CustomerID == "" {
-		return errors.New("customer id must not be empty")
+		return "", "", errors.New("customer id must not be empty")
 	}
 
-	assertion := NewAssertion(c.SAMLProviderID, c.CustomerID, time.Minute*10)
-	if err := assertion.Sign(c.PrivateKey); err != nil {
-		return fmt.Errorf("unable to sign assertion: %v", err)
+	opts := c.AssertionOptions
+	if opts.Now == nil {
+		opts.Now = c.now
+	}
+
+	assertion, err := NewAssertionWithOptions(c.SAMLProviderID, c.CustomerID, opts)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := c.signAssertion(&assertion); err != nil {
+		return "", "", fmt.Errorf("unable to sign assertion: %w", err)
 	}
 
 	samlString, err := xml.Marshal(assertion)
 	if err != nil {
-		return fmt.Errorf("unable to marshal assertion: %v", err)
+		return "", "", fmt.Errorf("unable to marshal assertion: %w", err)
 	}
 
 	values := make(url.Values)
 	values.Set("saml_assertion", base64.URLEncoding.EncodeToString(samlString))
 	values.Set("oauth_consumer_key", c.ConsumerKey)
 
-	resp, err := http.PostForm(AccessTokenEndpoint, values)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.tokenURL(), strings.NewReader(values.Encode()))
 	if err != nil {
-		return fmt.Errorf("token request error: %s", err)
+		return "", "", fmt.Errorf("token request error: %w", err)
 	}
 
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	start := c.now()
+	resp, err := c.httpClient().Do(req)
+	duration := c.now().Sub(start)
+	c.logResponse(req, "CAD token exchange", resp, err, duration)
+	c.observeRequest(req, resp, err, duration)
+	c.debugFailure(req, resp, err, start, duration)
+	if resp != nil {
+		span.SetAttributes(SpanAttribute{"http.response.status_code", resp.StatusCode})
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("token request error for customer %s: %w", c.CustomerLabel(), err)
+	}
+
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
-		errmsg, _ := url.QueryUnescape(resp.Header.Get("Www-Authenticate"))
-		return fmt.Errorf("authentication error: %s %s", resp.Status, errmsg)
+		return "", "", newSAMLAuthError(c.CustomerLabel(), resp)
 	}
 
 	body, _ := ioutil.ReadAll(resp.Body)
 	respQuery, _ := url.ParseQuery(string(body))
 
-	token, secret := respQuery.Get("oauth_token"), respQuery.Get("oauth_token_secret")
-	c.userConfig = oauth1a.NewAuthorizedConfig(token, secret)
-
-	return nil
+	return respQuery.Get("oauth_token"), respQuery.Get("oauth_token_secret"), nil
 }
@@ -0,0 +1,117 @@
+package intuit_test
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/intuittest"
+)
+
+// requestCounter is a middleware counting token exchanges and API requests
+type requestCounter struct {
+	mu        sync.Mutex
+	exchanges int
+	requests  int
+}
+
+func (rc *requestCounter) middleware(next http.RoundTripper) http.RoundTripper {
+	return intuit.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		rc.mu.Lock()
+		if req.URL.Path == intuittest.TokenPath {
+			rc.exchanges++
+		} else {
+			rc.requests++
+		}
+		rc.mu.Unlock()
+
+		return next.RoundTrip(req)
+	})
+}
+
+func (rc *requestCounter) counts() (exchanges, requests int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return rc.exchanges, rc.requests
+}
+
+func TestRefreshTokenAfterUnauthorized(t *testing.T) {
+	var counter requestCounter
+	srv, client := newTestServer(t, intuit.WithMiddleware(counter.middleware))
+
+	if _, err := client.GetCustomerAccounts(); err != nil {
+		t.Fatal(err)
+	}
+	if exchanges, requests := counter.counts(); exchanges != 1 || requests != 1 {
+		t.Fatalf("got %d exchanges and %d requests, want 1 of each", exchanges, requests)
+	}
+
+	srv.RevokeTokens(testCustomer)
+
+	accounts, err := client.GetCustomerAccounts()
+	if err != nil {
+		t.Fatalf("GetCustomerAccounts() after revoking the token: %v", err)
+	}
+	if len(accounts) != 7 {
+		t.Errorf("got %d accounts, want 7", len(accounts))
+	}
+
+	// the rejected request is retried once with a new token
+	if exchanges, requests := counter.counts(); exchanges != 2 || requests != 3 {
+		t.Errorf("got %d exchanges and %d requests, want 2 and 3", exchanges, requests)
+	}
+}
+
+func TestRefreshTokenResendsBody(t *testing.T) {
+	const institutionID int64 = 100000
+
+	srv, client := newTestServer(t)
+	srv.AddDiscovery(institutionID, false, intuit.Account{
+		ID:                     500000000001,
+		LoginID:                29000001,
+		Status:                 intuit.AccountStatusActive,
+		FinancialInstitutionID: institutionID,
+	})
+
+	if _, err := client.GetCustomerAccounts(); err != nil {
+		t.Fatal(err)
+	}
+	srv.RevokeTokens(testCustomer)
+
+	credentials := []intuit.Credential{{Name: "Banking Userid", Value: "demo"}, {Name: "Banking Password", Value: "go"}}
+	accounts, challenge, err := client.DiscoverAndAddAccounts(institutionID, credentials)
+	if err != nil {
+		t.Fatalf("DiscoverAndAddAccounts() after revoking the token: %v", err)
+	}
+	if challenge != nil || len(accounts) != 1 || accounts[0].ID != 500000000001 {
+		t.Errorf("got accounts %v and challenge %v, want the discovered account", accounts, challenge)
+	}
+}
+
+func TestRefreshTokenRetriesOnce(t *testing.T) {
+	var counter requestCounter
+
+	// every API request is rejected as if the new token were revoked too
+	reject := func(next http.RoundTripper) http.RoundTripper {
+		return intuit.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != intuittest.TokenPath {
+				req = req.Clone(req.Context())
+				req.Header.Set("Authorization", `OAuth oauth_token="revoked"`)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+
+	_, client := newTestServer(t, intuit.WithMiddleware(counter.middleware, reject))
+
+	_, err := client.GetCustomerAccounts()
+	if !errors.Is(err, intuit.ErrUnauthorized) {
+		t.Fatalf("GetCustomerAccounts() error = %v, want ErrUnauthorized", err)
+	}
+	if exchanges, requests := counter.counts(); exchanges != 2 || requests != 2 {
+		t.Errorf("got %d exchanges and %d requests, want 2 of each", exchanges, requests)
+	}
+}
@@ -0,0 +1,140 @@
+package intuit
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is a source of time. The client cache, token renewal and anything else
+// in the package that schedules work against the wall clock can be given a
+// Clock, so that tests can advance time synthetically with a ManualClock.
+type Clock interface {
+	Now() time.Time
+
+	// AfterFunc calls f in its own goroutine once d has elapsed, as with
+	// time.AfterFunc
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call scheduled with Clock.AfterFunc
+type Timer interface {
+	// Stop prevents the call from happening, returning false if it has
+	// already happened or been stopped
+	Stop() bool
+}
+
+// SystemClock is the Clock backed by the time package, used wherever no
+// Clock is set
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// clockOrSystem returns clock, or SystemClock if clock is nil
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+
+	return clock
+}
+
+// ManualClock is a Clock that only moves when told to. Calls scheduled with
+// AfterFunc run synchronously, in order, from the Advance or Set call that
+// makes them due.
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	clock *ManualClock
+	when  time.Time
+	f     func()
+}
+
+// NewManualClock returns a clock stopped at `start`
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the clock's current time
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// AfterFunc schedules f to run once the clock has been advanced by d
+func (c *ManualClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &manualTimer{clock: c, when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+
+	return t
+}
+
+// Advance moves the clock forward by d, running any calls that become due
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to `now`, running any calls that become due. The clock
+// never moves backwards; an earlier time is ignored.
+func (c *ManualClock) Set(now time.Time) {
+	c.mu.Lock()
+	if now.After(c.now) {
+		c.now = now
+	}
+
+	var due, pending []*manualTimer
+	for _, t := range c.timers {
+		if t.when.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			due = append(due, t)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].when.Before(due[j].when) })
+	for _, t := range due {
+		t.f()
+	}
+}
+
+func (t *manualTimer) Stop() bool {
+	c := t.clock
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithClock sets the clock used for token renewal and assertion timestamps
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.Clock = clock
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+func accountsList(ctx context.Context, e *env, args []string) error {
+	flags := newFlagSet(e, "accounts list")
+	asJSON := flags.Bool("json", false, "print the accounts as JSON")
+	if err := parseFlags(flags, args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	accounts, err := client.GetCustomerAccountsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return writeJSON(e, accounts)
+	}
+
+	printAccounts(e, accounts)
+
+	return nil
+}
+
+func transactionsPull(ctx context.Context, e *env, args []string) error {
+	flags := newFlagSet(e, "transactions pull")
+	since := flags.String("since", "", "first posted `date` to pull, as YYYY-MM-DD")
+	until := flags.String("until", "", "last posted `date` to pull, as YYYY-MM-DD (default today)")
+	accountID := flags.Int64("account", 0, "pull only the account with this `ID` (default all active accounts)")
+	format := flags.String("format", "ndjson", "output `format`: ndjson, json or csv")
+	if err := parseFlags(flags, args); err != nil || flags.NArg() > 0 || *since == "" {
+		return errUsage
+	}
+
+	start, err := intuit.ParseDate(*since)
+	if err != nil {
+		return fmt.Errorf("bad -since: %w", err)
+	}
+
+	end := intuit.DateOf(time.Now().UTC())
+	if *until != "" {
+		if end, err = intuit.ParseDate(*until); err != nil {
+			return fmt.Errorf("bad -until: %w", err)
+		}
+	}
+
+	switch *format {
+	case "ndjson", "json", "csv":
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	accountIDs := []int64{*accountID}
+	if *accountID == 0 {
+		accounts, err := client.GetCustomerAccountsContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		accountIDs = accountIDs[:0]
+		for _, account := range accounts {
+			if account.IsActive() {
+				accountIDs = append(accountIDs, account.ID)
+			}
+		}
+	}
+
+	if *format == "ndjson" {
+		// stream each account's payload straight to the output
+		for _, id := range accountIDs {
+			it, err := client.StreamAccountTransactions(ctx, id, start, end)
+			if err != nil {
+				return fmt.Errorf("account %d: %w", id, err)
+			}
+
+			_, err = intuit.WriteNDJSON(e.stdout, it)
+			it.Close()
+			if err != nil {
+				return fmt.Errorf("account %d: %w", id, err)
+			}
+		}
+
+		return nil
+	}
+
+	list := intuit.TransactionList{}
+	for _, id := range accountIDs {
+		txns, err := client.AccountTransactionsForDatesContext(ctx, id, start, end)
+		if err != nil {
+			return fmt.Errorf("account %d: %w", id, err)
+		}
+
+		for key, t := range txns {
+			list[key] = append(list[key], t...)
+		}
+	}
+
+	if *format == "csv" {
+		return list.WriteCSV(e.stdout, intuit.CSVOptions{})
+	}
+
+	return writeJSON(e, list)
+}
+
+func institutionsSearch(ctx context.Context, e *env, args []string) error {
+	flags := newFlagSet(e, "institutions search")
+	limit := flags.Int("limit", 20, "print at most `N` institutions")
+	if err := parseFlags(flags, args); err != nil || flags.NArg() == 0 {
+		return errUsage
+	}
+
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	institutions, err := client.InstitutionsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	index := intuit.NewInstitutionIndex(institutions)
+	matches, total := index.Search(strings.Join(flags.Args(), " "), 0, *limit)
+
+	w := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tHOME URL")
+	for _, details := range matches {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", details.ID, details.Name, details.HomeURL)
+	}
+	w.Flush()
+
+	if total > len(matches) {
+		fmt.Fprintf(e.stderr, "%d of %d matches shown\n", len(matches), total)
+	}
+
+	return nil
+}
+
+func loginAdd(ctx context.Context, e *env, args []string) error {
+	values := credentialValues{}
+
+	flags := newFlagSet(e, "login add")
+	institutionID := flags.Int64("institution", 0, "institution `ID`")
+	flags.Var(values, "cred", "credential as `NAME=VALUE`, left in shell history and the process list; missing credentials are prompted for")
+	if err := parseFlags(flags, args); err != nil || flags.NArg() > 0 || *institutionID == 0 {
+		return errUsage
+	}
+
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	details, err := client.InstitutionDetailsContext(ctx, *institutionID)
+	if err != nil {
+		return err
+	}
+
+	form := intuit.NewCredentialForm(details)
+	for _, field := range form.Fields {
+		if _, ok := values[field.Name]; ok {
+			continue
+		}
+
+		label := field.Label
+		if field.Instructions != "" {
+			label += " (" + field.Instructions + ")"
+		}
+
+		if values[field.Name], err = e.prompt(label, field.Masked); err != nil {
+			return err
+		}
+	}
+
+	credentials, err := form.Credentials(values)
+	if err != nil {
+		return err
+	}
+
+	accounts, challenge, err := client.DiscoverAndAddAccountsContext(ctx, *institutionID, credentials)
+	if err != nil {
+		return err
+	}
+
+	return printOutcome(e, accounts, challenge)
+}
+
+func challengeAnswer(ctx context.Context, e *env, args []string) error {
+	challenge := &intuit.Challenge{}
+
+	flags := newFlagSet(e, "challenge answer")
+	flags.Int64Var(&challenge.InstitutionID, "institution", 0, "institution `ID`")
+	flags.StringVar(&challenge.SessionID, "session", "", "challenge session `ID`")
+	flags.StringVar(&challenge.NodeID, "node", "", "challenge node `ID`")
+	if err := parseFlags(flags, args); err != nil || flags.NArg() == 0 || challenge.InstitutionID == 0 || challenge.SessionID == "" {
+		return errUsage
+	}
+
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	accounts, next, err := client.AnswerChallengeContext(ctx, challenge, flags.Args())
+	if err != nil {
+		return err
+	}
+
+	return printOutcome(e, accounts, next)
+}
+
+// printOutcome prints the accounts added by a login, or the challenge raised
+// instead along with how to answer it
+func printOutcome(e *env, accounts []intuit.Account, challenge *intuit.Challenge) error {
+	if challenge == nil {
+		printAccounts(e, accounts)
+		return nil
+	}
+
+	fmt.Fprintln(e.stdout, "The institution requires answers to a challenge:")
+	for i, q := range challenge.Questions {
+		fmt.Fprintf(e.stdout, "  %d. %s\n", i+1, q.Text)
+		if len(q.Choices) > 0 {
+			fmt.Fprintf(e.stdout, "     choices: %s\n", strings.Join(q.Choices, ", "))
+		}
+	}
+
+	fmt.Fprintf(e.stdout, "\nAnswer with:\n  intuit-cad challenge answer -institution %d -session %s -node %s ANSWER...\n",
+		challenge.InstitutionID, challenge.SessionID, challenge.NodeID)
+
+	return nil
+}
+
+func printAccounts(e *env, accounts []intuit.Account) {
+	w := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tLOGIN\tINSTITUTION\tNAME\tBALANCE\tSTATUS\tAGGREGATION")
+	for _, a := range accounts {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%s %s\t%s\t%s\n",
+			a.ID, a.LoginID, a.FinancialInstitutionID, a.Name, a.Balance, a.Currency, a.Status, a.AggrStatusCode)
+	}
+	w.Flush()
+}
+
+func writeJSON(e *env, v interface{}) error {
+	encoder := json.NewEncoder(e.stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+// credentialValues collects repeated NAME=VALUE flags
+type credentialValues map[string]string
+
+var _ flag.Value = credentialValues{}
+
+func (v credentialValues) String() string {
+	return ""
+}
+
+func (v credentialValues) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("expected NAME=VALUE, got %q", s)
+	}
+
+	v[s[:i]] = s[i+1:]
+
+	return nil
+}
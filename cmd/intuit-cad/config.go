@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+// config holds the credentials and settings the tool needs to build a client.
+// It is read from a JSON file, then overridden by environment variables, then
+// by flags.
+type config struct {
+	intuit.Config
+
+	CustomerID string `json:"customer_id"`
+}
+
+// defaultConfigPath returns the config file used when none is given: the
+// INTUIT_CAD_CONFIG environment variable, or intuit-cad/config.json in the
+// user's config directory
+func defaultConfigPath() string {
+	if path := os.Getenv("INTUIT_CAD_CONFIG"); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "intuit-cad", "config.json")
+}
+
+// loadConfig reads the config file at `path`, if any, and applies the
+// environment. A missing file is only an error if `required` is set.
+func loadConfig(path string, required bool) (*config, error) {
+	cfg := &config{}
+
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		switch {
+		case os.IsNotExist(err) && !required:
+		case err != nil:
+			return nil, err
+		default:
+			if err := json.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("reading %s: %w", path, err)
+			}
+		}
+	}
+
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+
+	if customerID, ok := os.LookupEnv("INTUIT_CAD_CUSTOMER_ID"); ok {
+		cfg.CustomerID = customerID
+	}
+
+	return cfg, nil
+}
+
+// options returns the client options for the config
+func (cfg *config) options() ([]intuit.Option, error) {
+	var missing []string
+	if cfg.ConsumerKey == "" {
+		missing = append(missing, "consumer_key")
+	}
+	if cfg.ConsumerSecret == "" {
+		missing = append(missing, "consumer_secret")
+	}
+	if cfg.SAMLProviderID == "" {
+		missing = append(missing, "saml_provider_id")
+	}
+	if cfg.PrivateKeyFile == "" && cfg.PrivateKeyPEM == "" {
+		missing = append(missing, "private_key_file")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing configuration: %v", missing)
+	}
+
+	return cfg.Config.Options()
+}
@@ -0,0 +1,224 @@
+// Command intuit-cad is a command-line client for the CAD API, for trying out
+// the intuit package against a real application and for support and debugging.
+//
+// Usage:
+//
+//	intuit-cad [flags] <command> <subcommand> [flags] [arguments]
+//
+// The commands are:
+//
+//	accounts list                    list the customer's accounts
+//	transactions pull -since DATE    write the customer's transactions
+//	institutions search QUERY        search the institution catalog by name
+//	login add -institution ID        add a login with the end user's credentials
+//	challenge answer ANSWER...       answer a challenge raised by login add
+//
+// login add prompts for each credential not given with -cred, reading masked
+// fields such as passwords without echo when stdin is a terminal. Values
+// given as -cred NAME=VALUE are left in the shell history and are visible to
+// other users in the process list, so pass secrets that way only in scripts
+// that take them from a secure source.
+//
+// Credentials are read from a JSON config file (-config, INTUIT_CAD_CONFIG or
+// intuit-cad/config.json in the user's config directory) with the keys of
+// intuit.Config, such as consumer_key, consumer_secret, saml_provider_id and
+// private_key_file, and customer_id. Each can be overridden by the
+// environment variable of the same name in upper case, prefixed with
+// INTUIT_CAD_, e.g. INTUIT_CAD_CONSUMER_KEY.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"golang.org/x/term"
+)
+
+// command is a subcommand of the tool
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, env *env, args []string) error
+}
+
+var commands = []command{
+	{"accounts list", "[-json]", accountsList},
+	{"transactions pull", "-since DATE [-until DATE] [-account ID] [-format ndjson|json|csv]", transactionsPull},
+	{"institutions search", "[-limit N] QUERY", institutionsSearch},
+	{"login add", "-institution ID [-cred NAME=VALUE]...", loginAdd},
+	{"challenge answer", "-institution ID -session ID -node ID ANSWER...", challengeAnswer},
+}
+
+// errUsage is returned by commands given bad arguments
+var errUsage = errors.New("usage error")
+
+// env is the environment commands run in
+type env struct {
+	cfg    *config
+	debug  bool
+	stdin  *bufio.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	// readPassword reads a line without echoing it, or is nil if stdin is
+	// not a terminal
+	readPassword func() ([]byte, error)
+}
+
+// client returns a client for the configured customer
+func (e *env) client() (*intuit.Client, error) {
+	if e.cfg.CustomerID == "" {
+		return nil, fmt.Errorf("no customer ID; set -customer, customer_id or INTUIT_CAD_CUSTOMER_ID")
+	}
+
+	opts, err := e.cfg.options()
+	if err != nil {
+		return nil, err
+	}
+
+	if e.debug {
+		opts = append(opts, intuit.WithDebugWriter(e.stderr))
+	}
+
+	return intuit.NewClientWithOptions(e.cfg.CustomerID, opts...)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("intuit-cad", flag.ContinueOnError)
+	flags.Usage = func() { usage(flags) }
+
+	configPath := flags.String("config", "", "path to the JSON config `file`")
+	customerID := flags.String("customer", "", "customer `ID`, overriding the config")
+	timeout := flags.Duration("timeout", 5*time.Minute, "give up after `duration`")
+	debug := flags.Bool("debug", false, "dump failed requests and responses to stderr")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	args = flags.Args()
+	if len(args) < 2 {
+		usage(flags)
+		return 2
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == args[0]+" "+args[1] {
+			cmd = &commands[i]
+		}
+	}
+
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "intuit-cad: unknown command %q\n", args[0]+" "+args[1])
+		usage(flags)
+		return 2
+	}
+
+	path, required := *configPath, true
+	if path == "" {
+		path, required = defaultConfigPath(), false
+	}
+
+	cfg, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intuit-cad: %v\n", err)
+		return 1
+	}
+
+	if *customerID != "" {
+		cfg.CustomerID = *customerID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	e := &env{
+		cfg:    cfg,
+		debug:  *debug,
+		stdin:  bufio.NewReader(os.Stdin),
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		e.readPassword = func() ([]byte, error) { return term.ReadPassword(fd) }
+	}
+
+	switch err := cmd.run(ctx, e, args[2:]); {
+	case err == errUsage:
+		fmt.Fprintf(os.Stderr, "usage: intuit-cad %s %s\n", cmd.name, cmd.usage)
+		return 2
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "intuit-cad: %s: %v\n", cmd.name, err)
+		return 1
+	}
+
+	return 0
+}
+
+func usage(flags *flag.FlagSet) {
+	out := flags.Output()
+
+	fmt.Fprintln(out, "usage: intuit-cad [flags] <command> <subcommand> [flags] [arguments]")
+	fmt.Fprintln(out, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(out, "\nflags:")
+	flags.PrintDefaults()
+}
+
+// newFlagSet returns the flag set of a command, printing errors but not usage
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	flags.Usage = func() {}
+
+	return flags
+}
+
+// parseFlags parses a command's flags, returning errUsage on failure
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	return nil
+}
+
+// prompt asks for a line of input. If masked is set and stdin is a terminal,
+// the input is not echoed.
+func (e *env) prompt(label string, masked bool) (string, error) {
+	fmt.Fprintf(e.stderr, "%s: ", label)
+
+	if masked && e.readPassword != nil {
+		line, err := e.readPassword()
+		fmt.Fprintln(e.stderr)
+
+		return string(line), err
+	}
+
+	line, err := e.stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
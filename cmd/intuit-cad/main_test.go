@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		masked   bool
+		terminal bool
+		want     string
+	}{
+		{name: "plain", want: "typed"},
+		{name: "plain on a terminal", terminal: true, want: "typed"},
+		{name: "masked on a terminal", masked: true, terminal: true, want: "hidden"},
+		{name: "masked from a pipe", masked: true, want: "typed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			e := &env{
+				stdin:  bufio.NewReader(strings.NewReader("typed\n")),
+				stderr: &stderr,
+			}
+			if test.terminal {
+				e.readPassword = func() ([]byte, error) { return []byte("hidden"), nil }
+			}
+
+			got, err := e.prompt("Password", test.masked)
+			if err != nil {
+				t.Fatalf("prompt() error = %v", err)
+			}
+			if got != test.want {
+				t.Errorf("prompt() = %q, want %q", got, test.want)
+			}
+			if !strings.HasPrefix(stderr.String(), "Password: ") {
+				t.Errorf("prompt() wrote %q", stderr.String())
+			}
+		})
+	}
+}
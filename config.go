@@ -0,0 +1,352 @@
+package intuit
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the settings of an Intuit application, so that deployments can
+// build clients from a file or the environment instead of bootstrap code. See
+// LoadConfigFromFile and LoadConfigFromEnv.
+type Config struct {
+	ConsumerKey    string `json:"consumer_key"`
+	ConsumerSecret string `json:"consumer_secret"`
+	SAMLProviderID string `json:"saml_provider_id"`
+
+	// PrivateKeyPEM is the PEM-encoded signing key itself, and PrivateKeyFile
+	// the path of a file holding it. PrivateKeyPEM takes precedence.
+	PrivateKeyPEM        string `json:"private_key_pem,omitempty"`
+	PrivateKeyFile       string `json:"private_key_file,omitempty"`
+	PrivateKeyPassphrase string `json:"private_key_passphrase,omitempty"`
+
+	// Environment is "production" or "testing", which currently share
+	// endpoints; see Environment. BaseURL and TokenURL, if set, override the
+	// environment's endpoints.
+	Environment string `json:"environment,omitempty"`
+	BaseURL     string `json:"base_url,omitempty"`
+	TokenURL    string `json:"token_url,omitempty"`
+
+	// ClientCacheSize and ClientCacheTTL configure the client cache of
+	// managers. Zero values use DefaultClientCacheSize and
+	// DefaultClientCacheTTL.
+	ClientCacheSize int      `json:"client_cache_size,omitempty"`
+	ClientCacheTTL  Duration `json:"client_cache_ttl,omitempty"`
+
+	// Retry, if set, enables retries of transient failures
+	Retry *RetryConfig `json:"retry,omitempty"`
+}
+
+// RetryConfig configures a RetryPolicy. Zero fields use the values of
+// DefaultRetryPolicy.
+type RetryConfig struct {
+	MaxAttempts        int      `json:"max_attempts,omitempty"`
+	BaseDelay          Duration `json:"base_delay,omitempty"`
+	MaxDelay           Duration `json:"max_delay,omitempty"`
+	Jitter             float64  `json:"jitter,omitempty"`
+	RetryNonIdempotent bool     `json:"retry_non_idempotent,omitempty"`
+}
+
+// Policy returns the retry policy
+func (r RetryConfig) Policy() RetryPolicy {
+	policy := DefaultRetryPolicy
+	if r.MaxAttempts != 0 {
+		policy.MaxAttempts = r.MaxAttempts
+	}
+	if r.BaseDelay != 0 {
+		policy.BaseDelay = time.Duration(r.BaseDelay)
+	}
+	if r.MaxDelay != 0 {
+		policy.MaxDelay = time.Duration(r.MaxDelay)
+	}
+	if r.Jitter != 0 {
+		policy.Jitter = r.Jitter
+	}
+	policy.RetryNonIdempotent = r.RetryNonIdempotent
+
+	return policy
+}
+
+// Duration is a time.Duration written in configuration as a string such as
+// "30s" or "5m"
+type Duration time.Duration
+
+// MarshalJSON implements the json Marshaler interface
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements the json Unmarshaler interface
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\", not %s", data)
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// configEnv lists the environment variables read by Config.ApplyEnv. Each is
+// named after its configuration key, upper-cased and prefixed with
+// INTUIT_CAD_.
+var configEnv = []struct {
+	name string
+	set  func(c *Config, value string) error
+}{
+	{"INTUIT_CAD_CONSUMER_KEY", setConfigString(func(c *Config) *string { return &c.ConsumerKey })},
+	{"INTUIT_CAD_CONSUMER_SECRET", setConfigString(func(c *Config) *string { return &c.ConsumerSecret })},
+	{"INTUIT_CAD_SAML_PROVIDER_ID", setConfigString(func(c *Config) *string { return &c.SAMLProviderID })},
+	{"INTUIT_CAD_PRIVATE_KEY_PEM", setConfigString(func(c *Config) *string { return &c.PrivateKeyPEM })},
+	{"INTUIT_CAD_PRIVATE_KEY_FILE", setConfigString(func(c *Config) *string { return &c.PrivateKeyFile })},
+	{"INTUIT_CAD_PRIVATE_KEY_PASSPHRASE", setConfigString(func(c *Config) *string { return &c.PrivateKeyPassphrase })},
+	{"INTUIT_CAD_ENVIRONMENT", setConfigString(func(c *Config) *string { return &c.Environment })},
+	{"INTUIT_CAD_BASE_URL", setConfigString(func(c *Config) *string { return &c.BaseURL })},
+	{"INTUIT_CAD_TOKEN_URL", setConfigString(func(c *Config) *string { return &c.TokenURL })},
+	{"INTUIT_CAD_CLIENT_CACHE_SIZE", setConfigInt(func(c *Config) *int { return &c.ClientCacheSize })},
+	{"INTUIT_CAD_CLIENT_CACHE_TTL", setConfigDuration(func(c *Config) *Duration { return &c.ClientCacheTTL })},
+	{"INTUIT_CAD_RETRY_MAX_ATTEMPTS", setConfigInt(func(c *Config) *int { return &c.retry().MaxAttempts })},
+	{"INTUIT_CAD_RETRY_BASE_DELAY", setConfigDuration(func(c *Config) *Duration { return &c.retry().BaseDelay })},
+	{"INTUIT_CAD_RETRY_MAX_DELAY", setConfigDuration(func(c *Config) *Duration { return &c.retry().MaxDelay })},
+	{"INTUIT_CAD_RETRY_JITTER", setConfigFloat(func(c *Config) *float64 { return &c.retry().Jitter })},
+	{"INTUIT_CAD_RETRY_NON_IDEMPOTENT", setConfigBool(func(c *Config) *bool { return &c.retry().RetryNonIdempotent })},
+}
+
+func setConfigString(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setConfigInt(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func setConfigFloat(field func(*Config) *float64) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func setConfigBool(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func setConfigDuration(field func(*Config) *Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = Duration(d)
+		return nil
+	}
+}
+
+// retry returns the retry settings, creating them if needed
+func (c *Config) retry() *RetryConfig {
+	if c.Retry == nil {
+		c.Retry = &RetryConfig{}
+	}
+
+	return c.Retry
+}
+
+// LoadConfigFromEnv returns the configuration given by the INTUIT_CAD_*
+// environment variables; see Config.ApplyEnv
+func LoadConfigFromEnv() (*Config, error) {
+	cfg := &Config{}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LoadConfigFromFile reads the configuration from the file at `path`. Files
+// named *.yaml or *.yml are read as YAML; other files are read as JSON. Keys
+// are those of the Config JSON tags, e.g. consumer_key, and durations are
+// strings such as "30s". In YAML, the PEM of private_key_pem can be given as
+// a literal block scalar (private_key_pem: |).
+func LoadConfigFromFile(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if data, err = yamlToJSON(data, cfg); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// ApplyEnv overrides the configuration with the environment variables that are
+// set, each named after its key, upper-cased and prefixed with INTUIT_CAD_,
+// e.g. INTUIT_CAD_CONSUMER_KEY or INTUIT_CAD_RETRY_MAX_ATTEMPTS
+func (c *Config) ApplyEnv() error {
+	for _, env := range configEnv {
+		value, ok := os.LookupEnv(env.name)
+		if !ok {
+			continue
+		}
+
+		if err := env.set(c, value); err != nil {
+			return fmt.Errorf("%s: %w", env.name, err)
+		}
+	}
+
+	return nil
+}
+
+// privateKey loads the configured private key, or returns nil if none is
+// configured
+func (c *Config) privateKey() (*rsa.PrivateKey, error) {
+	switch {
+	case c.PrivateKeyPEM != "":
+		return DecodePrivateKeyPEM([]byte(c.PrivateKeyPEM), []byte(c.PrivateKeyPassphrase))
+	case c.PrivateKeyFile != "":
+		return LoadPrivateKeyFromFile(c.PrivateKeyFile, c.PrivateKeyPassphrase)
+	}
+
+	return nil, nil
+}
+
+// endpoints returns the token URL and base URL for the configuration
+func (c *Config) endpoints() (tokenURL, baseURL string, err error) {
+	env := Production
+	if c.Environment != "" {
+		if env, err = ParseEnvironment(c.Environment); err != nil {
+			return "", "", err
+		}
+	}
+
+	tokenURL, baseURL = env.TokenURL(), env.BaseURL()
+	if c.TokenURL != "" {
+		tokenURL = c.TokenURL
+	}
+	if c.BaseURL != "" {
+		baseURL = c.BaseURL
+	}
+
+	return tokenURL, baseURL, nil
+}
+
+// Options returns the client options for the configuration, loading its
+// private key
+func (c *Config) Options() ([]Option, error) {
+	key, err := c.privateKey()
+	if err != nil {
+		return nil, fmt.Errorf("loading private key: %w", err)
+	}
+
+	tokenURL, baseURL, err := c.endpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []Option{
+		WithConsumerCredentials(c.ConsumerKey, c.ConsumerSecret),
+		WithSAMLProvider(c.SAMLProviderID),
+		WithPrivateKey(key),
+		WithTokenURL(tokenURL),
+		WithBaseURL(baseURL),
+	}
+
+	if c.Retry != nil {
+		opts = append(opts, WithRetryPolicy(c.Retry.Policy()))
+	}
+
+	return opts, nil
+}
+
+// NewClient returns an initialized client for the customer. `opts` are applied
+// after the configuration's options.
+func (c *Config) NewClient(customerID string, opts ...Option) (*Client, error) {
+	options, err := c.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClientWithOptions(customerID, append(options, opts...)...)
+}
+
+// NewManager returns a manager for the configured application, as with
+// NewManager
+func (c *Config) NewManager() (*Manager, error) {
+	key, err := c.privateKey()
+	if err != nil {
+		return nil, fmt.Errorf("loading private key: %w", err)
+	}
+
+	m := NewManager(c.ConsumerKey, c.ConsumerSecret, c.SAMLProviderID, key)
+
+	if m.TokenURL, m.BaseURL, err = c.endpoints(); err != nil {
+		return nil, err
+	}
+
+	if c.ClientCacheSize != 0 || c.ClientCacheTTL != 0 {
+		size, ttl := c.ClientCacheSize, time.Duration(c.ClientCacheTTL)
+		if size == 0 {
+			size = DefaultClientCacheSize
+		}
+		if ttl == 0 {
+			ttl = DefaultClientCacheTTL
+		}
+
+		cache := NewLRUClientCache(size, ttl)
+		cache.OnBackgroundError = m.backgroundError
+		m.Cache = cache
+	}
+
+	if c.Retry != nil {
+		policy := c.Retry.Policy()
+		m.RetryPolicy = &policy
+	}
+
+	return m, nil
+}
@@ -0,0 +1,156 @@
+package intuit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Credential is a named credential value as submitted to the institution
+type Credential struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CredentialField is one input of a credential form
+type CredentialField struct {
+	Name         string
+	Label        string
+	Instructions string
+	Masked       bool
+	MinLength    int
+	MaxLength    int
+
+	key InstitutionKey
+}
+
+// CredentialForm is the model of the form an end user fills in to connect an
+// institution, built from the institution's keys
+type CredentialForm struct {
+	InstitutionID int64
+	Fields        []CredentialField
+}
+
+// NewCredentialForm builds the credential form for an institution. Only keys
+// displayed to the user are included, ordered by their display order.
+func NewCredentialForm(details *InstitutionDetails) CredentialForm {
+	keys := make([]InstitutionKey, 0, len(details.Keys))
+	for _, key := range details.Keys {
+		if key.DisplayToUser {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].DisplayOrder < keys[j].DisplayOrder })
+
+	form := CredentialForm{InstitutionID: details.ID, Fields: make([]CredentialField, len(keys))}
+	for i, key := range keys {
+		label := key.Description
+		if label == "" {
+			label = key.Name
+		}
+
+		form.Fields[i] = CredentialField{
+			Name:         key.Name,
+			Label:        label,
+			Instructions: key.Instructions,
+			Masked:       key.MaskValue,
+			MinLength:    key.MinLength,
+			MaxLength:    key.MaxLength,
+			key:          key,
+		}
+	}
+
+	return form
+}
+
+// CredentialFieldError describes an invalid credential value
+type CredentialFieldError struct {
+	Field   string
+	Problem string
+}
+
+func (e CredentialFieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Problem)
+}
+
+// CredentialErrors lists every invalid value of a credential form
+type CredentialErrors []CredentialFieldError
+
+func (e CredentialErrors) Error() string {
+	problems := make([]string, len(e))
+	for i, fieldErr := range e {
+		problems[i] = fieldErr.Error()
+	}
+
+	return "invalid credentials: " + strings.Join(problems, "; ")
+}
+
+// Validate checks user-supplied `values`, keyed by field name, against the
+// form. Every field is required and must respect its length limits. It returns
+// CredentialErrors listing every problem, or nil.
+func (f CredentialForm) Validate(values map[string]string) error {
+	var errs CredentialErrors
+	for _, field := range f.Fields {
+		value, ok := values[field.Name]
+		length := utf8.RuneCountInString(value)
+
+		switch {
+		case !ok || value == "":
+			errs = append(errs, CredentialFieldError{Field: field.Name, Problem: "is required"})
+		case field.MinLength > 0 && length < field.MinLength:
+			errs = append(errs, CredentialFieldError{Field: field.Name, Problem: fmt.Sprintf("must be at least %d characters", field.MinLength)})
+		case field.MaxLength > 0 && length > field.MaxLength:
+			errs = append(errs, CredentialFieldError{Field: field.Name, Problem: fmt.Sprintf("must be at most %d characters", field.MaxLength)})
+		}
+	}
+
+	var unknown []string
+	for name := range values {
+		if !f.hasField(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+
+	for _, name := range unknown {
+		errs = append(errs, CredentialFieldError{Field: name, Problem: "is not a field of the form"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Credentials validates `values` and returns them in form order, encoded as
+// the institution expects, ready to be submitted
+func (f CredentialForm) Credentials(values map[string]string) ([]Credential, error) {
+	if err := f.Validate(values); err != nil {
+		return nil, err
+	}
+
+	credentials := make([]Credential, len(f.Fields))
+	for i, field := range f.Fields {
+		value, err := field.key.EncodeValue(values[field.Name])
+		if err != nil {
+			return nil, err
+		}
+
+		credentials[i] = Credential{Name: field.Name, Value: value}
+	}
+
+	return credentials, nil
+}
+
+func (f CredentialForm) hasField(name string) bool {
+	for _, field := range f.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
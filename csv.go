@@ -0,0 +1,205 @@
+package intuit
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVColumn names a column of a CSV export
+type CSVColumn string
+
+// Constants representing CSV columns
+const (
+	CSVID                       CSVColumn = "id"
+	CSVInstitutionTransactionID CSVColumn = "institution_transaction_id"
+	CSVType                     CSVColumn = "type"
+	CSVPostedDate               CSVColumn = "posted_date"
+	CSVUserDate                 CSVColumn = "user_date"
+	CSVPayee                    CSVColumn = "payee"
+	CSVAmount                   CSVColumn = "amount"
+	CSVCurrency                 CSVColumn = "currency"
+	CSVPending                  CSVColumn = "pending"
+	CSVCategory                 CSVColumn = "category"
+)
+
+// DefaultCSVColumns are the columns of a CSV export without configured columns
+var DefaultCSVColumns = []CSVColumn{
+	CSVPostedDate,
+	CSVPayee,
+	CSVAmount,
+	CSVCurrency,
+	CSVCategory,
+	CSVPending,
+	CSVInstitutionTransactionID,
+}
+
+// CSVOptions configures WriteCSV. The zero value writes DefaultCSVColumns
+// with a header, ISO dates in UTC and amounts like "-1234.56".
+type CSVOptions struct {
+	Columns []CSVColumn
+
+	// NoHeader omits the header row of column names
+	NoHeader bool
+
+	// Comma is the field delimiter. If zero, ',' is used.
+	Comma rune
+
+	// DateFormat is the layout of dates, as with time.Format. If empty,
+	// "2006-01-02" is used.
+	DateFormat string
+
+	// Location is the zone dates are written in. If nil, UTC is used.
+	Location *time.Location
+
+	// DecimalSeparator separates the whole and fractional parts of amounts.
+	// If zero, '.' is used.
+	DecimalSeparator rune
+
+	// ThousandsSeparator, if set, groups the digits of amounts in threes
+	ThousandsSeparator rune
+}
+
+// WriteCSV writes every transaction in the list as a CSV row, ordered by
+// posted date. The type column holds the payload key of each transaction,
+// such as "bankingTransactions".
+func (l TransactionList) WriteCSV(w io.Writer, opts CSVOptions) error {
+	type row struct {
+		key string
+		txn Transaction
+	}
+
+	var rows []row
+	for key, txns := range l {
+		for _, t := range txns {
+			rows = append(rows, row{key, t})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].txn.PostedDate.Equal(rows[j].txn.PostedDate) {
+			return rows[i].txn.PostedDate.Before(rows[j].txn.PostedDate)
+		}
+		if rows[i].key != rows[j].key {
+			return rows[i].key < rows[j].key
+		}
+
+		return rows[i].txn.ID < rows[j].txn.ID
+	})
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+
+	writer := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		writer.Comma = opts.Comma
+	}
+
+	record := make([]string, len(columns))
+
+	if !opts.NoHeader {
+		for i, column := range columns {
+			record[i] = string(column)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range rows {
+		for i, column := range columns {
+			record[i] = opts.field(column, r.key, r.txn)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// field formats one column of a transaction
+func (opts CSVOptions) field(column CSVColumn, key string, t Transaction) string {
+	switch column {
+	case CSVID:
+		return strconv.FormatInt(t.ID, 10)
+	case CSVInstitutionTransactionID:
+		return t.InstitutionTransactionID
+	case CSVType:
+		return key
+	case CSVPostedDate:
+		return opts.date(t.PostedDate)
+	case CSVUserDate:
+		return opts.date(t.UserDate)
+	case CSVPayee:
+		return t.PayeeName
+	case CSVAmount:
+		return opts.amount(t.Amount)
+	case CSVCurrency:
+		return t.CurrencyType
+	case CSVPending:
+		return strconv.FormatBool(t.Pending)
+	case CSVCategory:
+		return t.Categorization.PrimaryCategory()
+	}
+
+	return ""
+}
+
+func (opts CSVOptions) date(t Timestamp) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	layout := opts.DateFormat
+	if layout == "" {
+		layout = "2006-01-02"
+	}
+
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return t.Time().In(loc).Format(layout)
+}
+
+// amount formats an amount with the configured separators
+func (opts CSVOptions) amount(m Money) string {
+	s := m.String()
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+
+	if opts.ThousandsSeparator != 0 && len(whole) > 3 {
+		var b strings.Builder
+		for i, digit := range whole {
+			if i > 0 && (len(whole)-i)%3 == 0 {
+				b.WriteRune(opts.ThousandsSeparator)
+			}
+			b.WriteRune(digit)
+		}
+		whole = b.String()
+	}
+
+	decimal := opts.DecimalSeparator
+	if decimal == 0 {
+		decimal = '.'
+	}
+
+	return sign + whole + string(decimal) + frac
+}
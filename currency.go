@@ -0,0 +1,118 @@
+package intuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoExchangeRate is returned by currency converters that can't convert
+// between two currencies
+var ErrNoExchangeRate = errors.New("no exchange rate")
+
+// ExchangeRateError is returned by currency converters that have no rate
+// between two currencies. It wraps ErrNoExchangeRate.
+type ExchangeRateError struct {
+	From, To string
+}
+
+func (e *ExchangeRateError) Error() string {
+	return fmt.Sprintf("no exchange rate from %s to %s", e.From, e.To)
+}
+
+// Unwrap returns ErrNoExchangeRate
+func (e *ExchangeRateError) Unwrap() error {
+	return ErrNoExchangeRate
+}
+
+// CurrencyConverter converts amounts between currencies, identified by their
+// ISO 4217 codes, so that amounts in several currencies can be combined into
+// one base currency. Implementations must be safe for concurrent use.
+type CurrencyConverter interface {
+	Convert(ctx context.Context, amount Money, from, to string) (Money, error)
+}
+
+// CurrencyConverterFunc adapts a function to the CurrencyConverter interface
+type CurrencyConverterFunc func(ctx context.Context, amount Money, from, to string) (Money, error)
+
+// Convert calls f
+func (f CurrencyConverterFunc) Convert(ctx context.Context, amount Money, from, to string) (Money, error) {
+	return f(ctx, amount, from, to)
+}
+
+// NopCurrencyConverter converts nothing. Amounts are returned as is if both
+// currencies are the same; otherwise an *ExchangeRateError is returned, so
+// that amounts in different currencies are never combined by accident.
+var NopCurrencyConverter CurrencyConverter = CurrencyConverterFunc(func(ctx context.Context, amount Money, from, to string) (Money, error) {
+	if sameCurrency(from, to) {
+		return amount, nil
+	}
+
+	return 0, &ExchangeRateError{From: from, To: to}
+})
+
+// currencyConverterOrNop returns the converter, or NopCurrencyConverter if it
+// is nil
+func currencyConverterOrNop(conv CurrencyConverter) CurrencyConverter {
+	if conv == nil {
+		return NopCurrencyConverter
+	}
+
+	return conv
+}
+
+// StaticRates is a CurrencyConverter using fixed exchange rates, e.g. rates
+// loaded once a day
+type StaticRates struct {
+	// Base is the currency the rates are quoted against
+	Base string
+
+	// Rates holds the value of one unit of each currency in Base, e.g.
+	// {"CAD": 0.73} for a USD base
+	Rates map[string]float64
+}
+
+// Convert converts the amount through the base currency, rounding to the
+// precision of Money
+func (r StaticRates) Convert(ctx context.Context, amount Money, from, to string) (Money, error) {
+	if sameCurrency(from, to) {
+		return amount, nil
+	}
+
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return 0, &ExchangeRateError{From: from, To: to}
+	}
+
+	toRate, ok := r.rate(to)
+	if !ok {
+		return 0, &ExchangeRateError{From: from, To: to}
+	}
+
+	return MoneyFromFloat(amount.Float64() * fromRate / toRate), nil
+}
+
+func (r StaticRates) rate(currency string) (float64, bool) {
+	if sameCurrency(currency, r.Base) {
+		return 1, true
+	}
+
+	rate, ok := r.Rates[strings.ToUpper(currency)]
+	if !ok || rate <= 0 {
+		return 0, false
+	}
+
+	return rate, true
+}
+
+// ConvertMoney converts the amount with `conv`, or with NopCurrencyConverter
+// if `conv` is nil
+func ConvertMoney(ctx context.Context, conv CurrencyConverter, amount Money, from, to string) (Money, error) {
+	return currencyConverterOrNop(conv).Convert(ctx, amount, from, to)
+}
+
+// sameCurrency compares currency codes case-insensitively
+func sameCurrency(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
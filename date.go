@@ -0,0 +1,85 @@
+package intuit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const dateFormat = "2006-01-02"
+
+// Date is a calendar date with no time of day or time zone. It is used for
+// transaction date ranges so that a date means the same day regardless of the
+// zone of the time.Time it came from.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date on which t falls, in t's location
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// ParseDate parses a date in YYYY-MM-DD format
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateFormat, s)
+	if err != nil {
+		return Date{}, err
+	}
+
+	return DateOf(t), nil
+}
+
+// String returns the date in YYYY-MM-DD format, as expected by the CAD API
+func (d Date) String() string {
+	return d.In(time.UTC).Format(dateFormat)
+}
+
+// In returns midnight at the start of the date in the given location
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero reports whether d is the zero date
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Before reports whether d is before other
+func (d Date) Before(other Date) bool {
+	return d.In(time.UTC).Before(other.In(time.UTC))
+}
+
+// After reports whether d is after other
+func (d Date) After(other Date) bool {
+	return d.In(time.UTC).After(other.In(time.UTC))
+}
+
+// AddDays returns the date `n` days after d. `n` may be negative.
+func (d Date) AddDays(n int) Date {
+	return DateOf(d.In(time.UTC).AddDate(0, 0, n))
+}
+
+// MarshalJSON implements the json Marshaler interface
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements the json Unmarshaler interface
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package intuit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Date
+		wantErr bool
+	}{
+		{in: "2024-02-29", want: Date{2024, time.February, 29}},
+		{in: "1999-12-31", want: Date{1999, time.December, 31}},
+		{in: "2023-02-29", wantErr: true},
+		{in: "2024-2-1", wantErr: true},
+		{in: "2024-02-01T00:00:00Z", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseDate(test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseDate(%q) = %s, want an error", test.in, got)
+			}
+			continue
+		}
+
+		if err != nil || got != test.want {
+			t.Errorf("ParseDate(%q) = %s, %v, want %s", test.in, got, err, test.want)
+		}
+		if got.String() != test.in {
+			t.Errorf("ParseDate(%q).String() = %s", test.in, got)
+		}
+	}
+}
+
+func TestDateOf(t *testing.T) {
+	// the same instant falls on different dates in different zones
+	instant := time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+	newYork := time.FixedZone("EST", -5*60*60)
+
+	if got, want := DateOf(instant), (Date{2024, time.March, 1}); got != want {
+		t.Errorf("DateOf(UTC) = %s, want %s", got, want)
+	}
+	if got, want := DateOf(instant.In(newYork)), (Date{2024, time.February, 29}); got != want {
+		t.Errorf("DateOf(EST) = %s, want %s", got, want)
+	}
+}
+
+func TestDateArithmetic(t *testing.T) {
+	tests := []struct {
+		d    Date
+		days int
+		want Date
+	}{
+		{Date{2024, time.February, 28}, 1, Date{2024, time.February, 29}},
+		{Date{2024, time.February, 28}, 2, Date{2024, time.March, 1}},
+		{Date{2024, time.January, 1}, -1, Date{2023, time.December, 31}},
+		{Date{2024, time.January, 1}, 366, Date{2025, time.January, 1}},
+		{Date{2024, time.January, 1}, 0, Date{2024, time.January, 1}},
+	}
+
+	for _, test := range tests {
+		got := test.d.AddDays(test.days)
+		if got != test.want {
+			t.Errorf("%s.AddDays(%d) = %s, want %s", test.d, test.days, got, test.want)
+		}
+
+		if test.days > 0 && (!test.d.Before(got) || !got.After(test.d)) {
+			t.Errorf("%s and %s are not ordered", test.d, got)
+		}
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	data, err := json.Marshal(Date{2024, time.March, 5})
+	if err != nil || string(data) != `"2024-03-05"` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+
+	for _, in := range []string{`"2024-03-05"`, `"2024-13-05"`, `20240305`} {
+		var d Date
+		err := json.Unmarshal([]byte(in), &d)
+		if in == `"2024-03-05"` {
+			if err != nil || d != (Date{2024, time.March, 5}) {
+				t.Errorf("Unmarshal(%s) = %s, %v", in, d, err)
+			}
+		} else if err == nil {
+			t.Errorf("Unmarshal(%s) = %s, want an error", in, d)
+		}
+	}
+}
+
+func TestTimestampJSON(t *testing.T) {
+	tests := []struct {
+		time time.Time
+		json string
+	}{
+		{time.Date(2014, time.April, 23, 7, 0, 0, 123e6, time.UTC), "1398236400123"},
+		// outside the range of UnixNano
+		{time.Date(2300, time.January, 1, 0, 0, 0, 0, time.UTC), "10413792000000"},
+		{time.Date(1600, time.January, 1, 0, 0, 0, 0, time.UTC), "-11676096000000"},
+	}
+
+	for _, test := range tests {
+		data, err := json.Marshal(Timestamp(test.time))
+		if err != nil || string(data) != test.json {
+			t.Errorf("Marshal(%v) = %s, %v, want %s", test.time, data, err, test.json)
+		}
+
+		var ts Timestamp
+		if err := json.Unmarshal([]byte(test.json), &ts); err != nil || !time.Time(ts).Equal(test.time) {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %v", test.json, ts, err, test.time)
+		}
+	}
+}
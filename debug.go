@@ -0,0 +1,141 @@
+package intuit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// redactedParams are form parameters replaced with "REDACTED" in debug dumps
+var redactedParams = []string{
+	"saml_assertion",
+	"oauth_consumer_key",
+	"oauth_token",
+	"oauth_token_secret",
+	"oauth_signature",
+	"oauth_nonce",
+}
+
+// WithDebugWriter dumps every failed request and its response to `w`, for
+// support cases that need exact requests, payloads and Intuit transaction IDs.
+// A request fails if it returns an error or a status of 400 or above; the
+// dump includes the decoded CAD error, if the response holds one.
+//
+// Dumps are sanitized: credential headers are replaced, and the SAML assertion
+// and OAuth parameters of form bodies are redacted. Payloads are otherwise
+// written as sent, so treat the output as confidential.
+func WithDebugWriter(w io.Writer) Option {
+	return func(c *Client) {
+		c.DebugWriter = w
+	}
+}
+
+// debugFailure writes a dump of the request to the client's debug writer if
+// the request failed. The response body is read for the dump and replaced, so
+// callers can still read it.
+func (c *Client) debugFailure(req *http.Request, resp *http.Response, err error, start time.Time, duration time.Duration) {
+	if c.DebugWriter == nil || (err == nil && resp.StatusCode < 400) {
+		return
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "--- CAD request failed at %s after %s (customer %s) ---\n",
+		start.UTC().Format(time.RFC3339Nano), duration, c.CustomerLabel())
+
+	if dump, dumpErr := dumpRequest(req); dumpErr != nil {
+		fmt.Fprintf(&b, "%s %s\n(request dump failed: %v)\n", req.Method, req.URL.Path, dumpErr)
+	} else {
+		b.Write(dump)
+	}
+	b.WriteString("\n\n")
+
+	if err != nil {
+		fmt.Fprintf(&b, "error: %v\n\n", err)
+	} else {
+		body, readErr := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+		dumped := *resp
+		dumped.Body = ioutil.NopCloser(bytes.NewReader(body))
+		dump, dumpErr := httputil.DumpResponse(&dumped, true)
+		switch {
+		case dumpErr != nil:
+			fmt.Fprintf(&b, "%s\n(response dump failed: %v)\n", resp.Status, dumpErr)
+		case readErr != nil:
+			fmt.Fprintf(&b, "%s\n(reading body failed: %v)\n", dump, readErr)
+		default:
+			b.Write(dump)
+		}
+		b.WriteString("\n\n")
+
+		if apiErr := parseAPIError(resp.StatusCode, body); apiErr.Type != "" || apiErr.Message != "" {
+			fmt.Fprintf(&b, "CAD error: %v\n\n", apiErr)
+		}
+	}
+
+	c.debugMu.Lock()
+	defer c.debugMu.Unlock()
+
+	c.DebugWriter.Write(b.Bytes())
+}
+
+// dumpRequest dumps the request with credentials redacted. The body is read
+// from req.GetBody, since the original has already been sent.
+func dumpRequest(req *http.Request) ([]byte, error) {
+	dumped := req.Clone(req.Context())
+	dumped.Body = nil
+
+	for name := range dumped.Header {
+		if redactedHeaders[name] {
+			dumped.Header.Set(name, "REDACTED")
+		}
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		data = redactForm(dumped.Header.Get("Content-Type"), data)
+		dumped.Body = ioutil.NopCloser(bytes.NewReader(data))
+		dumped.ContentLength = int64(len(data))
+	}
+
+	return httputil.DumpRequest(dumped, true)
+}
+
+// redactForm redacts the credentials of a form-encoded body
+func redactForm(contentType string, body []byte) []byte {
+	if !strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		return body
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return body
+	}
+
+	for _, name := range redactedParams {
+		if _, ok := values[name]; ok {
+			values.Set(name, "REDACTED")
+		}
+	}
+
+	return []byte(values.Encode())
+}
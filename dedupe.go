@@ -0,0 +1,210 @@
+package intuit
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Constants representing why a transaction was found to be a duplicate
+const (
+	// DuplicateSameKey means the transactions share their ID or their
+	// institution transaction ID
+	DuplicateSameKey = "same-key"
+
+	// DuplicatePendingPosted means a pending transaction matched the posted
+	// transaction it became, by amount, payee and date
+	DuplicatePendingPosted = "pending-posted"
+)
+
+// PendingMatchDays is the largest number of days between the date of a pending
+// transaction and that of the posted transaction it may have become.
+// Institutions typically post card transactions within a few days.
+const PendingMatchDays = 5
+
+// Duplicate records a transaction DedupeTransactions dropped
+type Duplicate struct {
+	// Kept is the transaction that was kept, after the dropped transaction
+	// was merged into it
+	Kept Transaction
+
+	Dropped Transaction
+	Reason  string
+}
+
+// DedupeReport lists the duplicates DedupeTransactions dropped
+type DedupeReport struct {
+	Duplicates []Duplicate
+}
+
+// DedupeTransactions drops the duplicates in the transactions of one account
+// and returns the remaining transactions, in their original order, along with
+// a report of what was dropped.
+//
+// Transactions sharing their ID or institution transaction ID are
+// duplicates; a posted transaction is kept over a pending one, and otherwise
+// the first is kept. An ID of zero, as transactions built without one have,
+// matches nothing. A pending transaction is also a duplicate of a posted
+// transaction with the same amount and currency, a matching payee, and a date
+// at most PendingMatchDays away, since institutions often report a charge
+// again under a new ID once it posts. Each posted transaction absorbs at most
+// one pending transaction this way.
+//
+// Fields the kept transaction lacks, such as its user date or
+// categorization, are filled in from the dropped one.
+func DedupeTransactions(txns []Transaction) ([]Transaction, DedupeReport) {
+	var report DedupeReport
+
+	kept := make([]Transaction, 0, len(txns))
+	byID := make(map[int64]int, len(txns))
+	byKey := make(map[string]int, len(txns))
+
+	// index records the transaction kept at i under its ID and key, if it
+	// has them
+	index := func(t Transaction, i int) {
+		if t.ID != 0 {
+			byID[t.ID] = i
+		}
+		if t.InstitutionTransactionID != "" {
+			byKey[t.InstitutionTransactionID] = i
+		}
+	}
+
+	for _, t := range txns {
+		i, ok := 0, false
+		if t.ID != 0 {
+			i, ok = byID[t.ID]
+		}
+		if !ok && t.InstitutionTransactionID != "" {
+			i, ok = byKey[t.InstitutionTransactionID]
+		}
+
+		if !ok {
+			index(t, len(kept))
+			kept = append(kept, t)
+			continue
+		}
+
+		keep, drop := kept[i], t
+		if keep.Pending && !t.Pending {
+			keep, drop = t, kept[i]
+		}
+
+		kept[i] = mergeDuplicate(keep, drop)
+		index(t, i)
+		report.Duplicates = append(report.Duplicates, Duplicate{Kept: kept[i], Dropped: drop, Reason: DuplicateSameKey})
+	}
+
+	dropped := make([]bool, len(kept))
+	absorbed := make([]bool, len(kept))
+
+	for i, pending := range kept {
+		if !pending.Pending {
+			continue
+		}
+
+		for j, posted := range kept {
+			if posted.Pending || absorbed[j] || !pendingBecame(pending, posted) {
+				continue
+			}
+
+			kept[j] = mergeDuplicate(posted, pending)
+			absorbed[j] = true
+			dropped[i] = true
+			report.Duplicates = append(report.Duplicates, Duplicate{Kept: kept[j], Dropped: pending, Reason: DuplicatePendingPosted})
+
+			break
+		}
+	}
+
+	result := kept[:0]
+	for i, t := range kept {
+		if !dropped[i] {
+			result = append(result, t)
+		}
+	}
+
+	return result, report
+}
+
+// pendingBecame reports whether the pending transaction looks like an earlier
+// report of the posted one
+func pendingBecame(pending, posted Transaction) bool {
+	if pending.Amount != posted.Amount {
+		return false
+	}
+
+	if pending.CurrencyType != "" && posted.CurrencyType != "" && pending.CurrencyType != posted.CurrencyType {
+		return false
+	}
+
+	if !samePayee(pending, posted) {
+		return false
+	}
+
+	day := pending.UserDay()
+	if pending.UserDate.IsZero() {
+		day = pending.PostedDay()
+	}
+
+	for _, other := range []Date{posted.PostedDay(), posted.UserDay()} {
+		if !other.IsZero() && daysBetween(day, other) <= PendingMatchDays {
+			return true
+		}
+	}
+
+	return false
+}
+
+// samePayee reports whether two transactions name the same payee, comparing
+// normalized payee names if both have one, and otherwise the payee names,
+// ignoring case and punctuation and allowing one to extend the other
+func samePayee(a, b Transaction) bool {
+	if a.Categorization.Common.NormalizedPayeeName != "" && b.Categorization.Common.NormalizedPayeeName != "" {
+		return payeeWords(a.Categorization.Common.NormalizedPayeeName) == payeeWords(b.Categorization.Common.NormalizedPayeeName)
+	}
+
+	x, y := payeeWords(a.PayeeName), payeeWords(b.PayeeName)
+	if x == "" || y == "" {
+		return false
+	}
+
+	return strings.Contains(x, y) || strings.Contains(y, x)
+}
+
+// payeeWords lower-cases a payee name and collapses punctuation and spacing to
+// single spaces
+func payeeWords(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(fields, " ")
+}
+
+// daysBetween returns the number of days between two dates, in either order
+func daysBetween(a, b Date) int {
+	days := int(b.In(time.UTC).Sub(a.In(time.UTC)).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+
+	return days
+}
+
+// mergeDuplicate fills in the fields `keep` lacks from `drop`
+func mergeDuplicate(keep, drop Transaction) Transaction {
+	if keep.UserDate.IsZero() {
+		keep.UserDate = drop.UserDate
+	}
+
+	if keep.Categorization.Common.NormalizedPayeeName == "" && len(keep.Categorization.Context) == 0 {
+		keep.Categorization = drop.Categorization
+	}
+
+	if keep.Details == nil {
+		keep.Details = drop.Details
+	}
+
+	return keep
+}
@@ -0,0 +1,148 @@
+package intuit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDedupeTransactions(t *testing.T) {
+	day := func(d int) Timestamp {
+		return Timestamp(time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC))
+	}
+
+	tests := []struct {
+		name        string
+		txns        []Transaction
+		wantPayees  []string // the payees of the kept transactions, in order
+		wantReasons []string
+	}{
+		{
+			name: "no duplicates",
+			txns: []Transaction{
+				{ID: 1, PayeeName: "a", Amount: 100, PostedDate: day(1)},
+				{ID: 2, PayeeName: "b", Amount: 100, PostedDate: day(1)},
+			},
+			wantPayees: []string{"a", "b"},
+		},
+		{
+			name: "same ID",
+			txns: []Transaction{
+				{ID: 1, PayeeName: "a", PostedDate: day(1)},
+				{ID: 2, PayeeName: "b", PostedDate: day(1)},
+				{ID: 1, PayeeName: "a again", PostedDate: day(1)},
+			},
+			wantPayees:  []string{"a", "b"},
+			wantReasons: []string{DuplicateSameKey},
+		},
+		{
+			name: "same institution transaction ID",
+			txns: []Transaction{
+				{ID: 1, InstitutionTransactionID: "x", PayeeName: "a"},
+				{ID: 2, InstitutionTransactionID: "x", PayeeName: "a again"},
+			},
+			wantPayees:  []string{"a"},
+			wantReasons: []string{DuplicateSameKey},
+		},
+		{
+			name: "posted kept over pending with the same ID",
+			txns: []Transaction{
+				{ID: 1, PayeeName: "pending", Pending: true},
+				{ID: 1, PayeeName: "posted"},
+			},
+			wantPayees:  []string{"posted"},
+			wantReasons: []string{DuplicateSameKey},
+		},
+		{
+			name: "zero IDs and empty keys match nothing",
+			txns: []Transaction{
+				{PayeeName: "a", Amount: 100},
+				{PayeeName: "b", Amount: 200},
+				{PayeeName: "c", Amount: 300},
+			},
+			wantPayees: []string{"a", "b", "c"},
+		},
+		{
+			name: "pending became posted",
+			txns: []Transaction{
+				{ID: 1, PayeeName: "COFFEE SHOP #12", Amount: -450, Pending: true, PostedDate: day(1)},
+				{ID: 2, PayeeName: "Coffee Shop", Amount: -450, PostedDate: day(4)},
+			},
+			wantPayees:  []string{"Coffee Shop"},
+			wantReasons: []string{DuplicatePendingPosted},
+		},
+		{
+			name: "pending too long before posted",
+			txns: []Transaction{
+				{ID: 1, PayeeName: "shop", Amount: -450, Pending: true, PostedDate: day(1)},
+				{ID: 2, PayeeName: "shop", Amount: -450, PostedDate: day(1 + PendingMatchDays + 1)},
+			},
+			wantPayees: []string{"shop", "shop"},
+		},
+		{
+			name: "pending with a different amount",
+			txns: []Transaction{
+				{ID: 1, PayeeName: "shop", Amount: -450, Pending: true, PostedDate: day(1)},
+				{ID: 2, PayeeName: "shop", Amount: -500, PostedDate: day(2)},
+			},
+			wantPayees: []string{"shop", "shop"},
+		},
+		{
+			name: "pending with a different payee",
+			txns: []Transaction{
+				{ID: 1, PayeeName: "shop", Amount: -450, Pending: true, PostedDate: day(1)},
+				{ID: 2, PayeeName: "cafe", Amount: -450, PostedDate: day(2)},
+			},
+			wantPayees: []string{"shop", "cafe"},
+		},
+		{
+			name: "each posted transaction absorbs one pending",
+			txns: []Transaction{
+				{ID: 1, PayeeName: "shop", Amount: -450, Pending: true, PostedDate: day(1)},
+				{ID: 2, PayeeName: "shop", Amount: -450, Pending: true, PostedDate: day(1)},
+				{ID: 3, PayeeName: "shop", Amount: -450, PostedDate: day(2)},
+			},
+			wantPayees:  []string{"shop", "shop"},
+			wantReasons: []string{DuplicatePendingPosted},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, report := DedupeTransactions(test.txns)
+
+			var payees []string
+			for _, txn := range got {
+				payees = append(payees, txn.PayeeName)
+			}
+			if !reflect.DeepEqual(payees, test.wantPayees) {
+				t.Errorf("DedupeTransactions() kept %q, want %q", payees, test.wantPayees)
+			}
+
+			var reasons []string
+			for _, d := range report.Duplicates {
+				reasons = append(reasons, d.Reason)
+			}
+			if !reflect.DeepEqual(reasons, test.wantReasons) {
+				t.Errorf("DedupeTransactions() reasons = %q, want %q", reasons, test.wantReasons)
+			}
+		})
+	}
+}
+
+func TestDedupeTransactionsMerges(t *testing.T) {
+	userDate := Timestamp(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	got, report := DedupeTransactions([]Transaction{
+		{ID: 1, UserDate: userDate, Pending: true},
+		{ID: 1, Details: "details"},
+	})
+
+	want := Transaction{ID: 1, UserDate: userDate, Details: "details"}
+	if len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+		t.Errorf("DedupeTransactions() = %+v, want [%+v]", got, want)
+	}
+	if len(report.Duplicates) != 1 || !report.Duplicates[0].Dropped.Pending {
+		t.Errorf("DedupeTransactions() report = %+v, want the pending transaction dropped", report)
+	}
+}
@@ -0,0 +1,117 @@
+package intuit
+
+import "sort"
+
+// AccountChanges describes the differences between two snapshots of a
+// customer's accounts. Each list is ordered by account ID.
+type AccountChanges struct {
+	Added   []Account
+	Removed []Account
+
+	// StatusChanges lists the accounts whose status or aggregation status
+	// changed
+	StatusChanges []AccountStatusChange
+
+	// BalanceChanges lists the accounts whose balance changed
+	BalanceChanges []BalanceChange
+}
+
+// AccountStatusChange is a change of an account's status or aggregation status
+type AccountStatusChange struct {
+	// Account is the account as of the new snapshot
+	Account Account
+
+	PreviousStatus     string
+	PreviousAggrStatus AggregationStatus
+}
+
+// Deactivated returns true if the account was active and no longer is
+func (c AccountStatusChange) Deactivated() bool {
+	return c.PreviousStatus == AccountStatusActive && !c.Account.IsActive()
+}
+
+// StartedFailing returns true if the account's aggregation now fails and
+// previously didn't, e.g. because the bank disconnected
+func (c AccountStatusChange) StartedFailing() bool {
+	return !c.PreviousAggrStatus.IsError() && c.Account.AggrStatusCode.IsError()
+}
+
+// Recovered returns true if the account's aggregation failed and now doesn't
+func (c AccountStatusChange) Recovered() bool {
+	return c.PreviousAggrStatus.IsError() && !c.Account.AggrStatusCode.IsError()
+}
+
+// BalanceChange is a change of an account's balance
+type BalanceChange struct {
+	// Account is the account as of the new snapshot
+	Account  Account
+	Previous Money
+}
+
+// Delta returns the change in balance
+func (c BalanceChange) Delta() Money {
+	return c.Account.Balance - c.Previous
+}
+
+// Empty returns true if nothing changed
+func (c AccountChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.StatusChanges) == 0 && len(c.BalanceChanges) == 0
+}
+
+// DiffAccounts compares two snapshots of a customer's accounts, matching
+// accounts by ID
+func DiffAccounts(old, new []Account) AccountChanges {
+	previous := make(map[int64]Account, len(old))
+	for _, account := range old {
+		previous[account.ID] = account
+	}
+
+	var changes AccountChanges
+
+	current := make(map[int64]bool, len(new))
+	for _, account := range new {
+		current[account.ID] = true
+
+		before, ok := previous[account.ID]
+		if !ok {
+			changes.Added = append(changes.Added, account)
+			continue
+		}
+
+		if account.Status != before.Status || account.AggrStatusCode != before.AggrStatusCode {
+			changes.StatusChanges = append(changes.StatusChanges, AccountStatusChange{
+				Account:            account,
+				PreviousStatus:     before.Status,
+				PreviousAggrStatus: before.AggrStatusCode,
+			})
+		}
+
+		if account.Balance != before.Balance {
+			changes.BalanceChanges = append(changes.BalanceChanges, BalanceChange{
+				Account:  account,
+				Previous: before.Balance,
+			})
+		}
+	}
+
+	for _, account := range old {
+		if !current[account.ID] {
+			changes.Removed = append(changes.Removed, account)
+		}
+	}
+
+	sortAccounts(changes.Added)
+	sortAccounts(changes.Removed)
+	sort.Slice(changes.StatusChanges, func(i, j int) bool {
+		return changes.StatusChanges[i].Account.ID < changes.StatusChanges[j].Account.ID
+	})
+	sort.Slice(changes.BalanceChanges, func(i, j int) bool {
+		return changes.BalanceChanges[i].Account.ID < changes.BalanceChanges[j].Account.ID
+	})
+
+	return changes
+}
+
+func sortAccounts(accounts []Account) {
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+}
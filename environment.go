@@ -0,0 +1,68 @@
+package intuit
+
+import "fmt"
+
+// Environment selects the set of Intuit endpoints a client talks to. To test
+// against a mock server such as intuittest's, use WithTokenURL and WithBaseURL
+// instead.
+type Environment int
+
+// Constants representing Intuit environments. Intuit serves development apps
+// and test institutions from the production hosts, so Testing currently has
+// the same endpoints as Production; selecting it documents intent and lets
+// the endpoints diverge without API changes.
+const (
+	Production Environment = iota
+	Testing
+)
+
+// TokenURL returns the SAML access token endpoint for the environment
+func (e Environment) TokenURL() string {
+	return AccessTokenEndpoint
+}
+
+// BaseURL returns the root of the CAD API for the environment
+func (e Environment) BaseURL() string {
+	return BaseURL
+}
+
+func (e Environment) String() string {
+	switch e {
+	case Production:
+		return "production"
+	case Testing:
+		return "testing"
+	}
+
+	return fmt.Sprintf("Environment(%d)", int(e))
+}
+
+// ParseEnvironment returns the environment named `name`, as returned by
+// Environment.String
+func ParseEnvironment(name string) (Environment, error) {
+	switch name {
+	case "production":
+		return Production, nil
+	case "testing":
+		return Testing, nil
+	}
+
+	return 0, fmt.Errorf("unknown environment %q", name)
+}
+
+// WithEnvironment points the client at the environment's token endpoint and
+// base URL
+func WithEnvironment(env Environment) Option {
+	return func(c *Client) {
+		c.TokenURL = env.TokenURL()
+		c.BaseURL = env.BaseURL()
+	}
+}
+
+// WithTokenURL sets the SAML access token endpoint, e.g. to point the client at
+// a mock server
+func WithTokenURL(tokenURL string) Option {
+	return func(c *Client) {
+		c.TokenURL = tokenURL
+	}
+}
@@ -0,0 +1,21 @@
+package intuit
+
+import "testing"
+
+func TestParseEnvironment(t *testing.T) {
+	for _, env := range []Environment{Production, Testing} {
+		parsed, err := ParseEnvironment(env.String())
+		if err != nil || parsed != env {
+			t.Errorf("ParseEnvironment(%q) = %v, %v, want %v", env.String(), parsed, err, env)
+		}
+
+		// the environments share the production hosts
+		if env.TokenURL() != AccessTokenEndpoint || env.BaseURL() != BaseURL {
+			t.Errorf("%v has endpoints %s and %s, want the production ones", env, env.TokenURL(), env.BaseURL())
+		}
+	}
+
+	if _, err := ParseEnvironment("sandbox"); err == nil {
+		t.Error(`ParseEnvironment("sandbox") succeeded`)
+	}
+}
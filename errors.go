@@ -0,0 +1,219 @@
+package intuit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBodySize bounds how much of an error response is read
+const maxErrorBodySize = 64 << 10
+
+// Sentinel errors describing the class of a failure. Errors returned by the
+// client wrap them where they apply, so that callers can branch with
+// errors.Is instead of matching error strings.
+var (
+	// ErrUnauthorized means the CAD API or the token endpoint rejected the
+	// client's credentials
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrNotFound means the requested resource doesn't exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrChallengeRequired means the institution requires a multi-factor
+	// authentication challenge to be answered; see AnswerChallenge
+	ErrChallengeRequired = errors.New("challenge required")
+
+	// ErrAggregationInProgress means CAD is still aggregating the accounts
+	// involved and the request should be retried later
+	ErrAggregationInProgress = errors.New("aggregation in progress")
+
+	// ErrThrottled means CAD rejected the request for exceeding its rate
+	// limits
+	ErrThrottled = errors.New("throttled")
+)
+
+// ErrInstitutionNotFound is returned when the requested institution doesn't
+// exist. It wraps ErrNotFound.
+var ErrInstitutionNotFound = fmt.Errorf("institution %w", ErrNotFound)
+
+// APIError is returned when the CAD API responds with an error status. Type,
+// Code, Message and CorrelationID come from the CAD error envelope and are
+// empty if the response had none.
+type APIError struct {
+	StatusCode    int
+	Type          string
+	Code          string
+	Message       string
+	CorrelationID string
+
+	// Err is a sentinel error describing the failure, such as ErrNotFound or
+	// ErrInstitutionNotFound, if there is one
+	Err error
+
+	// RetryAfter is the delay the response's Retry-After header asked for, or
+	// zero if it had none
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CAD API returned status code %d", e.StatusCode)
+
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+
+	var details []string
+	if e.Type != "" {
+		details = append(details, "type "+e.Type)
+	}
+	if e.Code != "" {
+		details = append(details, "code "+e.Code)
+	}
+	if e.CorrelationID != "" {
+		details = append(details, "correlation ID "+e.CorrelationID)
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(details, ", "))
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the sentinel error, if any
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// AggregationStatus returns the error code as an aggregation status, or false
+// if the code is not a known aggregation error status
+func (e *APIError) AggregationStatus() (AggregationStatus, bool) {
+	status := AggregationStatus(e.Code)
+	if _, ok := aggregationStatusMessages[status]; !ok || !status.IsError() {
+		return "", false
+	}
+
+	return status, true
+}
+
+// IsRetryable reports whether err is a transient failure, so that the same
+// request may succeed later: a network error, a response with status 408
+// (aggregation in progress), 429 or 5xx, or an aggregation status such as 105
+// (institution unavailable). Failures that need the end user, such as rejected
+// credentials (103), a required password change (109) or a wrong challenge
+// answer (187), are not retryable, nor are cancelled contexts.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if status, ok := apiErr.AggregationStatus(); ok {
+			return status.IsRetryable()
+		}
+
+		return apiErr.StatusCode == http.StatusRequestTimeout ||
+			apiErr.StatusCode == http.StatusTooManyRequests ||
+			apiErr.StatusCode >= 500
+	}
+
+	var authErr *SAMLAuthError
+	if errors.As(err, &authErr) {
+		return authErr.StatusCode == http.StatusTooManyRequests || authErr.StatusCode >= 500
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RetryAfter returns the delay CAD asked for before retrying the request that
+// failed with err, from the Retry-After header of the response, or false if it
+// asked for none
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter <= 0 {
+		return 0, false
+	}
+
+	return apiErr.RetryAfter, true
+}
+
+// errorEnvelope is the body of CAD error responses
+type errorEnvelope struct {
+	Status struct {
+		ErrorInfo []struct {
+			ErrorType     string `json:"errorType"`
+			ErrorCode     string `json:"errorCode"`
+			ErrorMessage  string `json:"errorMessage"`
+			CorrelationID string `json:"correlationId"`
+		} `json:"errorInfo"`
+	} `json:"status"`
+}
+
+// newAPIError builds an APIError from an error response, parsing the CAD
+// error envelope if the body holds one. It consumes the body but does not
+// close it.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	if body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize)); err == nil {
+		apiErr = parseAPIError(resp.StatusCode, body)
+	}
+
+	apiErr.Err = statusSentinel(resp)
+	apiErr.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	return apiErr
+}
+
+// statusSentinel returns the sentinel error describing the status of an error
+// response, or nil if there is none
+func statusSentinel(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		if isChallenge(resp) {
+			return ErrChallengeRequired
+		}
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusRequestTimeout:
+		return ErrAggregationInProgress
+	case http.StatusTooManyRequests:
+		return ErrThrottled
+	}
+
+	return nil
+}
+
+// parseAPIError builds an APIError from the status and body of an error
+// response
+func parseAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil || len(envelope.Status.ErrorInfo) == 0 {
+		return apiErr
+	}
+
+	info := envelope.Status.ErrorInfo[0]
+	apiErr.Type = info.ErrorType
+	apiErr.Code = info.ErrorCode
+	apiErr.Message = info.ErrorMessage
+	apiErr.CorrelationID = info.CorrelationID
+
+	return apiErr
+}
@@ -0,0 +1,118 @@
+package intuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain", errors.New("failed"), false},
+		{"cancelled", context.Canceled, false},
+		{"deadline", context.DeadlineExceeded, true},
+		{"unexpected EOF", fmt.Errorf("reading: %w", io.ErrUnexpectedEOF), true},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("refused")}, true},
+		{"bad request", &APIError{StatusCode: 400}, false},
+		{"not found", &APIError{StatusCode: 404, Err: ErrNotFound}, false},
+		{"aggregation in progress", &APIError{StatusCode: 408}, true},
+		{"throttled", &APIError{StatusCode: 429}, true},
+		{"server error", fmt.Errorf("call: %w", &APIError{StatusCode: 502}), true},
+		{"institution unavailable", &APIError{StatusCode: 500, Code: string(AggrStatusUnavailable)}, true},
+		{"login error", &APIError{StatusCode: 500, Code: string(AggrStatusLoginError)}, false},
+		{"password change", &APIError{StatusCode: 500, Code: string(AggrStatusPasswordChangeRequired)}, false},
+		{"wrong challenge answer", &APIError{StatusCode: 401, Code: string(AggrStatusIncorrectMFAAnswer)}, false},
+		{"SAML rejected", &SAMLAuthError{StatusCode: 401}, false},
+		{"SAML server error", &SAMLAuthError{StatusCode: 503}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsRetryable(test.err); got != test.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		header     http.Header
+		body       string
+		want       APIError
+		wantString string
+	}{
+		{
+			name:       "no body",
+			status:     500,
+			want:       APIError{StatusCode: 500},
+			wantString: "CAD API returned status code 500",
+		},
+		{
+			name:   "envelope",
+			status: 404,
+			body:   `{"status":{"errorInfo":[{"errorType":"APP_ERROR","errorCode":"100","errorMessage":"no such account","correlationId":"abc"}]}}`,
+			want: APIError{
+				StatusCode:    404,
+				Type:          "APP_ERROR",
+				Code:          "100",
+				Message:       "no such account",
+				CorrelationID: "abc",
+				Err:           ErrNotFound,
+			},
+			wantString: "CAD API returned status code 404: no such account (type APP_ERROR, code 100, correlation ID abc)",
+		},
+		{
+			name:       "not JSON",
+			status:     401,
+			body:       `<html>`,
+			want:       APIError{StatusCode: 401, Err: ErrUnauthorized},
+			wantString: "CAD API returned status code 401",
+		},
+		{
+			name:       "throttled",
+			status:     429,
+			header:     http.Header{"Retry-After": {"30"}},
+			want:       APIError{StatusCode: 429, Err: ErrThrottled, RetryAfter: 30 * time.Second},
+			wantString: "CAD API returned status code 429",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := test.header
+			if header == nil {
+				header = http.Header{}
+			}
+
+			got := newAPIError(&http.Response{
+				StatusCode: test.status,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(test.body)),
+			})
+
+			if *got != test.want {
+				t.Errorf("newAPIError() = %+v, want %+v", *got, test.want)
+			}
+			if got.Error() != test.wantString {
+				t.Errorf("Error() = %q, want %q", got.Error(), test.wantString)
+			}
+			if test.want.Err != nil && !errors.Is(got, test.want.Err) {
+				t.Errorf("errors.Is(%v, %v) = false", got, test.want.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/ofx"
+	"github.com/bodetree/intuit-cad/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// fixtureStart precedes the fixture transactions, which were posted in April
+// 2014. A service syncing live data would keep the default lookback.
+var fixtureStart = time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// app is the example service
+type app struct {
+	manager   *intuit.Manager
+	store     *intuit.MemoryStore
+	scheduler *intuit.Scheduler
+	apiKey    string
+	mux       *http.ServeMux
+}
+
+// newApp returns the service for `customerIDs`, which the scheduler refreshes
+// every `interval`, syncing each customer refreshed successfully into `store`
+func newApp(manager *intuit.Manager, store *intuit.MemoryStore, registry *prometheus.Registry, apiKey string, interval time.Duration, customerIDs ...string) *app {
+	a := &app{manager: manager, store: store, apiKey: apiKey}
+
+	a.scheduler = intuit.NewScheduler(intuit.NewRefreshCoordinator(manager), intuit.Every(interval), customerIDs...)
+	a.scheduler.Pauses = store
+	a.scheduler.OnSuccess = func(result intuit.RefreshResult) {
+		if _, err := a.sync(result.CustomerID); err != nil {
+			log.Printf("syncing %s: %v", result.CustomerID, err)
+		}
+	}
+	a.scheduler.OnFailure = func(result intuit.RefreshResult) {
+		log.Printf("refreshing %s failed: %v (pending %v, held %v)", result.CustomerID, result.Err, result.Pending, result.Held)
+	}
+
+	a.mux = http.NewServeMux()
+	a.mux.Handle("/v1/", server.New(manager, apiKey))
+	a.mux.HandleFunc("POST /logins", a.handleAddLogin)
+	a.mux.HandleFunc("POST /logins/challenge", a.handleAnswerChallenge)
+	a.mux.HandleFunc("POST /customers/{customerID}/sync", a.handleSync)
+	a.mux.HandleFunc("POST /customers/{customerID}/pause", a.handlePause)
+	a.mux.HandleFunc("POST /customers/{customerID}/resume", a.handleResume)
+	a.mux.HandleFunc("GET /customers/{customerID}/accounts/{accountID}/statement.ofx", a.handleOFX)
+	a.mux.HandleFunc("GET /customers/{customerID}/accounts/{accountID}/transactions.ndjson", a.handleNDJSON)
+	a.mux.Handle("GET /metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	return a
+}
+
+// ServeHTTP checks the API key and routes the request. Routes under /v1/ are
+// checked by the server package.
+func (a *app) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, "/v1/") && !a.authorized(r) {
+		writeError(w, http.StatusUnauthorized, errors.New("missing or invalid API key"))
+		return
+	}
+
+	a.mux.ServeHTTP(w, r)
+}
+
+func (a *app) authorized(r *http.Request) bool {
+	key := r.Header.Get(server.APIKeyHeader)
+	if auth := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+		key = strings.TrimPrefix(auth, "Bearer ")
+	}
+
+	return key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(a.apiKey)) == 1
+}
+
+// sync copies the customer's accounts and new transactions into the store
+func (a *app) sync(customerID string) (*intuit.SyncResult, error) {
+	client, err := a.manager.ClientFor(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	syncer := intuit.NewSyncer(client, a.store)
+	syncer.Lookback = time.Since(fixtureStart)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	return syncer.Sync(ctx)
+}
+
+// loginRequest is the body of POST /logins
+type loginRequest struct {
+	CustomerID    string            `json:"customerId"`
+	InstitutionID int64             `json:"institutionId"`
+	Credentials   map[string]string `json:"credentials"`
+}
+
+// challengeRequest is the body of POST /logins/challenge, echoing the
+// challenge returned by POST /logins
+type challengeRequest struct {
+	CustomerID    string   `json:"customerId"`
+	InstitutionID int64    `json:"institutionId"`
+	SessionID     string   `json:"sessionId"`
+	NodeID        string   `json:"nodeId"`
+	Answers       []string `json:"answers"`
+}
+
+// challengeResponse is returned when the institution raises a challenge
+type challengeResponse struct {
+	InstitutionID int64                      `json:"institutionId"`
+	SessionID     string                     `json:"sessionId"`
+	NodeID        string                     `json:"nodeId"`
+	Questions     []intuit.ChallengeQuestion `json:"questions"`
+}
+
+// handleAddLogin validates the end user's credentials against the
+// institution's credential form and adds the login
+func (a *app) handleAddLogin(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	client, err := a.manager.ClientFor(req.CustomerID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	details, err := client.InstitutionDetailsContext(r.Context(), req.InstitutionID)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+
+	credentials, err := intuit.NewCredentialForm(details).Credentials(req.Credentials)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	accounts, challenge, err := client.DiscoverAndAddAccountsContext(r.Context(), req.InstitutionID, credentials)
+	a.writeOutcome(w, req.CustomerID, accounts, challenge, err)
+}
+
+func (a *app) handleAnswerChallenge(w http.ResponseWriter, r *http.Request) {
+	var req challengeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	client, err := a.manager.ClientFor(req.CustomerID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	challenge := &intuit.Challenge{InstitutionID: req.InstitutionID, SessionID: req.SessionID, NodeID: req.NodeID}
+	accounts, next, err := client.AnswerChallengeContext(r.Context(), challenge, req.Answers)
+	a.writeOutcome(w, req.CustomerID, accounts, next, err)
+}
+
+// writeOutcome writes the result of adding a login or answering a challenge.
+// Once the login is added, the customer is synced so that its accounts can be
+// exported right away.
+func (a *app) writeOutcome(w http.ResponseWriter, customerID string, accounts []intuit.Account, challenge *intuit.Challenge, err error) {
+	switch {
+	case err != nil:
+		writeError(w, statusOf(err), err)
+
+	case challenge != nil:
+		writeJSON(w, http.StatusAccepted, challengeResponse{
+			InstitutionID: challenge.InstitutionID,
+			SessionID:     challenge.SessionID,
+			NodeID:        challenge.NodeID,
+			Questions:     challenge.Questions,
+		})
+
+	default:
+		if _, err := a.sync(customerID); err != nil {
+			log.Printf("syncing %s: %v", customerID, err)
+		}
+
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"accounts": accounts})
+	}
+}
+
+func (a *app) handleSync(w http.ResponseWriter, r *http.Request) {
+	result, err := a.sync(r.PathValue("customerID"))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+
+	failed := make(map[string]string, len(result.Failed))
+	for accountID, err := range result.Failed {
+		failed[strconv.FormatInt(accountID, 10)] = err.Error()
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"accounts":     result.Accounts,
+		"transactions": result.Transactions,
+		"failed":       failed,
+	})
+}
+
+func (a *app) handlePause(w http.ResponseWriter, r *http.Request) {
+	if err := a.scheduler.Pause(r.PathValue("customerID")); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *app) handleResume(w http.ResponseWriter, r *http.Request) {
+	if err := a.scheduler.Resume(r.PathValue("customerID")); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleOFX exports the stored transactions of an account as an OFX statement
+func (a *app) handleOFX(w http.ResponseWriter, r *http.Request) {
+	account, ok := a.storedAccount(w, r)
+	if !ok {
+		return
+	}
+
+	key := "bankingTransactions"
+	if account.Kind() == intuit.AccountKindCredit {
+		key = "creditCardTransactions"
+	}
+
+	statement := ofx.Statement{
+		Account:      account,
+		Transactions: intuit.TransactionList{key: a.store.Transactions(r.PathValue("customerID"), account.ID)},
+		AccountType:  strings.ToUpper(account.BankingAccountType),
+	}
+
+	w.Header().Set("Content-Type", "application/x-ofx")
+	if err := ofx.Write(w, []ofx.Statement{statement}, ofx.Options{}); err != nil {
+		log.Printf("writing statement: %v", err)
+	}
+}
+
+// handleNDJSON exports the stored transactions of an account as
+// newline-delimited JSON
+func (a *app) handleNDJSON(w http.ResponseWriter, r *http.Request) {
+	account, ok := a.storedAccount(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if err := a.store.Transactions(r.PathValue("customerID"), account.ID).WriteNDJSON(w); err != nil {
+		log.Printf("writing transactions: %v", err)
+	}
+}
+
+// storedAccount returns the request's account from the store, writing a not
+// found response if it hasn't been synced
+func (a *app) storedAccount(w http.ResponseWriter, r *http.Request) (intuit.Account, bool) {
+	accountID, err := strconv.ParseInt(r.PathValue("accountID"), 10, 64)
+	if err == nil {
+		for _, account := range a.store.Accounts(r.PathValue("customerID")) {
+			if account.ID == accountID {
+				return account, true
+			}
+		}
+	}
+
+	writeError(w, http.StatusNotFound, fmt.Errorf("account %s has not been synced", r.PathValue("accountID")))
+
+	return intuit.Account{}, false
+}
+
+// statusOf maps an error from the intuit package to a response status
+func statusOf(err error) int {
+	var fieldErrs intuit.CredentialErrors
+
+	switch {
+	case errors.Is(err, intuit.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, intuit.ErrCustomerPaused):
+		return http.StatusConflict
+	case errors.As(err, &fieldErrs):
+		return http.StatusBadRequest
+	}
+
+	return http.StatusBadGateway
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]interface{}{"error": err.Error()})
+}
@@ -0,0 +1,133 @@
+// Command server is an example service wiring the intuit package together: a
+// Manager shared by every customer, the REST routes of the server package, an
+// add-login flow with multi-factor challenges, a Scheduler refreshing
+// customers and a Syncer copying their data into a Store, OFX and NDJSON
+// exports from the store, and Prometheus metrics.
+//
+// It runs against the fake CAD API of the intuittest package, seeded with a
+// demo customer, so it needs no credentials:
+//
+//	go run ./examples/server -addr localhost:8080
+//
+// Every route requires the API key given with -api-key, as "Authorization:
+// Bearer KEY" or in the X-API-Key header:
+//
+//	GET  /v1/...                                              routes of the server package
+//	POST /logins                                              add a login, which may raise a challenge
+//	POST /logins/challenge                                    answer a challenge
+//	POST /customers/{customerID}/sync                         sync the customer into the store now
+//	POST /customers/{customerID}/pause                        stop refreshing and syncing the customer
+//	POST /customers/{customerID}/resume                       refresh and sync the customer again
+//	GET  /customers/{customerID}/accounts/{accountID}/statement.ofx
+//	GET  /customers/{customerID}/accounts/{accountID}/transactions.ndjson
+//	GET  /metrics
+//
+// To run against CAD, build the Manager from your application's credentials
+// with intuit.NewManager instead of the fake server's, and replace the
+// MemoryStore with a persistent one such as boltstore's.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/intuitprom"
+	"github.com/bodetree/intuit-cad/intuittest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// demoCustomer is the customer seeded into the fake server
+const demoCustomer = "demo"
+
+// demoInstitution is the fixture institution, where adding a login raises a
+// challenge and discovers demoAccount
+const demoInstitution int64 = 100000
+
+var demoAccount = intuit.Account{
+	ID:                     500000000001,
+	LoginID:                29000001,
+	Name:                   "Demo Savings",
+	Status:                 intuit.AccountStatusActive,
+	Currency:               "USD",
+	FinancialInstitutionID: demoInstitution,
+	BankingAccountType:     "SAVINGS",
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	apiKey := flag.String("api-key", "example-key", "API key callers must present")
+	interval := flag.Duration("interval", time.Hour, "how often customers are refreshed")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, *addr, *apiKey, *interval); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, addr, apiKey string, interval time.Duration) error {
+	cad, err := newFakeCAD()
+	if err != nil {
+		return err
+	}
+	defer cad.Close()
+
+	manager, err := cad.NewManager()
+	if err != nil {
+		return err
+	}
+
+	registry := prometheus.NewRegistry()
+	metrics := intuitprom.New("example")
+	registry.MustRegister(metrics)
+	manager.Metrics = metrics
+
+	a := newApp(manager, intuit.NewMemoryStore(), registry, apiKey, interval, demoCustomer)
+
+	go func() {
+		if err := a.scheduler.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("scheduler stopped: %v", err)
+		}
+	}()
+
+	srv := &http.Server{Addr: addr, Handler: a}
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("listening on %s for customer %q", addr, demoCustomer)
+
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// newFakeCAD starts a fake CAD API seeded with the demo customer's fixture
+// accounts and transactions, and the demo institution's login flow
+func newFakeCAD() (*intuittest.Server, error) {
+	cad := intuittest.NewServer()
+
+	if err := cad.Seed(demoCustomer); err != nil {
+		cad.Close()
+		return nil, err
+	}
+
+	cad.AddDiscovery(demoInstitution, true, demoAccount)
+
+	return cad, nil
+}
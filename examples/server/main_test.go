@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/intuitprom"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const testKey = "test-key"
+
+func newTestApp(t *testing.T) (*app, *httptest.Server) {
+	t.Helper()
+
+	cad, err := newFakeCAD()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cad.Close)
+
+	manager, err := cad.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := prometheus.NewRegistry()
+	metrics := intuitprom.New("example")
+	registry.MustRegister(metrics)
+	manager.Metrics = metrics
+
+	a := newApp(manager, intuit.NewMemoryStore(), registry, testKey, time.Hour, demoCustomer)
+
+	srv := httptest.NewServer(a)
+	t.Cleanup(srv.Close)
+
+	return a, srv
+}
+
+// call sends a request with the API key and decodes a JSON response into out,
+// if given, returning the status
+func call(t *testing.T, srv *httptest.Server, method, path string, body, out interface{}) (int, string) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, srv.URL+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testKey)
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, data, err)
+		}
+	}
+
+	return resp.StatusCode, string(data)
+}
+
+func TestAddLoginWithChallenge(t *testing.T) {
+	_, srv := newTestApp(t)
+
+	var challenge challengeResponse
+	status, body := call(t, srv, "POST", "/logins", loginRequest{
+		CustomerID:    demoCustomer,
+		InstitutionID: demoInstitution,
+		Credentials:   map[string]string{"Banking Userid": "demo", "Banking Password": "go"},
+	}, &challenge)
+	if status != http.StatusAccepted || challenge.SessionID == "" || len(challenge.Questions) != 2 {
+		t.Fatalf("POST /logins = %d %s, want a challenge of 2 questions", status, body)
+	}
+
+	var added struct {
+		Accounts []intuit.Account `json:"accounts"`
+	}
+	status, body = call(t, srv, "POST", "/logins/challenge", challengeRequest{
+		CustomerID:    demoCustomer,
+		InstitutionID: challenge.InstitutionID,
+		SessionID:     challenge.SessionID,
+		NodeID:        challenge.NodeID,
+		Answers:       []string{"Smith", "Springfield"},
+	}, &added)
+	if status != http.StatusCreated || len(added.Accounts) != 1 || added.Accounts[0].ID != demoAccount.ID {
+		t.Fatalf("POST /logins/challenge = %d %s, want the demo account", status, body)
+	}
+
+	// the new account is synced and exportable, and visible through /v1/
+	status, body = call(t, srv, "GET", "/customers/demo/accounts/500000000001/transactions.ndjson", nil, nil)
+	if status != http.StatusOK {
+		t.Errorf("exporting the new account = %d %s", status, body)
+	}
+
+	var accounts struct {
+		Accounts []intuit.Account `json:"accounts"`
+	}
+	if status, body := call(t, srv, "GET", "/v1/customers/demo/accounts", nil, &accounts); status != http.StatusOK || len(accounts.Accounts) != 8 {
+		t.Errorf("GET /v1/customers/demo/accounts = %d %s, want 8 accounts", status, body)
+	}
+}
+
+func TestSyncAndExport(t *testing.T) {
+	a, srv := newTestApp(t)
+
+	a.scheduler.RunOnce(context.Background())
+
+	status, body := call(t, srv, "GET", "/customers/demo/accounts/400107846787/statement.ofx", nil, nil)
+	if status != http.StatusOK || !strings.Contains(body, "<STMTTRN>") || !strings.Contains(body, "INTUIT-BANK-0001") {
+		t.Errorf("OFX export = %d %s, want the fixture transactions", status, body)
+	}
+
+	status, body = call(t, srv, "GET", "/customers/demo/accounts/400107846787/transactions.ndjson", nil, nil)
+	if lines := strings.Count(body, "\n"); status != http.StatusOK || lines != 3 {
+		t.Errorf("NDJSON export = %d with %d lines, want 3 transactions:\n%s", status, lines, body)
+	}
+
+	status, body = call(t, srv, "GET", "/metrics", nil, nil)
+	if status != http.StatusOK || !strings.Contains(body, "example_intuit_requests_total") {
+		t.Errorf("GET /metrics = %d, want the client's request counter:\n%s", status, body)
+	}
+}
+
+func TestPause(t *testing.T) {
+	a, srv := newTestApp(t)
+
+	if status, body := call(t, srv, "POST", "/customers/demo/pause", nil, nil); status != http.StatusNoContent {
+		t.Fatalf("pausing = %d %s", status, body)
+	}
+
+	a.scheduler.RunOnce(context.Background())
+	if accounts := a.store.Accounts(demoCustomer); len(accounts) != 0 {
+		t.Errorf("synced %d accounts while paused, want none", len(accounts))
+	}
+	if status, body := call(t, srv, "POST", "/customers/demo/sync", nil, nil); status != http.StatusConflict {
+		t.Errorf("syncing while paused = %d %s, want %d", status, body, http.StatusConflict)
+	}
+
+	if status, body := call(t, srv, "POST", "/customers/demo/resume", nil, nil); status != http.StatusNoContent {
+		t.Fatalf("resuming = %d %s", status, body)
+	}
+
+	var result struct {
+		Accounts int `json:"accounts"`
+	}
+	if status, body := call(t, srv, "POST", "/customers/demo/sync", nil, &result); status != http.StatusOK || result.Accounts != 7 {
+		t.Errorf("syncing after resuming = %d %s, want 7 accounts", status, body)
+	}
+}
+
+func TestAPIKeyRequired(t *testing.T) {
+	_, srv := newTestApp(t)
+
+	for _, path := range []string{"/metrics", "/v1/customers/demo/accounts"} {
+		resp, err := srv.Client().Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("GET %s without a key = %d, want %d", path, resp.StatusCode, http.StatusUnauthorized)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package intuit
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// Extensions collects every pluggable interface and hook of a Manager in one
+// place, so that a customized deployment can be wired as a single literal and
+// checked with Validate before use. Nil fields use the package defaults.
+//
+//	err := m.SetExtensions(intuit.Extensions{
+//		TokenStore:       redisTokens,
+//		ClientCache:      intuit.NewLRUClientCache(5000, time.Hour),
+//		InstitutionCache: intuit.NewMemoryInstitutionCache(intuit.DefaultInstitutionCacheTTL),
+//		RateLimiter:      intuit.NewTokenBucket(20, 40),
+//		Middleware:       []intuit.Middleware{logRequests},
+//	})
+type Extensions struct {
+	// TokenStore persists OAuth tokens across processes; see TokenStore
+	TokenStore TokenStore
+
+	// ClientCache caches the manager's clients; see Manager.Cache
+	ClientCache ClientCache
+
+	// InstitutionCache caches institution details; see WithInstitutionCache
+	InstitutionCache InstitutionCache
+
+	// RateLimiter paces the requests of all of the manager's clients together
+	RateLimiter RateLimiter
+
+	// KeyRing supplies rotating SAML signing keys; see WithKeyRing
+	KeyRing *KeyRing
+
+	// Clock is used for token renewal and assertion timestamps; see WithClock
+	Clock Clock
+
+	// Middleware wraps the transport of every client; see Client.Use
+	Middleware []Middleware
+
+	// Logger receives the logs of every client; see WithLogger
+	Logger *slog.Logger
+
+	// Metrics receives the measurements of the manager and every client; see
+	// WithMetrics
+	Metrics Metrics
+
+	// OnBackgroundError receives errors from background goroutines; see
+	// Manager.OnBackgroundError
+	OnBackgroundError func(error)
+}
+
+// Validate checks that the extensions are usable. It reports every problem
+// found, such as an interface holding a nil pointer, which would otherwise
+// only fail at the first request.
+func (e Extensions) Validate() error {
+	var problems []string
+
+	check := func(name string, v interface{}) {
+		if isNilPointer(v) {
+			problems = append(problems, fmt.Sprintf("%s holds a nil %T", name, v))
+		}
+	}
+
+	check("TokenStore", e.TokenStore)
+	check("ClientCache", e.ClientCache)
+	check("InstitutionCache", e.InstitutionCache)
+	check("RateLimiter", e.RateLimiter)
+	check("Clock", e.Clock)
+	check("Metrics", e.Metrics)
+
+	for i, mw := range e.Middleware {
+		if mw == nil {
+			problems = append(problems, fmt.Sprintf("Middleware[%d] is nil", i))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid extensions: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// isNilPointer reports whether v is a non-nil interface holding a nil pointer,
+// map, slice, func or channel
+func isNilPointer(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return rv.IsNil()
+	}
+
+	return false
+}
+
+// Extensions returns the manager's current extensions
+func (m *Manager) Extensions() Extensions {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return Extensions{
+		TokenStore:        m.TokenStore,
+		ClientCache:       m.Cache,
+		InstitutionCache:  m.InstitutionCache,
+		RateLimiter:       m.RateLimiter,
+		KeyRing:           m.KeyRing,
+		Clock:             m.Clock,
+		Middleware:        m.Middleware,
+		Logger:            m.Logger,
+		Metrics:           m.Metrics,
+		OnBackgroundError: m.OnBackgroundError,
+	}
+}
+
+// SetExtensions validates `e` and replaces all of the manager's extensions with
+// it. Clients already cached keep their previous extensions; call it before
+// the manager is used. An LRUClientCache without its own background error
+// handler or clock inherits the manager's.
+func (m *Manager) SetExtensions(e Extensions) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lru, ok := e.ClientCache.(*LRUClientCache); ok {
+		if lru.OnBackgroundError == nil {
+			lru.OnBackgroundError = m.backgroundError
+		}
+		if lru.Clock == nil {
+			lru.Clock = e.Clock
+		}
+	}
+
+	m.TokenStore = e.TokenStore
+	m.Cache = e.ClientCache
+	m.InstitutionCache = e.InstitutionCache
+	m.RateLimiter = e.RateLimiter
+	m.KeyRing = e.KeyRing
+	m.Clock = e.Clock
+	m.Middleware = e.Middleware
+	m.Logger = e.Logger
+	m.Metrics = e.Metrics
+	m.OnBackgroundError = e.OnBackgroundError
+
+	return nil
+}
@@ -0,0 +1,166 @@
+// Package fdx translates CAD accounts and transactions into structures shaped
+// like the Financial Data Exchange (FDX) API, for consumers that standardize on
+// FDX while the data still comes from CAD.
+//
+// Only the fields CAD can populate are mapped. CAD accounts carry no account
+// category, so AccountCategory is left empty.
+package fdx
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+// Constants representing FDX account statuses
+const (
+	AccountStatusOpen   = "OPEN"
+	AccountStatusClosed = "CLOSED"
+)
+
+// Constants representing FDX transaction statuses
+const (
+	TransactionStatusPending = "PENDING"
+	TransactionStatusPosted  = "POSTED"
+)
+
+// Constants representing FDX debit/credit indicators
+const (
+	Debit  = "DEBIT"
+	Credit = "CREDIT"
+	Memo   = "MEMO"
+)
+
+// Currency is an FDX currency descriptor
+type Currency struct {
+	CurrencyCode string `json:"currencyCode"`
+}
+
+// Account is an FDX account descriptor with its current balance
+type Account struct {
+	AccountID       string       `json:"accountId"`
+	AccountCategory string       `json:"accountCategory,omitempty"`
+	DisplayName     string       `json:"displayName,omitempty"`
+	Status          string       `json:"status"`
+	Currency        Currency     `json:"currency"`
+	BalanceAsOf     time.Time    `json:"balanceAsOf"`
+	CurrentBalance  intuit.Money `json:"currentBalance"`
+	FIID            string       `json:"fiId,omitempty"`
+}
+
+// Transaction is an FDX transaction. Amount is always positive; the direction
+// is given by DebitCreditMemo.
+type Transaction struct {
+	AccountID            string       `json:"accountId"`
+	TransactionID        string       `json:"transactionId"`
+	PostedTimestamp      time.Time    `json:"postedTimestamp"`
+	TransactionTimestamp time.Time    `json:"transactionTimestamp"`
+	Description          string       `json:"description"`
+	DebitCreditMemo      string       `json:"debitCreditMemo"`
+	Category             string       `json:"category,omitempty"`
+	Status               string       `json:"status"`
+	Amount               intuit.Money `json:"amount"`
+}
+
+// FromAccount maps a CAD account to an FDX account
+func FromAccount(a intuit.Account) Account {
+	status := AccountStatusClosed
+	if a.IsActive() {
+		status = AccountStatusOpen
+	}
+
+	return Account{
+		AccountID:      strconv.FormatInt(a.ID, 10),
+		DisplayName:    a.Name,
+		Status:         status,
+		Currency:       Currency{CurrencyCode: a.Currency},
+		BalanceAsOf:    a.BalanceDate.Time(),
+		CurrentBalance: a.Balance,
+		FIID:           strconv.FormatInt(a.FinancialInstitutionID, 10),
+	}
+}
+
+// FromAccounts maps a slice of CAD accounts to FDX accounts
+func FromAccounts(accounts []intuit.Account) []Account {
+	mapped := make([]Account, len(accounts))
+	for i, a := range accounts {
+		mapped[i] = FromAccount(a)
+	}
+
+	return mapped
+}
+
+// FromTransaction maps a CAD transaction in the given account to an FDX
+// transaction
+func FromTransaction(accountID int64, t intuit.Transaction) Transaction {
+	txn := Transaction{
+		AccountID:            strconv.FormatInt(accountID, 10),
+		TransactionID:        t.InstitutionTransactionID,
+		PostedTimestamp:      t.PostedDate.Time(),
+		TransactionTimestamp: t.UserDate.Time(),
+		Description:          t.PayeeName,
+		Status:               TransactionStatusPosted,
+		Amount:               t.Amount,
+	}
+
+	if txn.TransactionID == "" {
+		txn.TransactionID = strconv.FormatInt(t.ID, 10)
+	}
+
+	if t.Pending {
+		txn.Status = TransactionStatusPending
+	}
+
+	switch {
+	case t.Amount < 0:
+		txn.DebitCreditMemo = Debit
+		txn.Amount = -t.Amount
+	case t.Amount > 0:
+		txn.DebitCreditMemo = Credit
+	default:
+		txn.DebitCreditMemo = Memo
+	}
+
+	txn.Category = t.Categorization.PrimaryCategory()
+
+	return txn
+}
+
+// FromTransactionList maps every transaction in the list to an FDX transaction,
+// ordered by posted time
+func FromTransactionList(accountID int64, list intuit.TransactionList) []Transaction {
+	var mapped []Transaction
+	for _, t := range list.All() {
+		mapped = append(mapped, FromTransaction(accountID, t))
+	}
+
+	sort.SliceStable(mapped, func(i, j int) bool {
+		return mapped[i].PostedTimestamp.Before(mapped[j].PostedTimestamp)
+	})
+
+	return mapped
+}
+
+// CustomerAccounts fetches the customer's accounts and maps them to FDX
+// accounts
+func CustomerAccounts(c intuit.AccountsGetter) ([]Account, error) {
+	accounts, err := c.GetCustomerAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	return FromAccounts(accounts), nil
+}
+
+// AccountTransactions fetches the account's transactions between start and end
+// and maps them to FDX transactions
+func AccountTransactions(c intuit.TransactionsGetter, accountID int64, start, end intuit.Date) ([]Transaction, error) {
+	list, err := c.AccountTransactionsForDates(accountID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromTransactionList(accountID, list), nil
+}
@@ -0,0 +1,134 @@
+package intuit
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TransactionFilter reports whether a transaction should be kept
+type TransactionFilter func(Transaction) bool
+
+// FilterPending keeps pending transactions
+func FilterPending() TransactionFilter {
+	return func(t Transaction) bool {
+		return t.Pending
+	}
+}
+
+// FilterPosted keeps posted transactions
+func FilterPosted() TransactionFilter {
+	return func(t Transaction) bool {
+		return !t.Pending
+	}
+}
+
+// FilterAmountBetween keeps transactions whose amount is between min and max,
+// inclusive
+func FilterAmountBetween(min, max Money) TransactionFilter {
+	return func(t Transaction) bool {
+		return t.Amount >= min && t.Amount <= max
+	}
+}
+
+// FilterPayee keeps transactions whose payee name or normalized payee name
+// matches `pattern`
+func FilterPayee(pattern *regexp.Regexp) TransactionFilter {
+	return func(t Transaction) bool {
+		return pattern.MatchString(t.PayeeName) || pattern.MatchString(t.Categorization.Common.NormalizedPayeeName)
+	}
+}
+
+// FilterCategory keeps transactions that any categorization context assigns
+// to the category `name`, compared case-insensitively
+func FilterCategory(name string) TransactionFilter {
+	return func(t Transaction) bool {
+		for _, context := range t.Categorization.Context {
+			if strings.EqualFold(context.CategoryName, name) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// FilterNot keeps the transactions that f drops
+func FilterNot(f TransactionFilter) TransactionFilter {
+	return func(t Transaction) bool {
+		return !f(t)
+	}
+}
+
+// FilterAny keeps transactions kept by any of the filters
+func FilterAny(filters ...TransactionFilter) TransactionFilter {
+	return func(t Transaction) bool {
+		for _, f := range filters {
+			if f(t) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// FilterAll keeps transactions kept by all of the filters
+func FilterAll(filters ...TransactionFilter) TransactionFilter {
+	return func(t Transaction) bool {
+		for _, f := range filters {
+			if !f(t) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Transactions is a slice of transactions with query helpers
+type Transactions []Transaction
+
+// Where returns the transactions kept by all of the filters
+func (t Transactions) Where(filters ...TransactionFilter) Transactions {
+	keep := FilterAll(filters...)
+
+	var kept Transactions
+	for _, txn := range t {
+		if keep(txn) {
+			kept = append(kept, txn)
+		}
+	}
+
+	return kept
+}
+
+// Filter returns a copy of the list holding only the transactions kept by f.
+// Keys left without transactions are dropped.
+func (l TransactionList) Filter(f TransactionFilter) TransactionList {
+	filtered := make(TransactionList)
+	for key, txns := range l {
+		if kept := Transactions(txns).Where(f); len(kept) > 0 {
+			filtered[key] = kept
+		}
+	}
+
+	return filtered
+}
+
+// All returns the transactions of every key in the list, ordered by key, so
+// that the order is the same every time
+func (l TransactionList) All() Transactions {
+	keys := make([]string, 0, len(l))
+	for key := range l {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var all Transactions
+	for _, key := range keys {
+		all = append(all, l[key]...)
+	}
+
+	return all
+}
@@ -1,8 +1,25 @@
 package intuit
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Constants representing institution key value encodings
+const (
+	KeyEncodingNone   = ""
+	KeyEncodingBase64 = "BASE64"
+)
+
+// Constants representing institution key value case normalization
+const (
+	KeyCaseAsIs  = ""
+	KeyCaseUpper = "UPPER"
+	KeyCaseLower = "LOWER"
 )
 
 type institutionKeys []InstitutionKey
@@ -22,6 +39,10 @@ func (l *institutionKeys) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (l institutionKeys) MarshalJSON() ([]byte, error) {
+	return json.Marshal(_institutionKeys{Key: l})
+}
+
 type InstitutionKey struct {
 	Name          string `json:"name"`
 	Value         string `json:"val"`
@@ -33,6 +54,36 @@ type InstitutionKey struct {
 	MaskValue     bool   `json:"mask"`
 	Instructions  string `json:"instructions"`
 	Description   string `json:"description"`
+
+	// Hints describing how the institution expects the value to be submitted.
+	// Most institutions leave these empty.
+	ValueEncoding string `json:"valueEncoding"`
+	ValueCase     string `json:"valueCase"`
+}
+
+// EncodeValue applies the key's case normalization and encoding hints to a
+// user-supplied credential value, returning the value that should be submitted
+// to the institution.
+func (k InstitutionKey) EncodeValue(value string) (string, error) {
+	switch strings.ToUpper(k.ValueCase) {
+	case KeyCaseAsIs:
+	case KeyCaseUpper:
+		value = strings.ToUpper(value)
+	case KeyCaseLower:
+		value = strings.ToLower(value)
+	default:
+		return "", fmt.Errorf("key %s: unsupported value case %q", k.Name, k.ValueCase)
+	}
+
+	switch strings.ToUpper(k.ValueEncoding) {
+	case KeyEncodingNone:
+	case KeyEncodingBase64:
+		value = base64.StdEncoding.EncodeToString([]byte(value))
+	default:
+		return "", fmt.Errorf("key %s: unsupported value encoding %q", k.Name, k.ValueEncoding)
+	}
+
+	return value, nil
 }
 
 type InstitutionDetails struct {
@@ -58,19 +109,102 @@ type InstitutionDetails struct {
 	Keys institutionKeys `json:"keys"`
 }
 
-func (c *Client) InstitutionDetails(institutionID int64) (*InstitutionDetails, error) {
+type institutionList struct {
+	Institutions []InstitutionDetails `json:"institution"`
+}
+
+// Institutions returns the catalog of institutions CAD supports, e.g. to build
+// an InstitutionIndex. The catalog is large and changes rarely, so it should be
+// fetched once and kept. Catalog entries don't include the institutions' keys;
+// use InstitutionDetails for those.
+func (c *Client) Institutions(opts ...RequestOption) ([]InstitutionDetails, error) {
+	return c.InstitutionsContext(context.Background(), opts...)
+}
+
+// InstitutionsContext is like Institutions, but sends the request with ctx
+func (c *Client) InstitutionsContext(ctx context.Context, opts ...RequestOption) (institutions []InstitutionDetails, err error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	ctx, span := c.startSpan(ctx, "Institutions", SpanKindInternal)
+	defer func() { endSpan(span, err) }()
+
+	req, err := c.request("GET", "/institutions", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	payload := institutionList{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	return payload.Institutions, nil
+}
+
+// InstitutionDetails returns the details of an institution. If it doesn't
+// exist, the error is an *APIError wrapping ErrInstitutionNotFound.
+func (c *Client) InstitutionDetails(institutionID int64, opts ...RequestOption) (*InstitutionDetails, error) {
+	return c.InstitutionDetailsContext(context.Background(), institutionID, opts...)
+}
+
+// InstitutionDetailsContext is like InstitutionDetails, but sends the request
+// with ctx. If the client has an InstitutionCache, cached details are returned
+// without a request, and concurrent lookups of the same institution share one
+// request.
+func (c *Client) InstitutionDetailsContext(ctx context.Context, institutionID int64, opts ...RequestOption) (details *InstitutionDetails, err error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	ctx, span := c.startSpan(ctx, "InstitutionDetails", SpanKindInternal,
+		SpanAttribute{"intuit.institution_id", institutionID})
+	defer func() { endSpan(span, err) }()
+
+	if c.InstitutionCache == nil {
+		return c.fetchInstitutionDetails(ctx, institutionID)
+	}
+
+	return c.cachedInstitutionDetails(institutionID, func() (*InstitutionDetails, error) {
+		return c.fetchInstitutionDetails(ctx, institutionID)
+	})
+}
+
+func (c *Client) fetchInstitutionDetails(ctx context.Context, institutionID int64) (*InstitutionDetails, error) {
 	req, err := c.request("GET", fmt.Sprintf("/institutions/%d", institutionID), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.do(req)
+	resp, err := c.do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		apiErr := newAPIError(resp)
+		if resp.StatusCode == http.StatusNotFound {
+			apiErr.Err = ErrInstitutionNotFound
+		}
+
+		return nil, apiErr
+	}
+
 	decoder := json.NewDecoder(resp.Body)
 	decoder.UseNumber()
 
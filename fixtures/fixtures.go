@@ -0,0 +1,65 @@
+// Package fixtures provides sample CAD API payloads for testing decoders and
+// code that extends the intuit models.
+//
+// The payloads are modeled on the shapes returned by CAD (accounts of each
+// type, transactions of each type, institution details, challenges and error
+// bodies). All identifiers, names and amounts are synthetic. The set is pinned
+// by Version, which changes whenever a payload is added or altered.
+package fixtures
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Version identifies the fixture set
+const Version = "1"
+
+// Names of the available fixtures
+const (
+	Accounts               = "accounts"
+	BankingTransactions    = "transactions-banking"
+	CreditCardTransactions = "transactions-credit"
+	InvestmentTransactions = "transactions-investment"
+	Institution            = "institution"
+	Challenge              = "challenge"
+	ErrorNotFound          = "error-not-found"
+	ErrorAggregation       = "error-aggregation"
+)
+
+//go:embed data/*.json
+var data embed.FS
+
+// Load returns the payload of the named fixture
+func Load(name string) ([]byte, error) {
+	return data.ReadFile(path.Join("data", name+".json"))
+}
+
+// MustLoad is like Load but panics if the fixture does not exist
+func MustLoad(name string) []byte {
+	payload, err := Load(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return payload
+}
+
+// Names returns the names of every available fixture, sorted
+func Names() []string {
+	entries, err := data.ReadDir("data")
+	if err != nil {
+		panic(err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+
+	sort.Strings(names)
+
+	return names
+}
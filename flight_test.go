@@ -0,0 +1,62 @@
+package intuit
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFlightGroup(t *testing.T) {
+	var g flightGroup
+	var calls int32
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			results[i], _ = g.do("key", func() (interface{}, error) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					close(started)
+				}
+				<-release
+				return "value", nil
+			})
+		}(i)
+	}
+
+	// a call for another key doesn't wait for the one in flight
+	<-started
+	if v, err := g.do("other", func() (interface{}, error) { return "other", nil }); v != "other" || err != nil {
+		t.Errorf("do(other) = %v, %v", v, err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	// callers arriving after the first call finished make calls of their own,
+	// so only a lower bound on sharing is certain
+	if n := atomic.LoadInt32(&calls); n < 1 || int(n) > len(results) {
+		t.Errorf("fn called %d times", n)
+	}
+	for i, v := range results {
+		if v != "value" {
+			t.Errorf("result %d = %v, want value", i, v)
+		}
+	}
+
+	// errors are shared, and the key is free again afterwards
+	want := errors.New("failed")
+	if _, err := g.do("key", func() (interface{}, error) { return nil, want }); err != want {
+		t.Errorf("do() error = %v, want %v", err, want)
+	}
+	if v, err := g.do("key", func() (interface{}, error) { return 2, nil }); v != 2 || err != nil {
+		t.Errorf("do() after an error = %v, %v, want 2", v, err)
+	}
+}
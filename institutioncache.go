@@ -0,0 +1,117 @@
+package intuit
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultInstitutionCacheTTL is how long NewMemoryInstitutionCache keeps
+// institution details by default. Institution metadata rarely changes.
+const DefaultInstitutionCacheTTL = time.Hour * 24
+
+// InstitutionCache stores institution details keyed by institution ID.
+// Implementations must be safe for concurrent use.
+type InstitutionCache interface {
+	Get(institutionID int64) (*InstitutionDetails, bool)
+	Put(institutionID int64, details *InstitutionDetails)
+}
+
+// MemoryInstitutionCache is an in-memory InstitutionCache whose entries expire
+// after a fixed TTL
+type MemoryInstitutionCache struct {
+	ttl time.Duration
+
+	// Clock times expiry. If nil, SystemClock is used.
+	Clock Clock
+
+	mu      sync.Mutex
+	entries map[int64]institutionCacheEntry
+}
+
+type institutionCacheEntry struct {
+	details *InstitutionDetails
+	expires time.Time
+}
+
+// NewMemoryInstitutionCache returns a cache keeping details for `ttl`. A ttl
+// <= 0 means details never expire.
+func NewMemoryInstitutionCache(ttl time.Duration) *MemoryInstitutionCache {
+	return &MemoryInstitutionCache{
+		ttl:     ttl,
+		entries: map[int64]institutionCacheEntry{},
+	}
+}
+
+// Get returns the cached details of the institution, if they exist and have
+// not expired
+func (m *MemoryInstitutionCache) Get(institutionID int64) (*InstitutionDetails, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[institutionID]
+	if !ok {
+		return nil, false
+	}
+
+	if m.ttl > 0 && !clockOrSystem(m.Clock).Now().Before(entry.expires) {
+		delete(m.entries, institutionID)
+		return nil, false
+	}
+
+	return entry.details, true
+}
+
+// Put caches the details of the institution
+func (m *MemoryInstitutionCache) Put(institutionID int64, details *InstitutionDetails) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[institutionID] = institutionCacheEntry{
+		details: details,
+		expires: clockOrSystem(m.Clock).Now().Add(m.ttl),
+	}
+}
+
+// WithInstitutionCache caches the results of InstitutionDetails in `cache`.
+// The cache may be shared between clients.
+func WithInstitutionCache(cache InstitutionCache) Option {
+	return func(c *Client) {
+		c.InstitutionCache = cache
+	}
+}
+
+// cachedInstitutionDetails returns the institution's details from the client's
+// institution cache, or else fetches them with `fetch`, sharing one request
+// between concurrent callers. Callers receive their own copy of the details.
+func (c *Client) cachedInstitutionDetails(institutionID int64, fetch func() (*InstitutionDetails, error)) (*InstitutionDetails, error) {
+	details, ok := c.InstitutionCache.Get(institutionID)
+	metricsOrNop(c.Metrics).ObserveCache(CacheInstitutions, ok)
+	if ok {
+		return details.copy(), nil
+	}
+
+	value, err := c.institutionFlights.do(strconv.FormatInt(institutionID, 10), func() (interface{}, error) {
+		details, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+
+		c.InstitutionCache.Put(institutionID, details)
+
+		return details, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*InstitutionDetails).copy(), nil
+}
+
+// copy returns a copy of the details that shares nothing mutable with d
+func (d *InstitutionDetails) copy() *InstitutionDetails {
+	dup := *d
+	dup.Keys = append(institutionKeys(nil), d.Keys...)
+
+	return &dup
+}
@@ -0,0 +1,247 @@
+package intuit
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// InstitutionIndex holds a catalog of institutions locally and searches it by
+// name, for bank pickers that need to search as the user types. Names are
+// matched case-insensitively, ignoring punctuation and spacing, by word prefix
+// and with a small number of typos in longer words.
+//
+// An InstitutionIndex is not modified after it is built and is safe for
+// concurrent use.
+type InstitutionIndex struct {
+	entries []indexedInstitution
+	byID    map[int64]int
+}
+
+type indexedInstitution struct {
+	details    InstitutionDetails
+	normalized string
+	tokens     []string
+}
+
+// NewInstitutionIndex builds an index over `institutions`, e.g. the catalog
+// returned by the institutions endpoint
+func NewInstitutionIndex(institutions []InstitutionDetails) *InstitutionIndex {
+	index := &InstitutionIndex{
+		entries: make([]indexedInstitution, len(institutions)),
+		byID:    make(map[int64]int, len(institutions)),
+	}
+
+	for i, details := range institutions {
+		index.byID[details.ID] = i
+
+		tokens := normalizeTokens(details.Name)
+		index.entries[i] = indexedInstitution{
+			details:    details,
+			normalized: strings.Join(tokens, " "),
+			tokens:     tokens,
+		}
+	}
+
+	return index
+}
+
+// Len returns the number of institutions in the index
+func (x *InstitutionIndex) Len() int {
+	return len(x.entries)
+}
+
+// Lookup returns the institution with the given ID
+func (x *InstitutionIndex) Lookup(institutionID int64) (InstitutionDetails, bool) {
+	i, ok := x.byID[institutionID]
+	if !ok {
+		return InstitutionDetails{}, false
+	}
+
+	return x.entries[i].details, true
+}
+
+// Search returns the institutions whose names match `query`, best matches
+// first, skipping the first `offset` matches and returning at most `limit` (all
+// if limit <= 0). It also returns the total number of matches, for paging.
+// Every word of the query must match a word of the name exactly, as a prefix,
+// or with a typo or two in longer words.
+func (x *InstitutionIndex) Search(query string, offset, limit int) ([]InstitutionDetails, int) {
+	queryTokens := normalizeTokens(query)
+	if len(queryTokens) == 0 {
+		return nil, 0
+	}
+	normalizedQuery := strings.Join(queryTokens, " ")
+
+	type match struct {
+		entry *indexedInstitution
+		score int
+	}
+
+	var matches []match
+	for i := range x.entries {
+		entry := &x.entries[i]
+
+		score, ok := matchTokens(queryTokens, entry.tokens)
+		if !ok {
+			continue
+		}
+
+		if strings.HasPrefix(entry.normalized, normalizedQuery) {
+			score--
+		}
+
+		matches = append(matches, match{entry: entry, score: score})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score < b.score
+		}
+		if len(a.entry.normalized) != len(b.entry.normalized) {
+			return len(a.entry.normalized) < len(b.entry.normalized)
+		}
+		return a.entry.normalized < b.entry.normalized
+	})
+
+	total := len(matches)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= total {
+		return nil, total
+	}
+
+	matches = matches[offset:]
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	results := make([]InstitutionDetails, len(matches))
+	for i, m := range matches {
+		results[i] = m.entry.details
+	}
+
+	return results, total
+}
+
+// matchTokens scores how well every query token matches some name token. Lower
+// scores are better.
+func matchTokens(query, name []string) (int, bool) {
+	total := 0
+	for _, q := range query {
+		best := -1
+		for _, n := range name {
+			score, ok := matchToken(q, n)
+			if ok && (best < 0 || score < best) {
+				best = score
+			}
+			if best == 0 {
+				break
+			}
+		}
+
+		if best < 0 {
+			return 0, false
+		}
+		total += best
+	}
+
+	return total, true
+}
+
+// matchToken scores one query token against one name token: 0 for an exact
+// match, 1 for a prefix, and 2 plus the edit distance for a fuzzy match
+func matchToken(q, n string) (int, bool) {
+	switch {
+	case q == n:
+		return 0, true
+	case strings.HasPrefix(n, q):
+		return 1, true
+	}
+
+	allowed := 0
+	switch {
+	case len(q) >= 8:
+		allowed = 2
+	case len(q) >= 4:
+		allowed = 1
+	}
+	if allowed == 0 {
+		return 0, false
+	}
+
+	// compare against the name token truncated to the query length too, so
+	// that a typo in a partially typed word still matches
+	candidates := []string{n}
+	if len(n) > len(q) {
+		candidates = append(candidates, n[:len(q)])
+	}
+
+	for _, candidate := range candidates {
+		if d := editDistance(q, candidate, allowed); d <= allowed {
+			return 2 + d, true
+		}
+	}
+
+	return 0, false
+}
+
+// editDistance returns the Levenshtein distance between a and b, or a value
+// greater than max once it is known to exceed max
+func editDistance(a, b string, max int) int {
+	if d := len(a) - len(b); d > max || -d > max {
+		return max + 1
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+
+		if rowMin > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
+
+// normalizeTokens lowercases `s` and splits it into words of letters and
+// digits. Apostrophes are dropped so that "People's" matches "peoples".
+func normalizeTokens(s string) []string {
+	s = strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(s))
+
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -2,14 +2,12 @@ package intuit
 
 import (
 	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"sync"
+	"time"
 )
 
 // Intuit CAD API constants
@@ -18,8 +16,27 @@ const (
 	BaseURL             = "https://financialdatafeed.platform.intuit.com/v1"
 )
 
+// OAuth tokens acquired through the SAML exchange expire after about an hour.
+// Clients refresh their token once it is within TokenRefreshMargin of expiry.
+const (
+	TokenLifetime      = time.Hour
+	TokenRefreshMargin = time.Minute * 5
+)
+
+// clientsMu guards clientCache. defaultClients deduplicates NewClient, so that
+// concurrent calls for the same customer share one client.
 var clientsMu sync.Mutex
-var clients = map[string]*Client{}
+var clientCache ClientCache = NewLRUClientCache(DefaultClientCacheSize, DefaultClientCacheTTL)
+var defaultClients flightGroup
+
+// SetClientCache replaces the cache used by NewClient. Passing nil disables
+// caching, so every call to NewClient initializes a new client.
+func SetClientCache(cache ClientCache) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	clientCache = cache
+}
 
 // Default values for clients
 var (
@@ -37,23 +54,41 @@ func SetDefaultCredentials(consumerKey, consumerSecret, samlProviderID string) {
 	DefaultSAMLProviderID = samlProviderID
 }
 
-// SetDefaultPrivateKeyFromPEM decodes a PEM-encoded RSA key from `pemData` and
-// stores it in DefaultPrivateKey. Panics if any part of the process fails
+// PanicOnKeyError makes SetDefaultPrivateKeyFromPEM panic when the key can't be
+// read or decoded, as it used to, instead of returning the error.
+//
+// Deprecated: check the error returned by SetDefaultPrivateKeyFromPEM or
+// SetDefaultPrivateKeyFromPEMSafe instead.
+var PanicOnKeyError = false
+
+// SetDefaultPrivateKeyFromPEM is like SetDefaultPrivateKeyFromPEMSafe, but
+// panics on failure if PanicOnKeyError is set.
+//
+// Deprecated: use SetDefaultPrivateKeyFromPEMSafe.
 func SetDefaultPrivateKeyFromPEM(pemData io.Reader) error {
-	pemBytes, err := ioutil.ReadAll(pemData)
-	if err != nil {
+	err := SetDefaultPrivateKeyFromPEMSafe(pemData)
+	if err != nil && PanicOnKeyError {
 		panic(err)
 	}
 
-	block, _ := pem.Decode(pemBytes)
-	if block == nil {
-		panic(errors.New("unable to read PEM data"))
+	return err
+}
+
+// SetDefaultPrivateKeyFromPEMSafe decodes a PEM-encoded RSA key (PKCS#1 or
+// PKCS#8) from `pemData` and stores it in DefaultPrivateKey. On failure,
+// DefaultPrivateKey is left unchanged.
+func SetDefaultPrivateKeyFromPEMSafe(pemData io.Reader) error {
+	pemBytes, err := ioutil.ReadAll(pemData)
+	if err != nil {
+		return fmt.Errorf("reading private key: %w", err)
 	}
 
-	DefaultPrivateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	key, err := ParsePrivateKeyPEM(pemBytes)
 	if err != nil {
-		panic(fmt.Sprintf("bad private key: %v", err))
+		return err
 	}
 
+	DefaultPrivateKey = key
+
 	return nil
 }
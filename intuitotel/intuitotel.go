@@ -0,0 +1,88 @@
+// Package intuitotel records the spans of intuit clients with OpenTelemetry.
+//
+//	client.Tracer = intuitotel.New(tracerProvider)
+package intuitotel
+
+import (
+	"context"
+	"fmt"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name of the client's spans
+const tracerName = "github.com/bodetree/intuit-cad"
+
+// Tracer implements intuit.Tracer with an OpenTelemetry tracer
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+var _ intuit.Tracer = (*Tracer)(nil)
+
+// New returns a tracer recording spans with `tp`, or with the global tracer
+// provider if tp is nil
+func New(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return &Tracer{tracer: tp.Tracer(tracerName)}
+}
+
+// Start implements intuit.Tracer
+func (t *Tracer) Start(ctx context.Context, name string, kind intuit.SpanKind, attrs ...intuit.SpanAttribute) (context.Context, intuit.Span) {
+	spanKind := trace.SpanKindInternal
+	if kind == intuit.SpanKindClient {
+		spanKind = trace.SpanKindClient
+	}
+
+	ctx, s := t.tracer.Start(ctx, name, trace.WithSpanKind(spanKind), trace.WithAttributes(attributes(attrs)...))
+
+	return ctx, span{s}
+}
+
+// span implements intuit.Span
+type span struct {
+	span trace.Span
+}
+
+func (s span) SetAttributes(attrs ...intuit.SpanAttribute) {
+	s.span.SetAttributes(attributes(attrs)...)
+}
+
+func (s span) Fail(description string) {
+	s.span.SetStatus(codes.Error, description)
+}
+
+func (s span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+
+	s.span.End()
+}
+
+// attributes converts span attributes to OpenTelemetry attributes
+func attributes(attrs []intuit.SpanAttribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, len(attrs))
+	for i, attr := range attrs {
+		switch value := attr.Value.(type) {
+		case string:
+			kvs[i] = attribute.String(attr.Key, value)
+		case int:
+			kvs[i] = attribute.Int(attr.Key, value)
+		case int64:
+			kvs[i] = attribute.Int64(attr.Key, value)
+		default:
+			kvs[i] = attribute.String(attr.Key, fmt.Sprint(value))
+		}
+	}
+
+	return kvs
+}
@@ -0,0 +1,169 @@
+// Package intuitprom reports the measurements of intuit clients to Prometheus.
+//
+//	metrics := intuitprom.New("myapp")
+//	prometheus.MustRegister(metrics)
+//	manager.Metrics = metrics
+//
+// Refreshes by an intuit.Scheduler and syncs by an intuit.Syncer are counted
+// by cohort, as set with manager.Cohort or intuit.WithCohort.
+package intuitprom
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics implements intuit.Metrics with Prometheus counters and histograms.
+// It is a prometheus.Collector; register it before use.
+type Metrics struct {
+	requests       *prometheus.CounterVec
+	latency        *prometheus.HistogramVec
+	tokenRefreshes *prometheus.CounterVec
+	cacheLookups   *prometheus.CounterVec
+	refreshes      *prometheus.CounterVec
+	syncs          *prometheus.CounterVec
+	syncFailures   *prometheus.CounterVec
+}
+
+var _ intuit.CohortMetrics = (*Metrics)(nil)
+
+// New returns metrics named with the given namespace, e.g.
+// "myapp_intuit_requests_total"
+func New(namespace string) *Metrics {
+	return &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "intuit",
+			Name:      "requests_total",
+			Help:      "HTTP requests sent to the CAD API, including token exchanges and retries.",
+		}, []string{"method", "endpoint", "status", "error_class"}),
+
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "intuit",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests sent to the CAD API.",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"method", "endpoint"}),
+
+		tokenRefreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "intuit",
+			Name:      "token_refreshes_total",
+			Help:      "SAML token exchanges, by result.",
+		}, []string{"result"}),
+
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "intuit",
+			Name:      "cache_lookups_total",
+			Help:      "Cache lookups, by cache and result.",
+		}, []string{"cache", "result"}),
+
+		refreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "intuit",
+			Name:      "refreshes_total",
+			Help:      "Customer refreshes by a scheduler, by cohort and result.",
+		}, []string{"cohort", "result"}),
+
+		syncs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "intuit",
+			Name:      "syncs_total",
+			Help:      "Customer syncs, by cohort and result: success, partial if some accounts failed, paused, cancelled or error.",
+		}, []string{"cohort", "result"}),
+
+		syncFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "intuit",
+			Name:      "sync_account_failures_total",
+			Help:      "Accounts that failed to sync, by cohort.",
+		}, []string{"cohort"}),
+	}
+}
+
+// ObserveRequest implements intuit.Metrics
+func (m *Metrics) ObserveRequest(method, endpoint string, status int, errorClass string, duration time.Duration) {
+	m.requests.WithLabelValues(method, endpoint, strconv.Itoa(status), errorClass).Inc()
+	m.latency.WithLabelValues(method, endpoint).Observe(duration.Seconds())
+}
+
+// ObserveTokenRefresh implements intuit.Metrics
+func (m *Metrics) ObserveTokenRefresh(err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	m.tokenRefreshes.WithLabelValues(result).Inc()
+}
+
+// ObserveCache implements intuit.Metrics
+func (m *Metrics) ObserveCache(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+
+	m.cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+// ObserveRefresh implements intuit.CohortMetrics
+func (m *Metrics) ObserveRefresh(cohort string, success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+
+	m.refreshes.WithLabelValues(cohort, result).Inc()
+}
+
+// ObserveSync implements intuit.CohortMetrics
+func (m *Metrics) ObserveSync(cohort string, result *intuit.SyncResult, err error) {
+	var cancelled *intuit.CancelledError
+
+	outcome := "success"
+	switch {
+	case errors.Is(err, intuit.ErrCustomerPaused):
+		outcome = "paused"
+	case errors.As(err, &cancelled):
+		outcome = "cancelled"
+	case err != nil:
+		outcome = "error"
+	case len(result.Failed) > 0:
+		outcome = "partial"
+	}
+
+	m.syncs.WithLabelValues(cohort, outcome).Inc()
+
+	if result != nil && len(result.Failed) > 0 {
+		m.syncFailures.WithLabelValues(cohort).Add(float64(len(result.Failed)))
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.latency.Describe(ch)
+	m.tokenRefreshes.Describe(ch)
+	m.cacheLookups.Describe(ch)
+	m.refreshes.Describe(ch)
+	m.syncs.Describe(ch)
+	m.syncFailures.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.latency.Collect(ch)
+	m.tokenRefreshes.Collect(ch)
+	m.cacheLookups.Collect(ch)
+	m.refreshes.Collect(ch)
+	m.syncs.Collect(ch)
+	m.syncFailures.Collect(ch)
+}
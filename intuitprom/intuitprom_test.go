@@ -0,0 +1,108 @@
+package intuitprom_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/intuitprom"
+	"github.com/bodetree/intuit-cad/intuittest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// counter returns the value of the named counter with the given labels, or 0
+// if it hasn't been incremented
+func counter(t *testing.T, registry *prometheus.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if labels[pair.GetName()] != pair.GetValue() {
+					continue metrics
+				}
+			}
+
+			return metric.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func TestCohortMetrics(t *testing.T) {
+	srv := intuittest.NewServer()
+	defer srv.Close()
+
+	for _, customerID := range []string{"gold-1", "trial-1"} {
+		if err := srv.Seed(customerID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := srv.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := prometheus.NewRegistry()
+	metrics := intuitprom.New("test")
+	registry.MustRegister(metrics)
+
+	m.Metrics = metrics
+	m.Cohort = func(customerID string) string {
+		if customerID == "gold-1" {
+			return "gold"
+		}
+		return "trial"
+	}
+
+	// the fixture's MFA account fails the refresh of every customer under
+	// the default policy, so an empty policy is used
+	rc := intuit.NewRefreshCoordinator(m)
+	rc.Policy = &intuit.ReaggregationPolicy{}
+	intuit.NewScheduler(rc, intuit.Every(time.Hour), "gold-1", "trial-1").RunOnce(context.Background())
+
+	for _, cohort := range []string{"gold", "trial"} {
+		labels := map[string]string{"cohort": cohort, "result": "success"}
+		if got := counter(t, registry, "test_intuit_refreshes_total", labels); got != 1 {
+			t.Errorf("refreshes %v = %v, want 1", labels, got)
+		}
+	}
+
+	client, err := m.ClientFor("gold-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := intuit.NewMemoryStore()
+	syncer := intuit.NewSyncer(client, store)
+
+	if _, err := syncer.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetPaused("gold-1", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := syncer.Sync(context.Background()); err == nil {
+		t.Fatal("Sync() of a paused customer succeeded")
+	}
+
+	for _, result := range []string{"success", "paused"} {
+		labels := map[string]string{"cohort": "gold", "result": result}
+		if got := counter(t, registry, "test_intuit_syncs_total", labels); got != 1 {
+			t.Errorf("syncs %v = %v, want 1", labels, got)
+		}
+	}
+}
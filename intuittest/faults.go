@@ -0,0 +1,155 @@
+package intuittest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+// Faults injects failures into requests, so that applications can verify their
+// retry and backoff handling around a client. Each rate is a probability from 0
+// to 1. Latency is added independently; at most one of the other faults is
+// injected per request, so their rates should sum to at most 1.
+type Faults struct {
+	// Latency is added before the request is sent, with probability LatencyRate
+	Latency     time.Duration
+	LatencyRate float64
+
+	// ServerErrorRate is the probability of a 503 response
+	ServerErrorRate float64
+
+	// RateLimitRate is the probability of a 429 response, which carries a
+	// Retry-After header when RetryAfter is set
+	RateLimitRate float64
+	RetryAfter    time.Duration
+
+	// ResetRate is the probability of the connection being reset
+	ResetRate float64
+
+	// MalformedRate is the probability of the request being sent but its
+	// response body being truncated, so that it is no longer valid JSON
+	MalformedRate float64
+
+	// Seed seeds the random source, for reproducible runs. Zero uses the time.
+	Seed int64
+
+	once sync.Once
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// Middleware returns the fault injector as client middleware; see
+// intuit.WithMiddleware
+func (f *Faults) Middleware() intuit.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return intuit.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return f.roundTrip(next, req)
+		})
+	}
+}
+
+func (f *Faults) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if f.Latency > 0 && f.draw() < f.LatencyRate {
+		timer := time.NewTimer(f.Latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	p := f.draw()
+
+	if p -= f.ServerErrorRate; p < 0 {
+		return faultResponse(req, http.StatusServiceUnavailable, nil), nil
+	}
+
+	if p -= f.RateLimitRate; p < 0 {
+		header := http.Header{}
+		if f.RetryAfter > 0 {
+			header.Set("Retry-After", strconv.Itoa(int((f.RetryAfter+time.Second-1)/time.Second)))
+		}
+
+		return faultResponse(req, http.StatusTooManyRequests, header), nil
+	}
+
+	if p -= f.ResetRate; p < 0 {
+		return nil, &net.OpError{
+			Op:  "read",
+			Net: "tcp",
+			Err: os.NewSyscallError("read", syscall.ECONNRESET),
+		}
+	}
+
+	if p -= f.MalformedRate; p < 0 {
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		body = body[:len(body)/2]
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+		resp.Header.Del("Content-Length")
+
+		return resp, nil
+	}
+
+	return next.RoundTrip(req)
+}
+
+// draw returns a random number in [0, 1)
+func (f *Faults) draw() float64 {
+	f.once.Do(func() {
+		seed := f.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+
+		f.rand = rand.New(rand.NewSource(seed))
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.rand.Float64()
+}
+
+// faultResponse returns an injected error response in the CAD error envelope
+func faultResponse(req *http.Request, status int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+
+	body := `{"status":{"errorInfo":[{"errorType":"SYSTEM_ERROR","errorCode":"` +
+		strconv.Itoa(status) + `","errorMessage":"injected fault"}]}}`
+
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
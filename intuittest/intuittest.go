@@ -0,0 +1,515 @@
+// Package intuittest provides an in-memory fake of the CAD API for tests.
+//
+// The fake implements the SAML token exchange and the accounts, logins, login
+// refresh, transactions and institutions endpoints over data seeded by the test,
+// and adding logins, with challenges, as set up with AddDiscovery. Each
+// customer sees only their own accounts, identified by the customer ID in the
+// SAML assertion, as with CAD. Signatures are not checked.
+//
+//	srv := intuittest.NewServer()
+//	defer srv.Close()
+//
+//	if err := srv.Seed("customer-1"); err != nil {
+//		t.Fatal(err)
+//	}
+//
+//	client, err := srv.NewClient("customer-1")
+package intuittest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/fixtures"
+)
+
+// TokenPath is the path of the fake SAML token endpoint
+const TokenPath = "/oauth/v1/get_access_token_by_saml"
+
+var tokenPattern = regexp.MustCompile(`oauth_token="([^"]*)"`)
+
+// Server is a fake CAD API server. Its methods are safe for concurrent use,
+// including while requests are being served.
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	customers    map[string]*customer
+	institutions map[int64]intuit.InstitutionDetails
+	tokens       map[string]string
+	discoveries  map[int64]discovery
+	challenges   map[string]pendingChallenge
+
+	keyOnce sync.Once
+	key     *rsa.PrivateKey
+	keyErr  error
+}
+
+type customer struct {
+	accounts     []intuit.Account
+	transactions map[int64]map[string][]intuit.Transaction
+}
+
+// NewServer starts a fake server with no data
+func NewServer() *Server {
+	s := &Server{
+		customers:    map[string]*customer{},
+		institutions: map[int64]intuit.InstitutionDetails{},
+		tokens:       map[string]string{},
+		discoveries:  map[int64]discovery{},
+		challenges:   map[string]pendingChallenge{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(TokenPath, s.handleToken)
+	mux.HandleFunc("/accounts", s.authorized(s.handleAccounts))
+	mux.HandleFunc("/accounts/", s.authorized(s.handleTransactions))
+	mux.HandleFunc("/logins/", s.authorized(s.handleLoginAccounts))
+	mux.HandleFunc("/institutions", s.authorized(s.handleInstitutions))
+	mux.HandleFunc("/institutions/", s.authorized(s.handleInstitution))
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// NewClient returns a client for the customer that talks to the server. It
+// signs assertions with a throwaway key; `opts` are applied after the
+// server's settings.
+func (s *Server) NewClient(customerID string, opts ...intuit.Option) (*intuit.Client, error) {
+	if err := s.generateKey(); err != nil {
+		return nil, err
+	}
+
+	options := []intuit.Option{
+		intuit.WithConsumerCredentials("intuittest-key", "intuittest-secret"),
+		intuit.WithSAMLProvider("intuittest"),
+		intuit.WithPrivateKey(s.key),
+		intuit.WithHTTPClient(s.Client()),
+		intuit.WithTokenURL(s.URL + TokenPath),
+		intuit.WithBaseURL(s.URL),
+	}
+
+	return intuit.NewClientWithOptions(customerID, append(options, opts...)...)
+}
+
+// NewManager returns a manager whose clients talk to the server, signing
+// assertions with the same throwaway key as NewClient
+func (s *Server) NewManager() (*intuit.Manager, error) {
+	if err := s.generateKey(); err != nil {
+		return nil, err
+	}
+
+	m := intuit.NewManager("intuittest-key", "intuittest-secret", "intuittest", s.key)
+	m.HTTPClient = s.Client()
+	m.TokenURL = s.URL + TokenPath
+	m.BaseURL = s.URL
+
+	return m, nil
+}
+
+// generateKey generates the throwaway signing key on first use
+func (s *Server) generateKey() error {
+	s.keyOnce.Do(func() {
+		s.key, s.keyErr = rsa.GenerateKey(rand.Reader, 2048)
+	})
+
+	return s.keyErr
+}
+
+// AddAccounts adds accounts to the customer, replacing any with the same ID,
+// e.g. to simulate a balance or aggregation status change
+func (s *Server) AddAccounts(customerID string, accounts ...intuit.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.customer(customerID).addAccounts(accounts)
+}
+
+// addAccounts adds accounts as AddAccounts does. Callers must hold s.mu.
+func (c *customer) addAccounts(accounts []intuit.Account) {
+next:
+	for _, account := range accounts {
+		for i := range c.accounts {
+			if c.accounts[i].ID == account.ID {
+				c.accounts[i] = account
+				continue next
+			}
+		}
+
+		c.accounts = append(c.accounts, account)
+	}
+}
+
+// AddTransactions adds transactions to one of the customer's accounts under a
+// payload key such as "bankingTransactions"
+func (s *Server) AddTransactions(customerID string, accountID int64, key string, txns ...intuit.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.customer(customerID)
+	if c.transactions[accountID] == nil {
+		c.transactions[accountID] = map[string][]intuit.Transaction{}
+	}
+	c.transactions[accountID][key] = append(c.transactions[accountID][key], txns...)
+}
+
+// RevokeTokens invalidates every OAuth token issued to the customer, as CAD
+// does when a token expires early, so that the customer's next request is
+// rejected with status 401
+func (s *Server) RevokeTokens(customerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for token, owner := range s.tokens {
+		if owner == customerID {
+			delete(s.tokens, token)
+		}
+	}
+}
+
+// AddInstitutions adds institutions, visible to every customer
+func (s *Server) AddInstitutions(institutions ...intuit.InstitutionDetails) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, details := range institutions {
+		s.institutions[details.ID] = details
+	}
+}
+
+// Seed gives the customer the accounts from the fixtures package, the fixture
+// transactions of each account type, and adds the fixture institution
+func (s *Server) Seed(customerID string) error {
+	payload := fixtures.MustLoad(fixtures.Accounts)
+
+	var accounts struct {
+		Accounts []intuit.Account `json:"accounts"`
+	}
+	if err := json.Unmarshal(payload, &accounts); err != nil {
+		return fmt.Errorf("decoding %s fixture: %w", fixtures.Accounts, err)
+	}
+
+	// the type of each account is given by which of these fields it has
+	var raw struct {
+		Accounts []map[string]json.RawMessage `json:"accounts"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return fmt.Errorf("decoding %s fixture: %w", fixtures.Accounts, err)
+	}
+
+	accountFor := func(typeField string) (int64, bool) {
+		for i, fields := range raw.Accounts {
+			if _, ok := fields[typeField]; ok {
+				return accounts.Accounts[i].ID, true
+			}
+		}
+		return 0, false
+	}
+
+	s.AddAccounts(customerID, accounts.Accounts...)
+
+	typeFields := map[string]string{
+		"bankingTransactions":    "bankingAccountType",
+		"creditCardTransactions": "creditAccountType",
+		"investmentTransactions": "investmentAccountType",
+		"loanTransactions":       "loanType",
+	}
+
+	for _, name := range []string{fixtures.BankingTransactions, fixtures.CreditCardTransactions, fixtures.InvestmentTransactions} {
+		list := make(intuit.TransactionList)
+		if err := json.Unmarshal(fixtures.MustLoad(name), &list); err != nil {
+			return fmt.Errorf("decoding %s fixture: %w", name, err)
+		}
+
+		for key, txns := range list {
+			if accountID, ok := accountFor(typeFields[key]); ok {
+				s.AddTransactions(customerID, accountID, key, txns...)
+			}
+		}
+	}
+
+	var institution intuit.InstitutionDetails
+	if err := json.Unmarshal(fixtures.MustLoad(fixtures.Institution), &institution); err != nil {
+		return fmt.Errorf("decoding %s fixture: %w", fixtures.Institution, err)
+	}
+	s.AddInstitutions(institution)
+
+	return nil
+}
+
+// customer returns the customer's data, creating it if needed. Callers must
+// hold s.mu.
+func (s *Server) customer(customerID string) *customer {
+	c, ok := s.customers[customerID]
+	if !ok {
+		c = &customer{transactions: map[int64]map[string][]intuit.Transaction{}}
+		s.customers[customerID] = c
+	}
+
+	return c
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "API_ERROR", "method not allowed")
+		return
+	}
+
+	encoded := r.PostFormValue("saml_assertion")
+	samlXML, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", url.QueryEscape(`OAuth oauth_problem="assertion_invalid", oauth_problem_advice="invalid assertion encoding"`))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var assertion struct {
+		NameID string `xml:"Subject>NameID"`
+	}
+	if err := xml.Unmarshal(samlXML, &assertion); err != nil || assertion.NameID == "" {
+		w.Header().Set("WWW-Authenticate", url.QueryEscape(`OAuth oauth_problem="assertion_invalid", oauth_problem_advice="invalid assertion"`))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	token, secret := randomHex(), randomHex()
+
+	s.mu.Lock()
+	s.tokens[token] = assertion.NameID
+	s.mu.Unlock()
+
+	values := url.Values{}
+	values.Set("oauth_token", token)
+	values.Set("oauth_token_secret", secret)
+
+	w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+	fmt.Fprint(w, values.Encode())
+}
+
+// authorized resolves the customer from the request's OAuth token
+func (s *Server) authorized(handler func(w http.ResponseWriter, r *http.Request, c *customer)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		match := tokenPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+		if match == nil {
+			writeError(w, http.StatusUnauthorized, "AUTH_ERROR", "missing OAuth token")
+			return
+		}
+
+		token, _ := url.QueryUnescape(match[1])
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		customerID, ok := s.tokens[token]
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "AUTH_ERROR", "invalid OAuth token")
+			return
+		}
+
+		handler(w, r, s.customer(customerID))
+	}
+}
+
+func (s *Server) handleAccounts(w http.ResponseWriter, r *http.Request, c *customer) {
+	writeJSON(w, map[string]interface{}{"accounts": nonNil(c.accounts)})
+}
+
+func (s *Server) handleLoginAccounts(w http.ResponseWriter, r *http.Request, c *customer) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) == 2 && r.Method == "PUT" {
+		s.handleRefresh(w, r, c, parts[1])
+		return
+	}
+
+	if len(parts) != 3 || parts[2] != "accounts" {
+		writeError(w, http.StatusNotFound, "APP_ERROR", "not found")
+		return
+	}
+
+	loginID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "APP_ERROR", "invalid login ID")
+		return
+	}
+
+	var accounts []intuit.Account
+	for _, account := range c.accounts {
+		if account.LoginID == loginID {
+			accounts = append(accounts, account)
+		}
+	}
+
+	if len(accounts) == 0 {
+		writeError(w, http.StatusNotFound, "APP_ERROR", "Login not found.")
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{"accounts": accounts})
+}
+
+// handleRefresh aggregates the login's accounts, which always succeeds
+// immediately
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request, c *customer, login string) {
+	loginID, err := strconv.ParseInt(login, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "APP_ERROR", "invalid login ID")
+		return
+	}
+
+	now := intuit.Timestamp(time.Now())
+
+	var accounts []intuit.Account
+	for i := range c.accounts {
+		if c.accounts[i].LoginID == loginID {
+			c.accounts[i].AggrAttemptDate = now
+			c.accounts[i].AggrSuccessDate = now
+			c.accounts[i].AggrStatusCode = intuit.AggrStatusOK
+			accounts = append(accounts, c.accounts[i])
+		}
+	}
+
+	if len(accounts) == 0 {
+		writeError(w, http.StatusNotFound, "APP_ERROR", "Login not found.")
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{"accounts": accounts})
+}
+
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request, c *customer) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || parts[2] != "transactions" {
+		writeError(w, http.StatusNotFound, "APP_ERROR", "not found")
+		return
+	}
+
+	accountID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "APP_ERROR", "invalid account ID")
+		return
+	}
+
+	found := false
+	for _, account := range c.accounts {
+		found = found || account.ID == accountID
+	}
+	if !found {
+		writeError(w, http.StatusNotFound, "APP_ERROR", "Account not found.")
+		return
+	}
+
+	start, err := intuit.ParseDate(r.URL.Query().Get("txnStartDate"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "APP_ERROR", "invalid txnStartDate")
+		return
+	}
+
+	var end intuit.Date
+	if value := r.URL.Query().Get("txnEndDate"); value != "" {
+		if end, err = intuit.ParseDate(value); err != nil {
+			writeError(w, http.StatusBadRequest, "APP_ERROR", "invalid txnEndDate")
+			return
+		}
+	}
+
+	payload := map[string][]intuit.Transaction{}
+	for key, txns := range c.transactions[accountID] {
+		for _, t := range txns {
+			day := t.PostedDay()
+			if day.Before(start) || (!end.IsZero() && day.After(end)) {
+				continue
+			}
+			payload[key] = append(payload[key], t)
+		}
+	}
+
+	writeJSON(w, payload)
+}
+
+func (s *Server) handleInstitutions(w http.ResponseWriter, r *http.Request, c *customer) {
+	institutions := make([]intuit.InstitutionDetails, 0, len(s.institutions))
+	for _, details := range s.institutions {
+		details.Keys = nil
+		institutions = append(institutions, details)
+	}
+
+	sort.Slice(institutions, func(i, j int) bool { return institutions[i].ID < institutions[j].ID })
+
+	writeJSON(w, map[string]interface{}{"institution": institutions})
+}
+
+func (s *Server) handleInstitution(w http.ResponseWriter, r *http.Request, c *customer) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/institutions/"), "/")
+
+	institutionID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || len(parts) > 2 || len(parts) == 2 && (parts[1] != "logins" || r.Method != "POST") {
+		writeError(w, http.StatusNotFound, "APP_ERROR", "not found")
+		return
+	}
+
+	if len(parts) == 2 {
+		s.handleAddLogin(w, r, c, institutionID)
+		return
+	}
+
+	details, ok := s.institutions[institutionID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "APP_ERROR", "Institution not found.")
+		return
+	}
+
+	writeJSON(w, details)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a response in the CAD error envelope
+func writeError(w http.ResponseWriter, status int, errorType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": map[string]interface{}{
+			"errorInfo": []map[string]string{{
+				"errorType":     errorType,
+				"errorCode":     strconv.Itoa(status),
+				"errorMessage":  message,
+				"correlationId": "intuittest-" + randomHex()[:8],
+			}},
+		},
+	})
+}
+
+func nonNil(accounts []intuit.Account) []intuit.Account {
+	if accounts == nil {
+		return []intuit.Account{}
+	}
+
+	return accounts
+}
+
+func randomHex() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
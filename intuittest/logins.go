@@ -0,0 +1,86 @@
+package intuittest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/fixtures"
+)
+
+// discovery is what adding a login at an institution discovers
+type discovery struct {
+	challenge bool
+	accounts  []intuit.Account
+}
+
+// pendingChallenge is a challenge raised for a customer and not yet answered
+type pendingChallenge struct {
+	customer      *customer
+	institutionID int64
+}
+
+// AddDiscovery makes adding a login at the institution, with any credentials,
+// give the customer adding it `accounts`. If `challenge` is true, the
+// institution first raises the challenge fixture, which any answers pass.
+func (s *Server) AddDiscovery(institutionID int64, challenge bool, accounts ...intuit.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.discoveries[institutionID] = discovery{challenge: challenge, accounts: accounts}
+}
+
+// handleAddLogin discovers the accounts of a new login, or raises or checks
+// the institution's challenge. Callers must hold s.mu.
+func (s *Server) handleAddLogin(w http.ResponseWriter, r *http.Request, c *customer, institutionID int64) {
+	d, ok := s.discoveries[institutionID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "APP_ERROR", "Institution not found.")
+		return
+	}
+
+	var payload struct {
+		Credentials *struct {
+			Credential []intuit.Credential `json:"credential"`
+		} `json:"credentials"`
+		ChallengeResponses *struct {
+			Response []string `json:"response"`
+		} `json:"challengeResponses"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "APP_ERROR", "invalid request body")
+		return
+	}
+
+	switch {
+	case payload.ChallengeResponses != nil:
+		sessionID := r.Header.Get(intuit.ChallengeSessionIDHeader)
+		pending, ok := s.challenges[sessionID]
+		if !ok || pending.customer != c || pending.institutionID != institutionID {
+			writeError(w, http.StatusBadRequest, "APP_ERROR", "invalid challenge session")
+			return
+		}
+		delete(s.challenges, sessionID)
+
+	case payload.Credentials == nil || len(payload.Credentials.Credential) == 0:
+		writeError(w, http.StatusBadRequest, "APP_ERROR", "missing credentials")
+		return
+
+	case d.challenge:
+		sessionID := randomHex()
+		s.challenges[sessionID] = pendingChallenge{customer: c, institutionID: institutionID}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(intuit.ChallengeSessionIDHeader, sessionID)
+		w.Header().Set(intuit.ChallengeNodeIDHeader, randomHex()[:8])
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write(fixtures.MustLoad(fixtures.Challenge))
+		return
+	}
+
+	c.addAccounts(d.accounts)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{"accounts": nonNil(d.accounts)})
+}
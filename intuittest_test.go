@@ -0,0 +1,41 @@
+package intuit_test
+
+import (
+	"testing"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/intuittest"
+)
+
+const (
+	testCustomer = "customer-1"
+
+	// testBankingAccount is the fixture checking account, whose transactions
+	// were posted on 2014-04-23 and 2014-04-24
+	testBankingAccount int64 = 400107846787
+)
+
+var (
+	testStart = intuit.Date{Year: 2014, Month: 4, Day: 1}
+	testEnd   = intuit.Date{Year: 2014, Month: 4, Day: 30}
+)
+
+// newTestServer starts a fake server seeded with the fixtures for
+// testCustomer and returns it with a client for the customer
+func newTestServer(t *testing.T, opts ...intuit.Option) (*intuittest.Server, *intuit.Client) {
+	t.Helper()
+
+	srv := intuittest.NewServer()
+	t.Cleanup(srv.Close)
+
+	if err := srv.Seed(testCustomer); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := srv.NewClient(testCustomer, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return srv, client
+}
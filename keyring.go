@@ -0,0 +1,68 @@
+package intuit
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"sync"
+	"time"
+)
+
+// SigningKey is a key used to sign SAML assertions, along with the time from
+// which it should be used
+type SigningKey struct {
+	Signer     crypto.Signer
+	Cert       *x509.Certificate
+	ActiveFrom time.Time
+}
+
+// KeyRing holds the current and previous SAML signing keys, so that the signing
+// certificate can be rotated without a hard cutover: register the new
+// certificate with Intuit, add its key with Rotate and an activation time after
+// the registration takes effect, and the previous key keeps being used until
+// then. KeyRing is safe for concurrent use.
+type KeyRing struct {
+	mu       sync.RWMutex
+	current  SigningKey
+	previous *SigningKey
+}
+
+// NewKeyRing returns a key ring whose only key is `key`
+func NewKeyRing(key SigningKey) *KeyRing {
+	return &KeyRing{current: key}
+}
+
+// Rotate makes `next` the current key and keeps the existing current key as the
+// previous one. The previous key is used until next.ActiveFrom.
+func (k *KeyRing) Rotate(next SigningKey) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	previous := k.current
+	k.previous = &previous
+	k.current = next
+}
+
+// Select returns the key that should sign assertions at `now`: the current key
+// once it is active, otherwise the previous key
+func (k *KeyRing) Select(now time.Time) (SigningKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if !now.Before(k.current.ActiveFrom) {
+		return k.current, nil
+	}
+
+	if k.previous != nil && !now.Before(k.previous.ActiveFrom) {
+		return *k.previous, nil
+	}
+
+	return SigningKey{}, errors.New("no signing key is active yet")
+}
+
+// WithKeyRing signs the client's assertions with keys selected from `ring`
+func WithKeyRing(ring *KeyRing) Option {
+	return func(c *Client) {
+		c.KeyRing = ring
+	}
+}
@@ -0,0 +1,96 @@
+package intuit
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// DecodePrivateKeyPEM decodes an RSA private key from the first PEM block in
+// `pemBytes`. Both PKCS#1 ("RSA PRIVATE KEY") and PKCS#8 ("PRIVATE KEY") blocks
+// are supported. Encrypted blocks are decrypted with `passphrase`: encrypted
+// PKCS#8 ("ENCRYPTED PRIVATE KEY", as written by OpenSSL 3 by default) using
+// PBES2 with PBKDF2 and AES or 3DES, and the legacy PEM encryption (a
+// "Proc-Type: 4,ENCRYPTED" header, as written by `openssl rsa -des3`).
+func DecodePrivateKeyPEM(pemBytes, passphrase []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("unable to read PEM data")
+	}
+
+	der := block.Bytes
+
+	if x509.IsEncryptedPEMBlock(block) {
+		if len(passphrase) == 0 {
+			return nil, errors.New("private key is encrypted but no passphrase was given")
+		}
+
+		var err error
+		der, err = x509.DecryptPEMBlock(block, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt private key: %w", err)
+		}
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("bad private key: %w", err)
+		}
+
+		return key, nil
+	case "PRIVATE KEY":
+		return parsePKCS8RSAKey(der)
+	case "ENCRYPTED PRIVATE KEY":
+		if len(passphrase) == 0 {
+			return nil, errors.New("private key is encrypted but no passphrase was given")
+		}
+
+		der, err := decryptPKCS8(der, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt private key: %w", err)
+		}
+
+		return parsePKCS8RSAKey(der)
+	}
+
+	return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+}
+
+// parsePKCS8RSAKey parses an unencrypted PKCS#8 private key, which must be an
+// RSA key
+func parsePKCS8RSAKey(der []byte) (*rsa.PrivateKey, error) {
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("bad private key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is %T, not an RSA key", parsed)
+	}
+
+	return key, nil
+}
+
+// ParsePrivateKeyPEM decodes an unencrypted RSA private key from the first PEM
+// block in `pemBytes`, as with DecodePrivateKeyPEM
+func ParsePrivateKeyPEM(pemBytes []byte) (*rsa.PrivateKey, error) {
+	return DecodePrivateKeyPEM(pemBytes, nil)
+}
+
+// LoadPrivateKeyFromFile reads and decodes a PEM-encoded RSA private key from
+// the file at `path`, as with DecodePrivateKeyPEM. `passphrase` may be empty for
+// unencrypted keys.
+func LoadPrivateKeyFromFile(path, passphrase string) (*rsa.PrivateKey, error) {
+	pemBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodePrivateKeyPEM(pemBytes, []byte(passphrase))
+}
@@ -0,0 +1,106 @@
+package intuit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// WithLogger logs the client's requests, retry decisions and token exchanges
+// to `logger`. Requests are logged at debug level and failures at warn level.
+//
+// Only the method, path, status, duration and Intuit transaction ID of each
+// request are logged, never headers, query strings or bodies, so OAuth
+// parameters and SAML assertions are not written. The customer is identified
+// by CustomerLabel. Accounts and transactions logged as attributes omit names,
+// payees, balances and amounts; see Account.LogValue.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.Logger = logger
+	}
+}
+
+// log logs a message with the customer's label if the client has a logger
+func (c *Client) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if c.Logger == nil || !c.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs = append([]slog.Attr{slog.String("customer", c.CustomerLabel())}, attrs...)
+	c.Logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// logResponse logs the outcome of a request
+func (c *Client) logResponse(req *http.Request, msg string, resp *http.Response, err error, duration time.Duration) {
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Duration("duration", duration),
+	}
+
+	level := slog.LevelDebug
+	switch {
+	case err != nil:
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", err.Error()))
+	case resp.StatusCode >= 400:
+		level = slog.LevelWarn
+		fallthrough
+	default:
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+		if tid := resp.Header.Get("intuit_tid"); tid != "" {
+			attrs = append(attrs, slog.String("intuit_tid", tid))
+		}
+	}
+
+	c.log(req.Context(), level, msg, attrs...)
+}
+
+// logRetry logs the decision to retry a request after `attempt` failed
+func (c *Client) logRetry(req *http.Request, attempt int, delay time.Duration, resp *http.Response, err error) {
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Int("attempt", attempt),
+		slog.Duration("delay", delay),
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	} else {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+
+	c.log(req.Context(), slog.LevelInfo, "retrying CAD request", attrs...)
+}
+
+// LogValue implements slog.LogValuer. The account's name and balance are
+// omitted.
+func (a Account) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int64("id", a.ID),
+		slog.Int64("loginId", a.LoginID),
+		slog.Int64("institutionId", a.FinancialInstitutionID),
+		slog.String("status", a.Status),
+		slog.String("aggrStatusCode", string(a.AggrStatusCode)),
+	)
+}
+
+// LogValue implements slog.LogValuer. The transaction's payee and amount are
+// omitted.
+func (t Transaction) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int64("id", t.ID),
+		slog.Time("postedDate", t.PostedDate.Time()),
+		slog.Bool("pending", t.Pending),
+	)
+}
+
+// LogValue implements slog.LogValuer. The balance is omitted.
+func (b AccountBalance) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int64("id", b.ID),
+		slog.Time("balanceDate", b.BalanceDate.Time()),
+	)
+}
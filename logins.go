@@ -0,0 +1,167 @@
+package intuit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Headers carrying the state of a multi-factor authentication challenge
+const (
+	ChallengeSessionIDHeader = "challengeSessionId"
+	ChallengeNodeIDHeader    = "challengeNodeId"
+)
+
+// Challenge is a multi-factor authentication challenge the institution raised
+// while adding a login. Answer it with AnswerChallenge.
+type Challenge struct {
+	InstitutionID int64
+	SessionID     string
+	NodeID        string
+	Questions     []ChallengeQuestion
+}
+
+// ChallengeQuestion is one question of a challenge. Choices is empty for
+// free-text questions.
+type ChallengeQuestion struct {
+	Text    string
+	Choices []string
+}
+
+type credentialsPayload struct {
+	Credentials struct {
+		Credential []Credential `json:"credential"`
+	} `json:"credentials"`
+}
+
+type challengeResponsePayload struct {
+	ChallengeResponses struct {
+		Response []string `json:"response"`
+	} `json:"challengeResponses"`
+}
+
+type challengePayload struct {
+	Challenge []struct {
+		TextOrImageAndChoice []string `json:"textOrImageAndChoice"`
+	} `json:"challenge"`
+}
+
+// DiscoverAndAddAccounts adds a login at the institution with the end user's
+// credentials, e.g. from CredentialForm.Credentials, and returns the accounts
+// discovered. If the institution requires a multi-factor authentication
+// challenge to be answered first, no accounts are returned and the challenge
+// is returned instead.
+func (c *Client) DiscoverAndAddAccounts(institutionID int64, credentials []Credential, opts ...RequestOption) ([]Account, *Challenge, error) {
+	return c.DiscoverAndAddAccountsContext(context.Background(), institutionID, credentials, opts...)
+}
+
+// DiscoverAndAddAccountsContext is like DiscoverAndAddAccounts, but sends the
+// request with ctx
+func (c *Client) DiscoverAndAddAccountsContext(ctx context.Context, institutionID int64, credentials []Credential, opts ...RequestOption) (accounts []Account, challenge *Challenge, err error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	ctx, span := c.startSpan(ctx, "DiscoverAndAddAccounts", SpanKindInternal,
+		SpanAttribute{"intuit.institution_id", institutionID})
+	defer func() { endSpan(span, err) }()
+
+	payload := credentialsPayload{}
+	payload.Credentials.Credential = credentials
+
+	req, err := c.request("POST", fmt.Sprintf("/institutions/%d/logins", institutionID), payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.addAccounts(req.WithContext(ctx), institutionID)
+}
+
+// AnswerChallenge answers a challenge returned by DiscoverAndAddAccounts with
+// one answer per question, and returns the accounts discovered. The
+// institution may raise a further challenge, which is returned instead.
+func (c *Client) AnswerChallenge(challenge *Challenge, answers []string, opts ...RequestOption) ([]Account, *Challenge, error) {
+	return c.AnswerChallengeContext(context.Background(), challenge, answers, opts...)
+}
+
+// AnswerChallengeContext is like AnswerChallenge, but sends the request with
+// ctx
+func (c *Client) AnswerChallengeContext(ctx context.Context, challenge *Challenge, answers []string, opts ...RequestOption) (accounts []Account, next *Challenge, err error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	ctx, span := c.startSpan(ctx, "AnswerChallenge", SpanKindInternal,
+		SpanAttribute{"intuit.institution_id", challenge.InstitutionID})
+	defer func() { endSpan(span, err) }()
+
+	payload := challengeResponsePayload{}
+	payload.ChallengeResponses.Response = answers
+
+	req, err := c.request("POST", fmt.Sprintf("/institutions/%d/logins", challenge.InstitutionID), payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Set(ChallengeSessionIDHeader, challenge.SessionID)
+	req.Header.Set(ChallengeNodeIDHeader, challenge.NodeID)
+
+	return c.addAccounts(req.WithContext(ctx), challenge.InstitutionID)
+}
+
+// addAccounts sends a request adding a login and decodes the discovered
+// accounts, or the challenge raised instead
+func (c *Client) addAccounts(req *http.Request, institutionID int64) ([]Account, *Challenge, error) {
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	if isChallenge(resp) {
+		var payload challengePayload
+		if err := decoder.Decode(&payload); err != nil {
+			return nil, nil, fmt.Errorf("decoding challenge: %w", err)
+		}
+
+		challenge := &Challenge{
+			InstitutionID: institutionID,
+			SessionID:     resp.Header.Get(ChallengeSessionIDHeader),
+			NodeID:        resp.Header.Get(ChallengeNodeIDHeader),
+			Questions:     make([]ChallengeQuestion, 0, len(payload.Challenge)),
+		}
+
+		for _, q := range payload.Challenge {
+			if len(q.TextOrImageAndChoice) == 0 {
+				continue
+			}
+
+			challenge.Questions = append(challenge.Questions, ChallengeQuestion{
+				Text:    q.TextOrImageAndChoice[0],
+				Choices: q.TextOrImageAndChoice[1:],
+			})
+		}
+
+		return nil, challenge, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, newAPIError(resp)
+	}
+
+	payload := accountList{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, nil, err
+	}
+
+	return payload.Accounts, nil, nil
+}
+
+// isChallenge reports whether the response is a multi-factor authentication
+// challenge rather than a rejected token
+func isChallenge(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusUnauthorized && resp.Header.Get(ChallengeSessionIDHeader) != ""
+}
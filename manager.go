@@ -0,0 +1,266 @@
+package intuit
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// Manager holds the configuration shared by every customer of one Intuit
+// application, along with a cache of their clients. Use one Manager per
+// application, e.g. one for a development app and one for production.
+type Manager struct {
+	ConsumerKey    string
+	ConsumerSecret string
+
+	SAMLProviderID string
+	PrivateKey     *rsa.PrivateKey
+	Signer         crypto.Signer
+	KeyRing        *KeyRing
+	SigningCert    *x509.Certificate
+	SigningHash    crypto.Hash
+
+	AssertionOptions AssertionOptions
+
+	HTTPClient *http.Client
+	TokenURL   string
+	BaseURL    string
+
+	// PrivacyKey, if set, enables privacy mode on every client of the manager;
+	// see WithPrivacyKey
+	PrivacyKey []byte
+
+	// TokenStore, if set, is shared by every client of the manager
+	TokenStore TokenStore
+
+	// InstitutionCache, if set, is shared by every client of the manager
+	InstitutionCache InstitutionCache
+
+	// RateLimiter, if set, is shared by every client of the manager, so that
+	// they are paced together
+	RateLimiter RateLimiter
+
+	// RetryPolicy, if set, controls the retries of every client of the
+	// manager; see WithRetryPolicy
+	RetryPolicy *RetryPolicy
+
+	// Middleware wraps the transport of every client of the manager
+	Middleware []Middleware
+
+	// Logger, if set, is used by every client of the manager; see WithLogger
+	Logger *slog.Logger
+
+	// DebugWriter, if set, receives dumps of the failed requests of every
+	// client of the manager; see WithDebugWriter
+	DebugWriter io.Writer
+
+	// Metrics, if set, receives the measurements of the manager and every
+	// client of the manager; see WithMetrics
+	Metrics Metrics
+
+	// Cohort, if set, returns the cohort of each customer, which labels the
+	// customer's refresh and sync outcomes; see CohortMetrics
+	Cohort func(customerID string) string
+
+	// Clock, if set, is used by every client of the manager; see WithClock.
+	// The clock of an LRUClientCache is set separately.
+	Clock Clock
+
+	// Cache stores clients returned by ClientFor. If nil, every call to
+	// ClientFor initializes a new client.
+	Cache ClientCache
+
+	// OnBackgroundError is called with errors and recovered panics from the
+	// manager's background goroutines, such as cache eviction timers. If nil,
+	// the package-level OnBackgroundError is used.
+	OnBackgroundError func(error)
+
+	// mu guards the manager's settings against SetExtensions
+	mu sync.Mutex
+
+	// clients deduplicates ClientFor, so that concurrent calls for the same
+	// customer share one client while other customers' clients are created
+	// in parallel
+	clients flightGroup
+}
+
+// NewManager returns a manager for the application identified by the given
+// credentials. It uses DefaultHTTPClient, the Production environment, and a new
+// LRU client cache with the default size and TTL.
+func NewManager(consumerKey, consumerSecret, samlProviderID string, privateKey *rsa.PrivateKey) *Manager {
+	m := &Manager{
+		ConsumerKey:    consumerKey,
+		ConsumerSecret: consumerSecret,
+
+		SAMLProviderID: samlProviderID,
+		PrivateKey:     privateKey,
+
+		HTTPClient: DefaultHTTPClient,
+		TokenURL:   AccessTokenEndpoint,
+		BaseURL:    BaseURL,
+	}
+
+	cache := NewLRUClientCache(DefaultClientCacheSize, DefaultClientCacheTTL)
+	cache.OnBackgroundError = m.backgroundError
+	m.Cache = cache
+
+	return m
+}
+
+// defaultManager returns a manager built from the package-level defaults. It
+// backs NewClient. Callers must hold clientsMu.
+func defaultManager() *Manager {
+	return &Manager{
+		ConsumerKey:    DefaultConsumerKey,
+		ConsumerSecret: DefaultConsumerSecret,
+
+		SAMLProviderID: DefaultSAMLProviderID,
+		PrivateKey:     DefaultPrivateKey,
+
+		HTTPClient: DefaultHTTPClient,
+		TokenURL:   AccessTokenEndpoint,
+		BaseURL:    BaseURL,
+
+		Cache: clientCache,
+	}
+}
+
+// ClientFor returns an initialized client for the customer, reusing a cached
+// client if there is one
+func (m *Manager) ClientFor(customerID string) (*Client, error) {
+	return m.clientFor(customerID, &m.clients)
+}
+
+// clientFor implements ClientFor, deduplicating the creation of clients that
+// will be cached with `flight`
+func (m *Manager) clientFor(customerID string, flight *flightGroup) (*Client, error) {
+	m.mu.Lock()
+	cache, metrics, opts := m.Cache, m.Metrics, m.options()
+	m.mu.Unlock()
+
+	opts = append(opts, WithCohort(m.cohort(customerID)))
+
+	if cache == nil {
+		return NewClientWithOptions(customerID, opts...)
+	}
+
+	client, ok := cache.Get(customerID)
+	metricsOrNop(metrics).ObserveCache(CacheClients, ok)
+	if ok {
+		return client, nil
+	}
+
+	value, err := flight.do(customerID, func() (interface{}, error) {
+		// a call that was in flight during the lookup above may have cached a
+		// client since
+		if client, ok := cache.Get(customerID); ok {
+			return client, nil
+		}
+
+		client, err := NewClientWithOptions(customerID, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		cache.Put(customerID, client)
+
+		return client, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*Client), nil
+}
+
+// Prewarm signs an assertion and acquires an OAuth token for the customer in
+// the background, caching the resulting client so that the first request of an
+// interactive session doesn't pay for the SAML exchange. Call it when the
+// session starts. Errors are reported through OnBackgroundError. Prewarm has no
+// effect if the manager has no cache.
+func (m *Manager) Prewarm(customerID string) {
+	if m.cache() == nil {
+		return
+	}
+
+	goBackground("client prewarm", m.OnBackgroundError, func() {
+		if _, err := m.ClientFor(customerID); err != nil {
+			m.backgroundError(err)
+		}
+	})
+}
+
+// Evict removes the customer's client from the cache, so that the next call to
+// ClientFor initializes a new one
+func (m *Manager) Evict(customerID string) {
+	if cache := m.cache(); cache != nil {
+		cache.Evict(customerID)
+	}
+}
+
+// cache returns the manager's client cache, which SetExtensions may replace
+func (m *Manager) cache() ClientCache {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.Cache
+}
+
+// metrics returns the manager's metrics, which SetExtensions may replace
+func (m *Manager) metrics() Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.Metrics
+}
+
+// cohort returns the customer's cohort, or "" if the manager has no Cohort
+func (m *Manager) cohort(customerID string) string {
+	if m.Cohort == nil {
+		return ""
+	}
+
+	return m.Cohort(customerID)
+}
+
+// backgroundError reports an error from one of the manager's background
+// goroutines
+func (m *Manager) backgroundError(err error) {
+	reportBackgroundError(m.OnBackgroundError, err)
+}
+
+// options returns the client options corresponding to the manager's settings
+func (m *Manager) options() []Option {
+	opts := []Option{
+		WithConsumerCredentials(m.ConsumerKey, m.ConsumerSecret),
+		WithSAMLProvider(m.SAMLProviderID),
+		WithPrivateKey(m.PrivateKey),
+		WithSigner(m.Signer),
+		WithKeyRing(m.KeyRing),
+		WithSigningCert(m.SigningCert),
+		WithSigningHash(m.SigningHash),
+		WithAssertionOptions(m.AssertionOptions),
+		WithHTTPClient(m.HTTPClient),
+		WithTokenURL(m.TokenURL),
+		WithBaseURL(m.BaseURL),
+		WithTokenStore(m.TokenStore),
+		WithPrivacyKey(m.PrivacyKey),
+		WithClock(m.Clock),
+		WithInstitutionCache(m.InstitutionCache),
+		WithRateLimiter(m.RateLimiter),
+		WithMiddleware(m.Middleware...),
+		WithLogger(m.Logger),
+		WithMetrics(m.Metrics),
+		WithDebugWriter(m.DebugWriter),
+	}
+
+	if m.RetryPolicy != nil {
+		opts = append(opts, WithRetryPolicy(*m.RetryPolicy))
+	}
+
+	return opts
+}
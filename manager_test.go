@@ -0,0 +1,62 @@
+package intuit_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/intuittest"
+)
+
+// TestManagerCacheSwap checks, under the race detector, that replacing the
+// client cache with SetExtensions is safe while the cache is in use
+func TestManagerCacheSwap(t *testing.T) {
+	srv := intuittest.NewServer()
+	defer srv.Close()
+
+	if err := srv.Seed(testCustomer); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := srv.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.ClientFor(testCustomer); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 20; i++ {
+			e := m.Extensions()
+			e.ClientCache = intuit.NewLRUClientCache(10, time.Hour)
+			if err := m.SetExtensions(e); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 20; i++ {
+			if _, err := m.ClientFor(testCustomer); err != nil {
+				t.Error(err)
+				return
+			}
+			m.SupportBundle(context.Background(), testCustomer, time.Time{})
+			m.Evict(testCustomer)
+		}
+	}()
+
+	wg.Wait()
+}
@@ -0,0 +1,83 @@
+package intuit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ResponseMetadata describes the response to an API call; see
+// WithResponseMetadata
+type ResponseMetadata struct {
+	StatusCode int
+	Header     http.Header
+
+	// IntuitTID is the Intuit transaction ID of the response, to be quoted in
+	// support cases
+	IntuitTID string
+
+	// RateLimit holds the rate limit headers of the response, if any
+	RateLimit RateLimitInfo
+
+	// Duration is the time taken by the call's request, including retries and
+	// token refreshes but not decoding the response
+	Duration time.Duration
+
+	// Attempts is the number of times the request was sent
+	Attempts int
+}
+
+// RateLimitInfo holds the rate limit headers of a response. Fields are zero
+// if the corresponding header is missing.
+type RateLimitInfo struct {
+	Limit      int
+	Remaining  int
+	Reset      time.Time
+	RetryAfter time.Duration
+}
+
+// WithResponseMetadata fills `meta` with the status, headers and timing of the
+// call's response. It is filled even if the call fails, as long as a response
+// was received, and left unchanged if no request was sent, e.g. when
+// InstitutionDetails is answered from a cache. Calls that send several
+// requests, such as AccountTransactionsRange, describe the last one.
+func WithResponseMetadata(meta *ResponseMetadata) RequestOption {
+	return func(o *requestOptions) {
+		o.metadata = meta
+	}
+}
+
+// recordMetadata fills the call's ResponseMetadata, if it asked for one
+func recordMetadata(req *http.Request, resp *http.Response, attempts int, duration time.Duration) {
+	meta := requestOptionsFrom(req.Context()).metadata
+	if meta == nil || resp == nil {
+		return
+	}
+
+	*meta = ResponseMetadata{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		IntuitTID:  resp.Header.Get(TrackingIDHeader),
+		RateLimit:  parseRateLimit(resp.Header),
+		Duration:   duration,
+		Attempts:   attempts,
+	}
+}
+
+// parseRateLimit reads the conventional X-RateLimit-* and Retry-After headers
+func parseRateLimit(header http.Header) RateLimitInfo {
+	var info RateLimitInfo
+
+	info.Limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	info.Remaining, _ = strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+		info.Reset = time.Unix(reset, 0)
+	}
+
+	if after, ok := parseRetryAfter(header.Get("Retry-After")); ok {
+		info.RetryAfter = after
+	}
+
+	return info
+}
@@ -0,0 +1,150 @@
+package intuit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Constants representing the error classes reported to Metrics
+const (
+	ErrorClassNone      = ""
+	ErrorClassNetwork   = "network"
+	ErrorClassTimeout   = "timeout"
+	ErrorClassCancelled = "cancelled"
+	ErrorClassAuth      = "auth"
+	ErrorClassThrottled = "throttled"
+	ErrorClassClient    = "client"
+	ErrorClassServer    = "server"
+)
+
+// Constants naming the caches reported to Metrics
+const (
+	CacheClients      = "clients"
+	CacheInstitutions = "institutions"
+	CacheTokens       = "tokens"
+)
+
+// Metrics receives measurements from clients and managers, e.g. for capacity
+// planning against Intuit's throttles. Implementations must be safe for
+// concurrent use; see the intuitprom package for a Prometheus adapter.
+type Metrics interface {
+	// ObserveRequest is called after every HTTP request, including token
+	// exchanges and retries. `endpoint` is the request path with numeric IDs
+	// replaced by "{id}", and `status` is zero if no response was received.
+	ObserveRequest(method, endpoint string, status int, errorClass string, duration time.Duration)
+
+	// ObserveTokenRefresh is called after every SAML token exchange
+	ObserveTokenRefresh(err error)
+
+	// ObserveCache is called after every lookup in the named cache
+	ObserveCache(cache string, hit bool)
+}
+
+// CohortMetrics is implemented by Metrics that also receive the outcomes of
+// Scheduler refreshes and Syncer syncs, labelled with the customer's cohort so
+// that they can be broken down by product tier or rollout cohort. Cohorts come
+// from Client.Cohort and Manager.Cohort, and are empty if unset; each is a
+// label value, so there should be few of them.
+type CohortMetrics interface {
+	Metrics
+
+	// ObserveRefresh is called after a Scheduler refreshes a customer, with
+	// whether the refresh succeeded
+	ObserveRefresh(cohort string, success bool)
+
+	// ObserveSync is called after every Syncer.Sync with its result and
+	// error. The result is nil if nothing was synced.
+	ObserveSync(cohort string, result *SyncResult, err error)
+}
+
+// WithMetrics reports the client's requests, token refreshes and cache lookups
+// to `metrics`
+func WithMetrics(metrics Metrics) Option {
+	return func(c *Client) {
+		c.Metrics = metrics
+	}
+}
+
+// WithCohort labels the client's sync outcomes with `cohort`; see
+// CohortMetrics
+func WithCohort(cohort string) Option {
+	return func(c *Client) {
+		c.Cohort = cohort
+	}
+}
+
+// nopMetrics discards all measurements
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRequest(string, string, int, string, time.Duration) {}
+func (nopMetrics) ObserveTokenRefresh(error)                                 {}
+func (nopMetrics) ObserveCache(string, bool)                                 {}
+
+// metricsOrNop returns `metrics`, or a Metrics that discards everything if it
+// is nil
+func metricsOrNop(metrics Metrics) Metrics {
+	if metrics == nil {
+		return nopMetrics{}
+	}
+
+	return metrics
+}
+
+// observeRequest reports the outcome of a request to the client's metrics
+func (c *Client) observeRequest(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+
+	metricsOrNop(c.Metrics).ObserveRequest(req.Method, EndpointTemplate(req.URL.Path), status, ErrorClass(resp, err), duration)
+}
+
+// EndpointTemplate returns `path` with every numeric segment replaced by
+// "{id}", e.g. "/accounts/{id}/transactions", so that it can be used as a
+// low-cardinality metric label
+func EndpointTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment != "" && strings.Trim(segment, "0123456789") == "" {
+			segments[i] = "{id}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// ErrorClass classifies the outcome of a request as one of the ErrorClass
+// constants. Successful requests are ErrorClassNone.
+func ErrorClass(resp *http.Response, err error) string {
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, context.Canceled):
+			return ErrorClassCancelled
+		case errors.Is(err, context.DeadlineExceeded):
+			return ErrorClassTimeout
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return ErrorClassTimeout
+		}
+
+		return ErrorClassNetwork
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrorClassAuth
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return ErrorClassThrottled
+	case resp.StatusCode >= 500:
+		return ErrorClassServer
+	case resp.StatusCode >= 400:
+		return ErrorClassClient
+	}
+
+	return ErrorClassNone
+}
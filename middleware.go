@@ -0,0 +1,59 @@
+package intuit
+
+import "net/http"
+
+// Middleware wraps the transport used for outgoing requests, e.g. to add
+// logging, tracing or caching
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use appends middleware to the client's transport chain. It applies to every
+// request the client sends, including the SAML token exchange. Middleware added
+// first is outermost, so it sees requests first and responses last.
+//
+// Use is not safe to call concurrently with requests; configure middleware
+// before sharing the client.
+func (c *Client) Use(mw ...Middleware) {
+	c.middleware = append(c.middleware, mw...)
+}
+
+// WithMiddleware adds middleware to the client, as with Client.Use
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.Use(mw...)
+	}
+}
+
+// httpClient returns the client's HTTP client with its transport wrapped in
+// the middleware chain
+func (c *Client) httpClient() *http.Client {
+	base := c.HTTPClient
+	if base == nil {
+		base = DefaultHTTPClient
+	}
+
+	if len(c.middleware) == 0 {
+		return base
+	}
+
+	transport := base.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		transport = c.middleware[i](transport)
+	}
+
+	wrapped := *base
+	wrapped.Transport = transport
+
+	return &wrapped
+}
@@ -0,0 +1,115 @@
+package intuit
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MoneyScale is the number of Money units in one unit of currency. Four
+// decimal places cover every ISO 4217 currency, and the sub-cent prices some
+// institutions report.
+const MoneyScale = 10000
+
+// Money is an exact decimal amount of currency, stored as an integer number of
+// 1/MoneyScale units so that sums of many amounts don't accumulate rounding
+// errors. The currency itself is given by the account or transaction holding
+// the amount. Money values can be added, subtracted and compared directly.
+type Money int64
+
+// MoneyFromFloat returns the Money nearest to `f`
+func MoneyFromFloat(f float64) Money {
+	return Money(math.Round(f * MoneyScale))
+}
+
+// ParseMoney parses a decimal amount such as "-12.34" exactly. Digits beyond
+// the precision of Money are rounded half away from zero.
+func ParseMoney(s string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	scaled := new(big.Int).Mul(r.Num(), big.NewInt(MoneyScale))
+	quo, rem := new(big.Int).QuoRem(scaled, r.Denom(), new(big.Int))
+
+	// round half away from zero
+	if rem.Sign() != 0 && new(big.Int).Abs(new(big.Int).Lsh(rem, 1)).Cmp(r.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(int64(rem.Sign())))
+	}
+
+	if !quo.IsInt64() {
+		return 0, fmt.Errorf("amount %q out of range", s)
+	}
+
+	return Money(quo.Int64()), nil
+}
+
+// Float64 returns the amount as a float64, for compatibility with code written
+// against float amounts
+func (m Money) Float64() float64 {
+	return float64(m) / MoneyScale
+}
+
+// Abs returns the absolute value of the amount
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+
+	return m
+}
+
+// String formats the amount as a decimal with at least two decimal places
+func (m Money) String() string {
+	var b strings.Builder
+
+	u := uint64(m)
+	if m < 0 {
+		b.WriteByte('-')
+		u = uint64(-m)
+	}
+
+	b.WriteString(strconv.FormatUint(u/MoneyScale, 10))
+
+	frac := fmt.Sprintf("%04d", u%MoneyScale)
+	frac = strings.TrimRight(frac, "0")
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	b.WriteString("." + frac)
+
+	return b.String()
+}
+
+// MarshalJSON implements the json Marshaler interface. The amount is encoded as
+// a JSON number.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON implements the json Unmarshaler interface. It accepts JSON
+// numbers, numeric strings and null, which decodes to zero.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*m = 0
+		return nil
+	}
+
+	s := string(data)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
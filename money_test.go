@@ -0,0 +1,135 @@
+package intuit
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{in: "0", want: 0},
+		{in: "12.34", want: 123400},
+		{in: "-12.34", want: -123400},
+		{in: " 1 ", want: 10000},
+		{in: "0.0001", want: 1},
+		{in: "0.00005", want: 1},
+		{in: "-0.00005", want: -1},
+		{in: "0.00004999", want: 0},
+		{in: "1e2", want: 1000000},
+		{in: "922337203685477.5807", want: 9223372036854775807},
+		{in: "922337203685477.5808", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "1,00", wantErr: true},
+		{in: "$1", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseMoney(test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseMoney(%q) = %d, want an error", test.in, got)
+			}
+			continue
+		}
+
+		if err != nil || got != test.want {
+			t.Errorf("ParseMoney(%q) = %d, %v, want %d", test.in, got, err, test.want)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	// 0.1 + 0.2 is exact in Money, unlike in float64
+	sum := MoneyFromFloat(0.1) + MoneyFromFloat(0.2)
+	if sum != MoneyFromFloat(0.3) {
+		t.Errorf("0.1 + 0.2 = %s, want 0.30", sum)
+	}
+
+	var total Money
+	for i := 0; i < 1000; i++ {
+		total += MoneyFromFloat(0.01)
+	}
+	if total != 10*MoneyScale {
+		t.Errorf("1000 * 0.01 = %s, want 10.00", total)
+	}
+
+	tests := []struct {
+		a, b Money
+		want string
+	}{
+		{123400, -23400, "10.00"},
+		{-123400, 23400, "-10.00"},
+		{5, 5, "0.001"},
+		{0, -5, "-0.0005"},
+	}
+
+	for _, test := range tests {
+		if got := (test.a + test.b).String(); got != test.want {
+			t.Errorf("%d + %d = %s, want %s", test.a, test.b, got, test.want)
+		}
+	}
+
+	if got := Money(-5).Abs(); got != 5 {
+		t.Errorf("Money(-5).Abs() = %d, want 5", got)
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{0, "0.00"},
+		{10000, "1.00"},
+		{12345, "1.2345"},
+		{12300, "1.23"},
+		{12340, "1.234"},
+		{-1, "-0.0001"},
+		{-9223372036854775808, "-922337203685477.5808"},
+	}
+
+	for _, test := range tests {
+		if got := test.m.String(); got != test.want {
+			t.Errorf("Money(%d).String() = %s, want %s", test.m, got, test.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    Money
+		wantErr bool
+	}{
+		{json: `12.34`, want: 123400},
+		{json: `"12.34"`, want: 123400},
+		{json: `-0.5`, want: -5000},
+		{json: `null`, want: 0},
+		{json: `"abc"`, wantErr: true},
+		{json: `true`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		m := Money(1)
+		err := json.Unmarshal([]byte(test.json), &m)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) = %d, want an error", test.json, m)
+			}
+			continue
+		}
+
+		if err != nil || m != test.want {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d", test.json, m, err, test.want)
+		}
+	}
+
+	data, err := json.Marshal(struct{ Amount Money }{-123400})
+	if err != nil || string(data) != `{"Amount":-12.34}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+}
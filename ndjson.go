@@ -0,0 +1,51 @@
+package intuit
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WriteNDJSON writes the transactions to w as newline-delimited JSON, one
+// transaction per line in the CAD API's JSON format
+func (t Transactions) WriteNDJSON(w io.Writer) error {
+	encoder := newNDJSONEncoder(w)
+	for _, txn := range t {
+		if err := encoder.Encode(txn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteNDJSON writes the transactions yielded by `it` to w as newline-delimited
+// JSON as they are decoded, so that large payloads are never held in memory,
+// and returns the number of transactions written. It doesn't close `it`.
+//
+//	it, err := c.StreamAccountTransactions(ctx, accountID, start, end)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//
+//	n, err := intuit.WriteNDJSON(w, it)
+func WriteNDJSON(w io.Writer, it TransactionIterator) (int, error) {
+	encoder := newNDJSONEncoder(w)
+
+	var n int
+	for it.Next() {
+		if err := encoder.Encode(it.Transaction()); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, it.Err()
+}
+
+func newNDJSONEncoder(w io.Writer) *json.Encoder {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	return encoder
+}
@@ -0,0 +1,108 @@
+package intuit
+
+import (
+	"context"
+	"sort"
+)
+
+// NetWorth totals account balances in one currency. Liabilities is the
+// amount owed, so it is positive when money is owed, and Net is Assets -
+// Liabilities.
+type NetWorth struct {
+	Currency    string
+	Assets      Money
+	Liabilities Money
+	Net         Money
+
+	// Accounts is the number of accounts totaled
+	Accounts int
+
+	// AsOf is the earliest balance date of the accounts totaled, since the
+	// total is only as current as its stalest balance
+	AsOf Timestamp
+}
+
+// NetWorthOptions configures ComputeNetWorth. The zero value totals the
+// active accounts per currency, without conversion.
+type NetWorthOptions struct {
+	// Currency, if set, is the currency every balance is converted to with
+	// Converter, giving a single total
+	Currency string
+
+	// Converter converts balances to Currency. If nil, NopCurrencyConverter
+	// is used, so accounts in other currencies cause an error.
+	Converter CurrencyConverter
+
+	// IncludeInactive includes inactive accounts
+	IncludeInactive bool
+}
+
+// ComputeNetWorth totals the balances of the accounts as assets and
+// liabilities, per currency ordered by currency code, or in opts.Currency.
+//
+// Banking and investment accounts are assets, and credit and loan accounts
+// are liabilities, whose balances CAD reports as negative amounts. Accounts
+// of no known kind count as assets or liabilities by the sign of their
+// balance. Rewards accounts are skipped, since their balances are points or
+// miles rather than money.
+func ComputeNetWorth(accounts []Account, opts NetWorthOptions) ([]NetWorth, error) {
+	return ComputeNetWorthContext(context.Background(), accounts, opts)
+}
+
+// ComputeNetWorthContext is like ComputeNetWorth, but converts balances with
+// ctx
+func ComputeNetWorthContext(ctx context.Context, accounts []Account, opts NetWorthOptions) ([]NetWorth, error) {
+	totals := map[string]*NetWorth{}
+
+	for _, a := range accounts {
+		if !a.IsActive() && !opts.IncludeInactive {
+			continue
+		}
+
+		kind := a.Kind()
+		if kind == AccountKindRewards {
+			continue
+		}
+
+		currency, balance := a.Currency, a.Balance
+		if opts.Currency != "" {
+			converted, err := ConvertMoney(ctx, opts.Converter, balance, currency, opts.Currency)
+			if err != nil {
+				return nil, err
+			}
+			currency, balance = opts.Currency, converted
+		}
+
+		total, ok := totals[currency]
+		if !ok {
+			total = &NetWorth{Currency: currency}
+			totals[currency] = total
+		}
+
+		switch {
+		case kind == AccountKindCredit || kind == AccountKindLoan:
+			total.Liabilities -= balance
+		case kind == AccountKindOther && balance < 0:
+			total.Liabilities -= balance
+		default:
+			total.Assets += balance
+		}
+		total.Net += balance
+
+		total.Accounts++
+		if total.AsOf.IsZero() || (!a.BalanceDate.IsZero() && a.BalanceDate.Before(total.AsOf)) {
+			total.AsOf = a.BalanceDate
+		}
+	}
+
+	netWorth := make([]NetWorth, 0, len(totals))
+	for _, total := range totals {
+		netWorth = append(netWorth, *total)
+	}
+
+	sort.Slice(netWorth, func(i, j int) bool {
+		return netWorth[i].Currency < netWorth[j].Currency
+	})
+
+	return netWorth, nil
+}
@@ -0,0 +1,157 @@
+package intuit
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// oauthToken is an OAuth 1.0a access token and its secret
+type oauthToken struct {
+	key    string
+	secret string
+}
+
+// signOAuth adds an OAuth 1.0a Authorization header to req, signed with
+// HMAC-SHA1 using the consumer credentials and `token` (RFC 5849). The query
+// parameters and any form-encoded body are covered by the signature.
+func signOAuth(req *http.Request, consumerKey, consumerSecret string, token *oauthToken, now time.Time) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	oauthParams := map[string]string{
+		"oauth_consumer_key":     consumerKey,
+		"oauth_nonce":            hex.EncodeToString(nonce),
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(now.Unix(), 10),
+		"oauth_token":            token.key,
+		"oauth_version":          "1.0",
+	}
+
+	params, err := requestParams(req)
+	if err != nil {
+		return err
+	}
+	for name, value := range oauthParams {
+		params = append(params, [2]string{name, value})
+	}
+
+	oauthParams["oauth_signature"] = oauthSignature(oauthBaseString(req.Method, req.URL, params), consumerSecret, token.secret)
+
+	names := make([]string, 0, len(oauthParams))
+	for name := range oauthParams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]string, len(names))
+	for i, name := range names {
+		fields[i] = fmt.Sprintf(`%s="%s"`, name, oauthEscape(oauthParams[name]))
+	}
+	req.Header.Set("Authorization", "OAuth "+strings.Join(fields, ", "))
+
+	return nil
+}
+
+// requestParams returns the query parameters of req and the parameters of its
+// body, if it is form-encoded
+func requestParams(req *http.Request) ([][2]string, error) {
+	var params [][2]string
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			params = append(params, [2]string{name, value})
+		}
+	}
+
+	if req.Body == nil || req.GetBody == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return params, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	form, err := url.ParseQuery(string(data))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range form {
+		for _, value := range values {
+			params = append(params, [2]string{name, value})
+		}
+	}
+
+	return params, nil
+}
+
+// oauthBaseString returns the signature base string of a request with the
+// given method, URL and parameters
+func oauthBaseString(method string, u *url.URL, params [][2]string) string {
+	encoded := make([][2]string, len(params))
+	for i, param := range params {
+		encoded[i] = [2]string{oauthEscape(param[0]), oauthEscape(param[1])}
+	}
+	sort.Slice(encoded, func(i, j int) bool {
+		if encoded[i][0] != encoded[j][0] {
+			return encoded[i][0] < encoded[j][0]
+		}
+		return encoded[i][1] < encoded[j][1]
+	})
+
+	pairs := make([]string, len(encoded))
+	for i, param := range encoded {
+		pairs[i] = param[0] + "=" + param[1]
+	}
+
+	host := strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		host = strings.ToLower(u.Hostname())
+	}
+
+	baseURL := strings.ToLower(u.Scheme) + "://" + host + u.EscapedPath()
+
+	return strings.ToUpper(method) + "&" + oauthEscape(baseURL) + "&" + oauthEscape(strings.Join(pairs, "&"))
+}
+
+// oauthSignature returns the HMAC-SHA1 signature of a signature base string
+func oauthSignature(baseString, consumerSecret, tokenSecret string) string {
+	mac := hmac.New(sha1.New, []byte(oauthEscape(consumerSecret)+"&"+oauthEscape(tokenSecret)))
+	mac.Write([]byte(baseString))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// oauthEscape percent-encodes every byte of s but the unreserved characters
+// of RFC 3986, as OAuth requires
+func oauthEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package intuit
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOAuthBaseString(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		params [][2]string
+		want   string
+	}{
+		{
+			// RFC 5849, section 3.4.1.1
+			name:   "RFC 5849",
+			method: "post",
+			url:    "HTTP://Example.com:80/request",
+			params: [][2]string{
+				{"b5", "=%3D"}, {"a3", "a"}, {"c@", ""}, {"a2", "r b"},
+				{"c2", ""}, {"a3", "2 q"},
+				{"oauth_consumer_key", "9djdj82h48djs9d2"},
+				{"oauth_token", "kkk9d7dh3k39sjv7"},
+				{"oauth_signature_method", "HMAC-SHA1"},
+				{"oauth_timestamp", "137131201"},
+				{"oauth_nonce", "7d8f3e4a"},
+			},
+			want: "POST&http%3A%2F%2Fexample.com%2Frequest&a2%3Dr%2520b%26a3%3D2%2520q%26a3%3Da%26b5%3D%253D%25253D%26c%2540%3D%26c2%3D%26oauth_consumer_key%3D9djdj82h48djs9d2%26oauth_nonce%3D7d8f3e4a%26oauth_signature_method%3DHMAC-SHA1%26oauth_timestamp%3D137131201%26oauth_token%3Dkkk9d7dh3k39sjv7",
+		},
+		{
+			name:   "names that prefix each other",
+			method: "GET",
+			url:    "https://example.com:443/a%20b",
+			params: [][2]string{{"a1", "x"}, {"a", "y"}},
+			want:   "GET&https%3A%2F%2Fexample.com%2Fa%2520b&a%3Dy%26a1%3Dx",
+		},
+		{
+			name:   "non-default port",
+			method: "GET",
+			url:    "https://example.com:8443/",
+			want:   "GET&https%3A%2F%2Fexample.com%3A8443%2F&",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := url.Parse(test.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := oauthBaseString(test.method, u, test.params); got != test.want {
+				t.Errorf("oauthBaseString() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+// TestOAuthSignature checks the HMAC-SHA1 example of the OAuth Core 1.0
+// specification, appendix A.5
+func TestOAuthSignature(t *testing.T) {
+	u, _ := url.Parse("http://photos.example.net/photos")
+	base := oauthBaseString("GET", u, [][2]string{
+		{"file", "vacation.jpg"},
+		{"size", "original"},
+		{"oauth_consumer_key", "dpf43f3p2l4k3l03"},
+		{"oauth_token", "nnch734d00sl2jdk"},
+		{"oauth_signature_method", "HMAC-SHA1"},
+		{"oauth_timestamp", "1191242096"},
+		{"oauth_nonce", "kllo9940pd9333jh"},
+		{"oauth_version", "1.0"},
+	})
+
+	if got, want := oauthSignature(base, "kd94hf93k423kf44", "pfkkdhi9sl3r4s00"), "tR3+Ty81lMeYAr/Fid0kMTYa/WM="; got != want {
+		t.Errorf("oauthSignature() = %s, want %s", got, want)
+	}
+}
+
+func TestSignOAuth(t *testing.T) {
+	req, err := http.NewRequest("POST", "https://example.com/v1/accounts?x=1", strings.NewReader("a=b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if err := signOAuth(req, "key", "secret", &oauthToken{key: "to ken", secret: "s"}, time.Unix(1500000000, 0)); err != nil {
+		t.Fatalf("signOAuth() error = %v", err)
+	}
+
+	auth := req.Header.Get("Authorization")
+	for _, want := range []string{
+		`OAuth oauth_consumer_key="key", oauth_nonce="`,
+		`oauth_signature_method="HMAC-SHA1"`,
+		`oauth_timestamp="1500000000"`,
+		`oauth_token="to%20ken"`,
+		`oauth_version="1.0"`,
+		`oauth_signature="`,
+	} {
+		if !strings.Contains(auth, want) {
+			t.Errorf("Authorization = %s, want it to contain %s", auth, want)
+		}
+	}
+}
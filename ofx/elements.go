@@ -0,0 +1,104 @@
+package ofx
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// The types below mirror the OFX 2.2 elements used in statement downloads
+
+type document struct {
+	XMLName    xml.Name            `xml:"OFX"`
+	SignOn     signOnResponse      `xml:"SIGNONMSGSRSV1>SONRS"`
+	Bank       *bankMessages       `xml:"BANKMSGSRSV1,omitempty"`
+	CreditCard *creditCardMessages `xml:"CREDITCARDMSGSRSV1,omitempty"`
+}
+
+type status struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+var okStatus = status{Code: 0, Severity: "INFO"}
+
+type signOnResponse struct {
+	Status   status `xml:"STATUS"`
+	DTServer string `xml:"DTSERVER"`
+	Language string `xml:"LANGUAGE"`
+	FI       *fi    `xml:"FI,omitempty"`
+	IntuBID  string `xml:"INTU.BID,omitempty"`
+}
+
+type fi struct {
+	Org string `xml:"ORG,omitempty"`
+	FID string `xml:"FID,omitempty"`
+}
+
+type bankMessages struct {
+	Responses []bankResponse `xml:"STMTTRNRS"`
+}
+
+type bankResponse struct {
+	TrnUID    string        `xml:"TRNUID"`
+	Status    status        `xml:"STATUS"`
+	Statement bankStatement `xml:"STMTRS"`
+}
+
+type bankStatement struct {
+	Currency        string          `xml:"CURDEF"`
+	Account         bankAccount     `xml:"BANKACCTFROM"`
+	TransactionList transactionList `xml:"BANKTRANLIST"`
+	LedgerBalance   ledgerBalance   `xml:"LEDGERBAL"`
+}
+
+type bankAccount struct {
+	BankID      string `xml:"BANKID"`
+	AccountID   string `xml:"ACCTID"`
+	AccountType string `xml:"ACCTTYPE"`
+}
+
+type creditCardMessages struct {
+	Responses []creditCardResponse `xml:"CCSTMTTRNRS"`
+}
+
+type creditCardResponse struct {
+	TrnUID    string              `xml:"TRNUID"`
+	Status    status              `xml:"STATUS"`
+	Statement creditCardStatement `xml:"CCSTMTRS"`
+}
+
+type creditCardStatement struct {
+	Currency        string            `xml:"CURDEF"`
+	Account         creditCardAccount `xml:"CCACCTFROM"`
+	TransactionList transactionList   `xml:"BANKTRANLIST"`
+	LedgerBalance   ledgerBalance     `xml:"LEDGERBAL"`
+}
+
+type creditCardAccount struct {
+	AccountID string `xml:"ACCTID"`
+}
+
+type transactionList struct {
+	DTStart      string        `xml:"DTSTART"`
+	DTEnd        string        `xml:"DTEND"`
+	Transactions []transaction `xml:"STMTTRN"`
+}
+
+type transaction struct {
+	Type     string `xml:"TRNTYPE"`
+	DTPosted string `xml:"DTPOSTED"`
+	DTUser   string `xml:"DTUSER,omitempty"`
+	Amount   string `xml:"TRNAMT"`
+	FITID    string `xml:"FITID"`
+	Name     string `xml:"NAME,omitempty"`
+	Memo     string `xml:"MEMO,omitempty"`
+}
+
+type ledgerBalance struct {
+	Amount string `xml:"BALAMT"`
+	DTAsOf string `xml:"DTASOF"`
+}
+
+func strconvID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,262 @@
+// Package ofx renders CAD accounts and transactions as OFX 2.2 documents, or
+// as QFX documents for Quicken and QuickBooks, so that aggregated data can be
+// imported into accounting tools.
+//
+// CAD accounts carry no account number or type, so statements use the CAD
+// account ID as the account number and take the account type from Statement.
+// Pending transactions are left out, since OFX statements only hold posted
+// transactions.
+package ofx
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+// Constants representing OFX bank account types
+const (
+	AccountChecking   = "CHECKING"
+	AccountSavings    = "SAVINGS"
+	AccountMoneyMrkt  = "MONEYMRKT"
+	AccountCreditLine = "CREDITLINE"
+)
+
+// header is the OFX 2.2 processing instruction
+const header = `<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>`
+
+// maxNameLength is the maximum length of a transaction's NAME
+const maxNameLength = 32
+
+// Statement is the statement of one account for a date range
+type Statement struct {
+	Account      intuit.Account
+	Transactions intuit.TransactionList
+	Start, End   intuit.Date
+
+	// CreditCard renders the statement as a credit card statement. If false,
+	// the statement is a credit card statement anyway if the transactions
+	// hold creditCardTransactions and no bankingTransactions.
+	CreditCard bool
+
+	// AccountType is the bank account type of bank statements. If empty,
+	// AccountChecking is used.
+	AccountType string
+
+	// BankID is the routing number of bank statements, if known
+	BankID string
+}
+
+// Options configures a document
+type Options struct {
+	// Org and FID identify the financial institution, e.g. from an
+	// intuit.OFXCrossReference. Some importers require them.
+	Org string
+	FID string
+
+	// IntuitBID, if set, makes the document a QFX document by adding the
+	// INTU.BID element Quicken and QuickBooks use to identify the
+	// institution
+	IntuitBID string
+
+	// Now is the server time of the document. If zero, time.Now is used.
+	Now time.Time
+}
+
+// Write writes an OFX document holding the statements
+func Write(w io.Writer, statements []Statement, opts Options) error {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	doc := document{
+		SignOn: signOnResponse{
+			Status:   okStatus,
+			DTServer: formatTime(now),
+			Language: "ENG",
+			IntuBID:  opts.IntuitBID,
+		},
+	}
+
+	if opts.Org != "" || opts.FID != "" {
+		doc.SignOn.FI = &fi{Org: opts.Org, FID: opts.FID}
+	}
+
+	for i, s := range statements {
+		txns, start, end := transactions(s, intuit.DateOf(now.UTC()))
+
+		list := transactionList{
+			DTStart:      formatDate(start),
+			DTEnd:        formatDate(end),
+			Transactions: txns,
+		}
+
+		asOf := s.Account.BalanceDate.Time()
+		if s.Account.BalanceDate.IsZero() {
+			asOf = now
+		}
+
+		balance := ledgerBalance{
+			Amount: s.Account.Balance.String(),
+			DTAsOf: formatTime(asOf),
+		}
+
+		currency := s.Account.Currency
+		if currency == "" {
+			currency = "USD"
+		}
+
+		accountID := strconvID(s.Account.ID)
+		trnUID := strconvID(int64(i + 1))
+
+		if isCreditCard(s) {
+			if doc.CreditCard == nil {
+				doc.CreditCard = &creditCardMessages{}
+			}
+
+			doc.CreditCard.Responses = append(doc.CreditCard.Responses, creditCardResponse{
+				TrnUID: trnUID,
+				Status: okStatus,
+				Statement: creditCardStatement{
+					Currency:        currency,
+					Account:         creditCardAccount{AccountID: accountID},
+					TransactionList: list,
+					LedgerBalance:   balance,
+				},
+			})
+
+			continue
+		}
+
+		accountType := s.AccountType
+		if accountType == "" {
+			accountType = AccountChecking
+		}
+
+		if doc.Bank == nil {
+			doc.Bank = &bankMessages{}
+		}
+
+		doc.Bank.Responses = append(doc.Bank.Responses, bankResponse{
+			TrnUID: trnUID,
+			Status: okStatus,
+			Statement: bankStatement{
+				Currency:        currency,
+				Account:         bankAccount{BankID: s.BankID, AccountID: accountID, AccountType: accountType},
+				TransactionList: list,
+				LedgerBalance:   balance,
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header+header+"\n"); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// transactions returns the statement's posted transactions in posted order,
+// along with the statement's dates. A zero start date defaults to the first
+// posted date, and a zero end date to the last posted date; both default to
+// `today` without transactions.
+func transactions(s Statement, today intuit.Date) ([]transaction, intuit.Date, intuit.Date) {
+	var all intuit.Transactions
+	for _, t := range s.Transactions.All() {
+		if !t.Pending {
+			all = append(all, t)
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].PostedDate.Before(all[j].PostedDate) })
+
+	start, end := s.Start, s.End
+	mapped := make([]transaction, len(all))
+	for i, t := range all {
+		mapped[i] = fromTransaction(t)
+
+		day := t.PostedDay()
+		if s.Start.IsZero() && (start.IsZero() || day.Before(start)) {
+			start = day
+		}
+		if s.End.IsZero() && day.After(end) {
+			end = day
+		}
+	}
+
+	if start.IsZero() {
+		start = today
+	}
+	if end.IsZero() {
+		end = today
+	}
+
+	return mapped, start, end
+}
+
+func fromTransaction(t intuit.Transaction) transaction {
+	txn := transaction{
+		Type:     "CREDIT",
+		DTPosted: formatTime(t.PostedDate.Time()),
+		Amount:   t.Amount.String(),
+		FITID:    t.Key(),
+		Name:     truncate(t.PayeeName, maxNameLength),
+	}
+
+	if t.Amount < 0 {
+		txn.Type = "DEBIT"
+	}
+
+	if !t.UserDate.IsZero() {
+		txn.DTUser = formatTime(t.UserDate.Time())
+	}
+
+	if category := t.Categorization.PrimaryCategory(); category != "" {
+		txn.Memo = category
+	}
+
+	return txn
+}
+
+func isCreditCard(s Statement) bool {
+	if s.CreditCard {
+		return true
+	}
+
+	_, card := s.Transactions["creditCardTransactions"]
+	_, bank := s.Transactions["bankingTransactions"]
+
+	return card && !bank
+}
+
+// formatTime formats a time as an OFX datetime in UTC
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:UTC]"
+}
+
+// formatDate formats a date as an OFX date
+func formatDate(d intuit.Date) string {
+	return d.In(time.UTC).Format("20060102")
+}
+
+// truncate shortens `s` to at most `n` characters
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n])
+}
@@ -0,0 +1,157 @@
+package ofx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/intuittest"
+)
+
+const testCustomer = "customer-1"
+
+// statements returns a statement of each fixture banking and credit card
+// account holding transactions in April 2014, fetched from a fake CAD server
+func statements(t *testing.T) []Statement {
+	t.Helper()
+
+	srv := intuittest.NewServer()
+	t.Cleanup(srv.Close)
+
+	if err := srv.Seed(testCustomer); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := srv.NewClient(testCustomer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accounts, err := client.GetCustomerAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := intuit.Date{Year: 2014, Month: 4, Day: 1}
+	end := intuit.Date{Year: 2014, Month: 4, Day: 30}
+
+	var statements []Statement
+	for _, account := range accounts {
+		if !account.IsActive() {
+			continue
+		}
+
+		list, err := client.AccountTransactionsForDates(account.ID, start, end)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, bank := list["bankingTransactions"]
+		_, card := list["creditCardTransactions"]
+		if bank || card {
+			statements = append(statements, Statement{Account: account, Transactions: list})
+		}
+	}
+
+	return statements
+}
+
+func fitIDs(list transactionList) []string {
+	ids := make([]string, len(list.Transactions))
+	for i, t := range list.Transactions {
+		ids[i] = t.FITID
+	}
+
+	return ids
+}
+
+func TestWrite(t *testing.T) {
+	opts := Options{
+		Org:       "CC Bank",
+		FID:       "1234",
+		IntuitBID: "5678",
+		Now:       time.Date(2014, 4, 25, 12, 0, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, statements(t), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), xml.Header+header+"\n") {
+		t.Fatalf("document starts with %q, want the XML and OFX headers", buf.String()[:80])
+	}
+
+	var doc document
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("decoding %s: %v", buf.String(), err)
+	}
+
+	if doc.SignOn.DTServer != "20140425120000.000[0:UTC]" || doc.SignOn.IntuBID != "5678" || doc.SignOn.FI == nil || doc.SignOn.FI.FID != "1234" {
+		t.Errorf("sign-on %+v, want the options' time, FI and INTU.BID", doc.SignOn)
+	}
+
+	if doc.Bank == nil || len(doc.Bank.Responses) != 1 {
+		t.Fatalf("bank messages %+v, want one statement", doc.Bank)
+	}
+	bank := doc.Bank.Responses[0].Statement
+	if bank.Account.AccountID != "400107846787" || bank.Account.AccountType != AccountChecking || bank.Currency != "USD" {
+		t.Errorf("bank account %+v in %s, want checking account 400107846787 in USD", bank.Account, bank.Currency)
+	}
+
+	// the pending transaction is left out, and the dates span the rest
+	list := bank.TransactionList
+	if got, want := strings.Join(fitIDs(list), " "), "INTUIT-BANK-0001 INTUIT-BANK-0002"; got != want {
+		t.Errorf("bank FITIDs %s, want %s", got, want)
+	}
+	if list.DTStart != "20140423" || list.DTEnd != "20140424" {
+		t.Errorf("bank statement from %s to %s, want 20140423 to 20140424", list.DTStart, list.DTEnd)
+	}
+
+	if doc.CreditCard == nil || len(doc.CreditCard.Responses) != 1 {
+		t.Fatalf("credit card messages %+v, want one statement", doc.CreditCard)
+	}
+	card := doc.CreditCard.Responses[0]
+	if card.TrnUID != "2" || len(card.Statement.TransactionList.Transactions) != 2 {
+		t.Errorf("credit card response %+v, want the second statement with 2 transactions", card)
+	}
+	for _, txn := range card.Statement.TransactionList.Transactions {
+		if (txn.Type == "DEBIT") != strings.HasPrefix(txn.Amount, "-") {
+			t.Errorf("transaction %s of %s is a %s", txn.FITID, txn.Amount, txn.Type)
+		}
+	}
+}
+
+func TestWriteEmpty(t *testing.T) {
+	statement := Statement{
+		Account:     intuit.Account{ID: 400107846787},
+		AccountType: AccountSavings,
+		Start:       intuit.Date{Year: 2014, Month: 4, Day: 1},
+	}
+	now := time.Date(2014, 4, 25, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []Statement{statement}, Options{Now: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc document
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.SignOn.FI != nil || doc.SignOn.IntuBID != "" || doc.CreditCard != nil {
+		t.Errorf("document %+v, want a plain OFX bank statement", doc)
+	}
+
+	bank := doc.Bank.Responses[0].Statement
+	if bank.Account.AccountType != AccountSavings {
+		t.Errorf("account type %s, want %s", bank.Account.AccountType, AccountSavings)
+	}
+	if list := bank.TransactionList; list.DTStart != "20140401" || list.DTEnd != "20140425" || len(list.Transactions) != 0 {
+		t.Errorf("transaction list %+v, want an empty one from 20140401 to 20140425", list)
+	}
+}
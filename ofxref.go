@@ -0,0 +1,128 @@
+package intuit
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OFXMapping maps an OFX financial institution, identified by its FID and ORG,
+// onto a CAD institution ID
+type OFXMapping struct {
+	FID           string
+	Org           string
+	InstitutionID int64
+}
+
+// OFXCrossReference maps OFX institutions onto CAD institution IDs, so that
+// applications migrating from direct OFX connections can carry their users'
+// existing banks over. It is safe for concurrent use.
+//
+// The package doesn't ship a table; Intuit doesn't publish the mapping through
+// the API, so callers load one they maintain with LoadOFXCrossReference or Add.
+type OFXCrossReference struct {
+	mu    sync.RWMutex
+	byFID map[string][]OFXMapping
+}
+
+// NewOFXCrossReference returns a cross-reference holding `mappings`
+func NewOFXCrossReference(mappings ...OFXMapping) *OFXCrossReference {
+	x := &OFXCrossReference{byFID: map[string][]OFXMapping{}}
+	for _, m := range mappings {
+		x.Add(m)
+	}
+
+	return x
+}
+
+// LoadOFXCrossReference reads mappings from CSV with the columns fid, org and
+// institution_id. A first row naming those columns is skipped.
+func LoadOFXCrossReference(r io.Reader) (*OFXCrossReference, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	x := NewOFXCrossReference()
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if line == 1 && strings.EqualFold(record[0], "fid") {
+			continue
+		}
+
+		institutionID, err := strconv.ParseInt(record[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid institution ID %q", line, record[2])
+		}
+
+		x.Add(OFXMapping{FID: record[0], Org: record[1], InstitutionID: institutionID})
+	}
+
+	return x, nil
+}
+
+// Add adds a mapping, replacing any existing mapping for the same FID and ORG
+func (x *OFXCrossReference) Add(m OFXMapping) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	mappings := x.byFID[m.FID]
+	for i := range mappings {
+		if strings.EqualFold(mappings[i].Org, m.Org) {
+			mappings[i] = m
+			return
+		}
+	}
+
+	x.byFID[m.FID] = append(mappings, m)
+}
+
+// Lookup returns the CAD institution ID for an OFX institution. FIDs aren't
+// unique across institutions, so `org` disambiguates them; if org is empty the
+// FID alone must identify a single mapping.
+func (x *OFXCrossReference) Lookup(fid, org string) (int64, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	mappings := x.byFID[fid]
+	if org == "" {
+		if len(mappings) == 1 {
+			return mappings[0].InstitutionID, true
+		}
+		return 0, false
+	}
+
+	for _, m := range mappings {
+		if strings.EqualFold(m.Org, org) {
+			return m.InstitutionID, true
+		}
+	}
+
+	return 0, false
+}
+
+// OFXInstitutions returns the OFX institutions mapped onto a CAD institution
+func (x *OFXCrossReference) OFXInstitutions(institutionID int64) []OFXMapping {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var found []OFXMapping
+	for _, mappings := range x.byFID {
+		for _, m := range mappings {
+			if m.InstitutionID == institutionID {
+				found = append(found, m)
+			}
+		}
+	}
+
+	return found
+}
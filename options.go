@@ -0,0 +1,103 @@
+package intuit
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"net/http"
+)
+
+// Option configures a client created with NewClientWithOptions
+type Option func(*Client)
+
+// WithConsumerCredentials sets the OAuth consumer key and secret
+func WithConsumerCredentials(key, secret string) Option {
+	return func(c *Client) {
+		c.ConsumerKey = key
+		c.ConsumerSecret = secret
+	}
+}
+
+// WithSAMLProvider sets the SAML identity provider ID used as the assertion
+// issuer
+func WithSAMLProvider(samlProviderID string) Option {
+	return func(c *Client) {
+		c.SAMLProviderID = samlProviderID
+	}
+}
+
+// WithPrivateKey sets the key used to sign SAML assertions
+func WithPrivateKey(key *rsa.PrivateKey) Option {
+	return func(c *Client) {
+		c.PrivateKey = key
+	}
+}
+
+// WithSigner sets a crypto.Signer used to sign SAML assertions in place of a
+// private key, e.g. one backed by an HSM or KMS
+func WithSigner(signer crypto.Signer) Option {
+	return func(c *Client) {
+		c.Signer = signer
+	}
+}
+
+// WithSigningCert embeds the certificate in the KeyInfo of signed assertions
+func WithSigningCert(cert *x509.Certificate) Option {
+	return func(c *Client) {
+		c.SigningCert = cert
+	}
+}
+
+// WithSigningHash sets the hash used to sign SAML assertions, e.g. crypto.SHA256
+func WithSigningHash(hashFunc crypto.Hash) Option {
+	return func(c *Client) {
+		c.SigningHash = hashFunc
+	}
+}
+
+// WithAssertionOptions sets the lifetime, clock skew and audience of the SAML
+// assertions the client creates
+func WithAssertionOptions(opts AssertionOptions) Option {
+	return func(c *Client) {
+		c.AssertionOptions = opts
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for API requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.HTTPClient = httpClient
+	}
+}
+
+// WithBaseURL sets the root URL of the CAD API, e.g. to point the client at a
+// mock server
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.BaseURL = baseURL
+	}
+}
+
+// NewClientWithOptions returns an initialized client configured entirely by
+// `opts`. Unlike NewClient it does not read the package-level Default*
+// credentials and does not cache the client, so several applications can be
+// used side by side. Only the HTTP client and endpoints have defaults
+// (DefaultHTTPClient and the Production environment).
+func NewClientWithOptions(customerID string, opts ...Option) (*Client, error) {
+	client := &Client{
+		CustomerID: customerID,
+		HTTPClient: DefaultHTTPClient,
+		TokenURL:   AccessTokenEndpoint,
+		BaseURL:    BaseURL,
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	if err := client.Init(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
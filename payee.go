@@ -0,0 +1,146 @@
+package intuit
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// PayeeRule rewrites payee names matching Pattern, replacing the matches with
+// Replacement as with regexp.ReplaceAllString
+type PayeeRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// PayeeNormalizer cleans up payee names, which institutions report with
+// inconsistent prefixes, reference numbers and locations. The zero value
+// applies every built-in cleanup; set the Keep fields to skip some.
+type PayeeNormalizer struct {
+	// KeepPOSPrefixes keeps prefixes such as "POS PURCHASE", "CHECKCARD" and
+	// payment processor markers such as "SQ *"
+	KeepPOSPrefixes bool
+
+	// KeepCardNumbers keeps masked card numbers, store and reference numbers
+	// such as "#1234" and "XXXX5678", phone numbers and dates such as "04/12"
+	KeepCardNumbers bool
+
+	// KeepLocation keeps a trailing city and US state, such as
+	// "SPRINGFIELD IL". CO is not taken for Colorado, since it usually
+	// abbreviates "company".
+	KeepLocation bool
+
+	// KeepCase keeps the case of the name. Otherwise names entirely in upper
+	// case are converted to title case.
+	KeepCase bool
+
+	// IgnoreNormalizedPayee makes Payee clean up the payee name reported by
+	// the institution even if Intuit provides a normalized payee name
+	IgnoreNormalizedPayee bool
+
+	// Rules are applied in order after the built-in cleanup
+	Rules []PayeeRule
+}
+
+var (
+	posPrefixPattern = regexp.MustCompile(`(?i)^(?:POS(?:\s+(?:PURCHASE|DEBIT|WITHDRAWAL))?|DEBIT\s+CARD\s+PURCHASE|CHECK\s*CARD(?:\s+PURCHASE)?|PURCHASE(?:\s+AUTHORIZED\s+ON\s+\d{1,2}/\d{1,2})?|RECURRING\s+PAYMENT|ACH\s+(?:DEBIT|CREDIT)|SQ\s*\*|TST\s*\*|PP\s*\*|PAYPAL\s*\*)\s*`)
+
+	cardNumberPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(?:X{2,}|\*{2,})\d{2,}\b`),
+		regexp.MustCompile(`(?i)\bCARD\s+\d{4}\b`),
+		regexp.MustCompile(`\(?\b\d{3}\)?[-. ]\d{3}[-. ]\d{4}\b`),
+		regexp.MustCompile(`#\s*\d+`),
+		regexp.MustCompile(`\b\d{4,}\b`),
+		regexp.MustCompile(`\b\d{1,2}/\d{1,2}\b`),
+	}
+
+	locationPattern = regexp.MustCompile(`(?:\s+-)?\s+(?:(?i:NEW|SAN|LOS|LAS|SANTA|ST\.?|SAINT|FORT|FT\.?|EL|PALM|LONG|SALT\s+LAKE|WEST|EAST|NORTH|SOUTH)\s+)?[A-Za-z.]+\s+(?:AL|AK|AZ|AR|CA|CT|DE|DC|FL|GA|HI|ID|IL|IN|IA|KS|KY|LA|ME|MD|MA|MI|MN|MS|MO|MT|NE|NV|NH|NJ|NM|NY|NC|ND|OH|OK|OR|PA|RI|SC|SD|TN|TX|UT|VT|VA|WA|WV|WI|WY)$`)
+
+	payeeSpacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Normalize cleans up a payee name
+func (n PayeeNormalizer) Normalize(name string) string {
+	name = collapsePayee(name)
+
+	if !n.KeepPOSPrefixes {
+		name = stripPOSPrefixes(name)
+	}
+
+	if !n.KeepCardNumbers {
+		for _, pattern := range cardNumberPatterns {
+			name = pattern.ReplaceAllString(name, " ")
+		}
+		name = collapsePayee(name)
+
+		// removing numbers can expose further prefixes, as in
+		// "CHECKCARD 0412 SQ *"
+		if !n.KeepPOSPrefixes {
+			name = stripPOSPrefixes(name)
+		}
+	}
+
+	if !n.KeepLocation {
+		// keep at least one word
+		if loc := locationPattern.FindStringIndex(name); loc != nil && strings.TrimSpace(name[:loc[0]]) != "" {
+			name = collapsePayee(name[:loc[0]])
+		}
+	}
+
+	if !n.KeepCase && strings.ToUpper(name) == name {
+		name = titleCase(name)
+	}
+
+	for _, rule := range n.Rules {
+		name = collapsePayee(rule.Pattern.ReplaceAllString(name, rule.Replacement))
+	}
+
+	return name
+}
+
+// Payee returns the cleaned up payee of the transaction: its normalized payee
+// name if Intuit provides one, and otherwise the payee name reported by the
+// institution
+func (n PayeeNormalizer) Payee(t Transaction) string {
+	name := t.Categorization.Common.NormalizedPayeeName
+	if name == "" || n.IgnoreNormalizedPayee {
+		name = t.PayeeName
+	}
+
+	return n.Normalize(name)
+}
+
+// stripPOSPrefixes removes stacked prefixes, as in "POS PURCHASE SQ *", while
+// leaving at least part of the name
+func stripPOSPrefixes(name string) string {
+	for {
+		stripped := collapsePayee(posPrefixPattern.ReplaceAllString(name, ""))
+		if stripped == name || stripped == "" {
+			return name
+		}
+		name = stripped
+	}
+}
+
+// collapsePayee collapses runs of spaces and trims spaces and dangling
+// punctuation from both ends
+func collapsePayee(name string) string {
+	name = payeeSpacePattern.ReplaceAllString(name, " ")
+
+	return strings.TrimFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-' || r == '*' || r == '#' || r == ',' || r == ':'
+	})
+}
+
+// titleCase upper-cases the first letter of each word and lower-cases the
+// rest
+func titleCase(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,368 @@
+package intuit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stage is one step of a Pipeline. Stages read and update the run they are
+// given; returning an error stops the run.
+type Stage func(ctx context.Context, run *PipelineRun) error
+
+// StageStats describes the execution of one stage of a pipeline run
+type StageStats struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// PipelineRun is the state shared by the stages of one run of a Pipeline
+type PipelineRun struct {
+	Client *Client
+
+	// Accounts are the accounts being processed, as fetched by FetchAccounts
+	// or set by an earlier stage
+	Accounts []Account
+
+	// Transactions holds the transactions of each account, keyed by account ID
+	Transactions map[int64][]Transaction
+
+	// Stats holds one entry per stage run so far
+	Stats []StageStats
+
+	// Result collects warnings from stages that drop or alter data
+	Result
+
+	limiter  RateLimiter
+	softFail bool
+
+	// memoMu guards memo, which holds values memoized for the run.
+	// memoFlight deduplicates concurrent computations of the same key.
+	memoMu     sync.Mutex
+	memo       map[string]interface{}
+	memoFlight flightGroup
+}
+
+// Memo returns the value memoized under key for this run, calling fn to
+// compute it the first time. Concurrent calls for the same key wait for one
+// call of fn and share its result. Errors are not memoized, so a later call
+// calls fn again. Stages use it to avoid repeating upstream calls, e.g. for
+// exchange rates, when several outputs need the same data.
+func (r *PipelineRun) Memo(key string, fn func() (interface{}, error)) (interface{}, error) {
+	if value, ok := r.memoized(key); ok {
+		return value, nil
+	}
+
+	return r.memoFlight.do(key, func() (interface{}, error) {
+		// a call that was in flight during the lookup above may have
+		// memoized the value since
+		if value, ok := r.memoized(key); ok {
+			return value, nil
+		}
+
+		value, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		r.memoMu.Lock()
+		defer r.memoMu.Unlock()
+
+		if r.memo == nil {
+			r.memo = map[string]interface{}{}
+		}
+		r.memo[key] = value
+
+		return value, nil
+	})
+}
+
+// memoized returns the value memoized under key, if any
+func (r *PipelineRun) memoized(key string) (interface{}, bool) {
+	r.memoMu.Lock()
+	defer r.memoMu.Unlock()
+
+	value, ok := r.memo[key]
+
+	return value, ok
+}
+
+// InstitutionDetails returns the details of an institution, fetching them at
+// most once per run
+func (r *PipelineRun) InstitutionDetails(ctx context.Context, institutionID int64) (*InstitutionDetails, error) {
+	value, err := r.Memo(fmt.Sprintf("institution/%d", institutionID), func() (interface{}, error) {
+		if err := r.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		return r.Client.InstitutionDetailsContext(ctx, institutionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*InstitutionDetails), nil
+}
+
+// LoginAccounts returns the accounts of a login, fetching them at most once
+// per run
+func (r *PipelineRun) LoginAccounts(ctx context.Context, loginID int64) ([]Account, error) {
+	value, err := r.Memo(fmt.Sprintf("login-accounts/%d", loginID), func() (interface{}, error) {
+		if err := r.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		return r.Client.GetLoginAccountsContext(ctx, loginID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.([]Account), nil
+}
+
+// Wait blocks until the pipeline's rate limiter permits another request.
+// Custom stages that call the API should call it before each request.
+func (r *PipelineRun) Wait(ctx context.Context) error {
+	if r.limiter == nil {
+		return ctx.Err()
+	}
+
+	return r.limiter.Wait(ctx)
+}
+
+type pipelineStage struct {
+	name     string
+	stage    Stage
+	optional bool
+}
+
+// Pipeline declares a multi-step flow, such as fetching a customer's accounts
+// and transactions, post-processing them and exporting the result, so that it
+// can be run for any number of customers. Stages run in the order they are
+// added; Then adds custom stages anywhere in the flow.
+//
+//	p := intuit.NewPipeline().
+//		FetchAccounts().
+//		FetchTransactions(start, end).
+//		Dedupe().
+//		Export(write)
+//	run, err := p.Run(ctx, client)
+type Pipeline struct {
+	stages []pipelineStage
+
+	// RateLimiter, if set, paces the requests made by the pipeline's stages,
+	// in addition to any rate limiter of the client
+	RateLimiter RateLimiter
+
+	// Projection selects the parts of each transaction FetchTransactions
+	// decodes. The zero value, ProjectFull, decodes them all.
+	Projection Projection
+
+	// OnStage, if set, is called after each stage with its stats, e.g. to
+	// export metrics
+	OnStage func(StageStats)
+
+	// SoftFail makes failures of optional stages, such as Enrich and
+	// Categorize, degrade the run instead of stopping it: the failure is
+	// recorded as a WarningDegraded and the run continues with the data it
+	// has. Enrich and Categorize skip only the accounts or transactions that
+	// fail.
+	SoftFail bool
+}
+
+// NewPipeline returns an empty pipeline
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Then adds a custom stage to the pipeline
+func (p *Pipeline) Then(name string, stage Stage) *Pipeline {
+	p.stages = append(p.stages, pipelineStage{name: name, stage: stage})
+	return p
+}
+
+// ThenOptional adds a custom stage whose failure only degrades the run when
+// SoftFail is set, e.g. one that depends on an optional subsystem
+func (p *Pipeline) ThenOptional(name string, stage Stage) *Pipeline {
+	p.stages = append(p.stages, pipelineStage{name: name, stage: stage, optional: true})
+	return p
+}
+
+// FetchAccounts adds a stage that fetches all of the customer's accounts. The
+// accounts are fetched at most once per run.
+func (p *Pipeline) FetchAccounts() *Pipeline {
+	return p.Then("fetch-accounts", func(ctx context.Context, run *PipelineRun) error {
+		value, err := run.Memo("accounts", func() (interface{}, error) {
+			if err := run.Wait(ctx); err != nil {
+				return nil, err
+			}
+
+			return run.Client.GetCustomerAccountsContext(ctx)
+		})
+		if err != nil {
+			return err
+		}
+
+		run.Accounts = append([]Account(nil), value.([]Account)...)
+
+		return nil
+	})
+}
+
+// Enrich adds a stage that calls fn with each account in turn, e.g. to attach
+// institution details held elsewhere. The time remaining before ctx's deadline
+// is divided evenly between the accounts; the stage fails with a
+// DeadlineBudgetError when an account's share is below the client's
+// MinStepBudget.
+func (p *Pipeline) Enrich(fn func(ctx context.Context, run *PipelineRun, account *Account) error) *Pipeline {
+	return p.ThenOptional("enrich", func(ctx context.Context, run *PipelineRun) error {
+		budget := newDeadlineBudget(ctx, len(run.Accounts), run.Client.minStepBudget())
+		for i := range run.Accounts {
+			name := fmt.Sprintf("enrich account %d", run.Accounts[i].ID)
+
+			stepCtx, cancel, budgetErr := budget.step(name)
+			if budgetErr != nil {
+				return budgetErr
+			}
+
+			err := budget.wrap(name, fn(stepCtx, run, &run.Accounts[i]))
+			cancel()
+			if err != nil {
+				err = fmt.Errorf("account %d: %w", run.Accounts[i].ID, err)
+				if !run.softFail || ctx.Err() != nil {
+					return err
+				}
+
+				run.Warn(WarningDegraded, "enrich: %v", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// FetchTransactions adds a stage that fetches the transactions of every
+// account between start and end, inclusive. Each account's transactions for a
+// range are fetched at most once per run.
+func (p *Pipeline) FetchTransactions(start, end Date) *Pipeline {
+	return p.Then("fetch-transactions", func(ctx context.Context, run *PipelineRun) error {
+		for _, account := range run.Accounts {
+			key := fmt.Sprintf("transactions/%d/%s/%s/%d", account.ID, start, end, p.Projection)
+			value, err := run.Memo(key, func() (interface{}, error) {
+				if err := run.Wait(ctx); err != nil {
+					return nil, err
+				}
+
+				return run.Client.AccountTransactionsProjectedContext(ctx, account.ID, start, end, p.Projection)
+			})
+			if err != nil {
+				return fmt.Errorf("account %d: %w", account.ID, err)
+			}
+
+			run.Transactions[account.ID] = value.(TransactionList).All()
+		}
+
+		return nil
+	})
+}
+
+// transactionAccountIDs returns the IDs of the accounts in run.Transactions in
+// ascending order, so that stages walk them, and record warnings, in the same
+// order on every run
+func (run *PipelineRun) transactionAccountIDs() []int64 {
+	ids := make([]int64, 0, len(run.Transactions))
+	for accountID := range run.Transactions {
+		ids = append(ids, accountID)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+// Dedupe adds a stage that drops duplicate transactions from each account with
+// DedupeTransactions, recording a warning for every transaction dropped
+func (p *Pipeline) Dedupe() *Pipeline {
+	return p.Then("dedupe", func(ctx context.Context, run *PipelineRun) error {
+		for _, accountID := range run.transactionAccountIDs() {
+			kept, report := DedupeTransactions(run.Transactions[accountID])
+			for _, d := range report.Duplicates {
+				run.Warn(WarningDuplicate, "account %d: dropped duplicate transaction %d of %d (%s)", accountID, d.Dropped.ID, d.Kept.ID, d.Reason)
+			}
+
+			run.Transactions[accountID] = kept
+		}
+
+		return nil
+	})
+}
+
+// Categorize adds a stage that calls fn with every transaction, so that it can
+// assign or correct categories
+func (p *Pipeline) Categorize(fn func(t *Transaction) error) *Pipeline {
+	return p.ThenOptional("categorize", func(ctx context.Context, run *PipelineRun) error {
+		for _, accountID := range run.transactionAccountIDs() {
+			txns := run.Transactions[accountID]
+			for i := range txns {
+				if err := fn(&txns[i]); err != nil {
+					err = fmt.Errorf("account %d, transaction %d: %w", accountID, txns[i].ID, err)
+					if !run.softFail {
+						return err
+					}
+
+					run.Warn(WarningDegraded, "categorize: %v", err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Export adds a stage that hands the run to fn, e.g. to write the accounts and
+// transactions out
+func (p *Pipeline) Export(fn func(ctx context.Context, run *PipelineRun) error) *Pipeline {
+	return p.Then("export", fn)
+}
+
+// Run runs the pipeline's stages in order for the client's customer. It stops
+// at the first stage that fails, returning the run so far along with an error
+// naming the stage.
+func (p *Pipeline) Run(ctx context.Context, c *Client) (*PipelineRun, error) {
+	run := &PipelineRun{
+		Client:       c,
+		Transactions: map[int64][]Transaction{},
+		limiter:      p.RateLimiter,
+		softFail:     p.SoftFail,
+	}
+
+	for _, s := range p.stages {
+		if err := ctx.Err(); err != nil {
+			return run, err
+		}
+
+		start := c.now()
+		err := s.stage(ctx, run)
+
+		stats := StageStats{Name: s.name, Duration: c.now().Sub(start), Err: err}
+		run.Stats = append(run.Stats, stats)
+		if p.OnStage != nil {
+			p.OnStage(stats)
+		}
+
+		if err != nil && s.optional && p.SoftFail && ctx.Err() == nil {
+			run.Warn(WarningDegraded, "stage %s failed: %v", s.name, err)
+			continue
+		}
+
+		if err != nil {
+			return run, fmt.Errorf("pipeline stage %s: %w", s.name, err)
+		}
+	}
+
+	return run, nil
+}
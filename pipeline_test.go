@@ -0,0 +1,66 @@
+package intuit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+// TestPipelineAccountOrder checks that Dedupe and Categorize walk the accounts
+// in ID order, so that their warnings come out the same on every run
+func TestPipelineAccountOrder(t *testing.T) {
+	_, client := newTestServer(t)
+
+	txns, err := client.AccountTransactionsForDates(testBankingAccount, testStart, testEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn := txns.All()[0]
+
+	accountIDs := []int64{400107846792, 400107846787, 400107846790, 400107846788, 400107846791, 400107846789}
+
+	var want []string
+	for _, accountID := range []int64{400107846787, 400107846788, 400107846789, 400107846790, 400107846791, 400107846792} {
+		want = append(want, fmt.Sprintf("account %d", accountID))
+	}
+
+	for i := 0; i < 10; i++ {
+		var visited []string
+
+		p := intuit.NewPipeline().
+			Then("seed", func(ctx context.Context, run *intuit.PipelineRun) error {
+				run.Transactions = map[int64][]intuit.Transaction{}
+				for _, accountID := range accountIDs {
+					t := txn
+					t.ID = accountID
+					run.Transactions[accountID] = []intuit.Transaction{t, t}
+				}
+				return nil
+			}).
+			Dedupe().
+			Categorize(func(t *intuit.Transaction) error {
+				visited = append(visited, fmt.Sprintf("account %d", t.ID))
+				return nil
+			})
+
+		run, err := p.Run(context.Background(), client)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if fmt.Sprint(visited) != fmt.Sprint(want) {
+			t.Fatalf("run %d: categorized %v, want %v", i, visited, want)
+		}
+
+		if len(run.Warnings) != len(want) {
+			t.Fatalf("run %d: got %d warnings, want %d", i, len(run.Warnings), len(want))
+		}
+		for j, warning := range run.Warnings {
+			if got := warning.Message[:len(want[j])]; got != want[j] {
+				t.Fatalf("run %d: warning %d is %q, want it for %s", i, j, warning.Message, want[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package intuit
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// Object identifiers of the PBES2 encryption of PKCS#8 private keys (RFC 8018)
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+// maxPBKDF2Iterations bounds the PBKDF2 iteration count of an encrypted key, so
+// that a crafted key cannot make decryption run for hours. OpenSSL uses 2048.
+const maxPBKDF2Iterations = 10000000
+
+// pbkdf2PRFs are the pseudorandom functions PBKDF2 may use
+var pbkdf2PRFs = []struct {
+	oid asn1.ObjectIdentifier
+	new func() hash.Hash
+}{
+	{oidHMACWithSHA1, sha1.New},
+	{oidHMACWithSHA224, sha256.New224},
+	{oidHMACWithSHA256, sha256.New},
+	{oidHMACWithSHA384, sha512.New384},
+	{oidHMACWithSHA512, sha512.New},
+}
+
+// pbes2Ciphers are the block ciphers, used in CBC mode, that PBES2 may use
+var pbes2Ciphers = []struct {
+	oid     asn1.ObjectIdentifier
+	keySize int
+	new     func(key []byte) (cipher.Block, error)
+}{
+	{oidAES128CBC, 16, aes.NewCipher},
+	{oidAES192CBC, 24, aes.NewCipher},
+	{oidAES256CBC, 32, aes.NewCipher},
+	{oidDESEDE3CBC, 24, des.NewTripleDESCipher},
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// decryptPKCS8 decrypts an "ENCRYPTED PRIVATE KEY" block, as written by
+// `openssl pkcs8 -topk8` and OpenSSL 3 by default, returning the DER of the
+// PKCS#8 private key. Only PBES2 with PBKDF2 and AES or 3DES in CBC mode is
+// supported.
+func decryptPKCS8(der, passphrase []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("bad encrypted private key: %w", err)
+	}
+
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported private key encryption %v; only PBES2 is supported", info.Algorithm.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("bad PBES2 parameters: %w", err)
+	}
+
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported key derivation function %v; only PBKDF2 is supported", params.KeyDerivationFunc.Algorithm)
+	}
+
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("bad PBKDF2 parameters: %w", err)
+	}
+	if kdf.IterationCount < 1 || kdf.IterationCount > maxPBKDF2Iterations {
+		return nil, fmt.Errorf("bad PBKDF2 iteration count %d", kdf.IterationCount)
+	}
+
+	// the PRF defaults to HMAC-SHA1
+	prf := sha1.New
+	if len(kdf.PRF.Algorithm) > 0 {
+		prf = nil
+		for _, p := range pbkdf2PRFs {
+			if kdf.PRF.Algorithm.Equal(p.oid) {
+				prf = p.new
+			}
+		}
+		if prf == nil {
+			return nil, fmt.Errorf("unsupported PBKDF2 pseudorandom function %v", kdf.PRF.Algorithm)
+		}
+	}
+
+	scheme := params.EncryptionScheme
+	for _, c := range pbes2Ciphers {
+		if !scheme.Algorithm.Equal(c.oid) {
+			continue
+		}
+
+		if kdf.KeyLength != 0 && kdf.KeyLength != c.keySize {
+			return nil, fmt.Errorf("bad PBKDF2 key length %d", kdf.KeyLength)
+		}
+
+		var iv []byte
+		if _, err := asn1.Unmarshal(scheme.Parameters.FullBytes, &iv); err != nil {
+			return nil, fmt.Errorf("bad encryption parameters: %w", err)
+		}
+
+		key, err := pbkdf2.Key(prf, string(passphrase), kdf.Salt, kdf.IterationCount, c.keySize)
+		if err != nil {
+			return nil, err
+		}
+
+		block, err := c.new(key)
+		if err != nil {
+			return nil, err
+		}
+
+		return decryptCBC(block, iv, info.EncryptedData)
+	}
+
+	return nil, fmt.Errorf("unsupported private key cipher %v", scheme.Algorithm)
+}
+
+// decryptCBC decrypts CBC-mode ciphertext and removes its PKCS#7 padding
+func decryptCBC(block cipher.Block, iv, ciphertext []byte) ([]byte, error) {
+	size := block.BlockSize()
+	if len(iv) != size {
+		return nil, fmt.Errorf("bad IV length %d", len(iv))
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%size != 0 {
+		return nil, errors.New("encrypted data is not a whole number of blocks")
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	// a wrong passphrase almost always leaves bad padding
+	padding := int(plaintext[len(plaintext)-1])
+	if padding < 1 || padding > size {
+		return nil, errors.New("incorrect passphrase")
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("incorrect passphrase")
+		}
+	}
+
+	return plaintext[:len(plaintext)-padding], nil
+}
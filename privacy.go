@@ -0,0 +1,46 @@
+package intuit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashCustomerID returns a stable pseudonym for a customer ID: the first 16
+// bytes of its HMAC-SHA256 under `key`, hex encoded. The same key always maps an
+// ID to the same pseudonym, so telemetry can still be correlated per customer.
+func HashCustomerID(key []byte, customerID string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(customerID))
+
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// WithPrivacyKey enables privacy mode: the customer ID is HMAC-hashed with `key`
+// wherever the client includes it in logs, metrics, audit records and error
+// messages
+func WithPrivacyKey(key []byte) Option {
+	return func(c *Client) {
+		c.PrivacyKey = key
+	}
+}
+
+// CustomerLabel returns the identifier the client uses for its customer in
+// telemetry and error messages: the customer ID itself, or its pseudonym from
+// HashCustomerID if privacy mode is enabled
+func (c *Client) CustomerLabel() string {
+	if len(c.PrivacyKey) == 0 {
+		return c.CustomerID
+	}
+
+	return HashCustomerID(c.PrivacyKey, c.CustomerID)
+}
+
+// customerLabel is like Client.CustomerLabel, for the manager's customers
+func (m *Manager) customerLabel(customerID string) string {
+	if len(m.PrivacyKey) == 0 {
+		return customerID
+	}
+
+	return HashCustomerID(m.PrivacyKey, customerID)
+}
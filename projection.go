@@ -0,0 +1,154 @@
+package intuit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Projection selects which parts of a transactions payload are decoded.
+// High-volume pollers that only need amounts and dates can skip the
+// categorization data, which is the bulk of each transaction.
+type Projection int
+
+// Constants representing transaction projections
+const (
+	// ProjectFull decodes every field, as AccountTransactions does
+	ProjectFull Projection = iota
+
+	// ProjectWithoutCategorization leaves Transaction.Categorization and
+	// Transaction.Details empty
+	ProjectWithoutCategorization
+)
+
+// AccountBalance is the balance portion of an Account
+type AccountBalance struct {
+	ID          int64     `json:"accountId"`
+	Balance     Money     `json:"balanceAmount"`
+	BalanceDate Timestamp `json:"balanceDate"`
+	Currency    string    `json:"currencyCode"`
+}
+
+// GetCustomerBalances returns the balance of every account of the customer,
+// decoding only the balance fields of each account
+func (c *Client) GetCustomerBalances(opts ...RequestOption) ([]AccountBalance, error) {
+	return c.GetCustomerBalancesContext(context.Background(), opts...)
+}
+
+// GetCustomerBalancesContext is like GetCustomerBalances, but sends the request
+// with ctx
+func (c *Client) GetCustomerBalancesContext(ctx context.Context, opts ...RequestOption) (_ []AccountBalance, err error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	ctx, span := c.startSpan(ctx, "GetCustomerBalances", SpanKindInternal)
+	defer func() { endSpan(span, err) }()
+
+	req, err := c.request("GET", "/accounts", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	var payload struct {
+		Accounts []AccountBalance `json:"accounts"`
+	}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	return payload.Accounts, nil
+}
+
+// AccountTransactionsProjected is like AccountTransactionsForDates, but decodes
+// only the parts of each transaction selected by `projection`
+func (c *Client) AccountTransactionsProjected(accountID int64, start, end Date, projection Projection, opts ...RequestOption) (TransactionList, error) {
+	return c.AccountTransactionsProjectedContext(context.Background(), accountID, start, end, projection, opts...)
+}
+
+// AccountTransactionsProjectedContext is like AccountTransactionsProjected, but
+// sends the request with ctx
+func (c *Client) AccountTransactionsProjectedContext(ctx context.Context, accountID int64, start, end Date, projection Projection, opts ...RequestOption) (_ TransactionList, err error) {
+	if projection == ProjectFull {
+		return c.AccountTransactionsForDatesContext(ctx, accountID, start, end, opts...)
+	}
+
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	ctx, span := c.startSpan(ctx, "AccountTransactionsProjected", SpanKindInternal, traceTransactions(accountID, start, end)...)
+	defer func() { endSpan(span, err) }()
+
+	req, err := c.transactionsRequest(accountID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var payload map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	list := make(TransactionList)
+	for key, rawMessage := range payload {
+		if !includesTransactionKey(key) {
+			continue
+		}
+
+		txns, err := decodeUncategorized(rawMessage)
+		if err != nil {
+			return nil, err
+		}
+
+		list[key] = txns
+	}
+
+	return list, nil
+}
+
+// uncategorizedTransaction decodes into an embedded Transaction, except that
+// its categorization field shadows the embedded one and discards the data
+type uncategorizedTransaction struct {
+	*Transaction
+	Categorization struct{} `json:"categorization"`
+}
+
+func decodeUncategorized(data []byte) ([]Transaction, error) {
+	var raw []uncategorizedTransaction
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	txns := make([]Transaction, len(raw))
+	for i := range raw {
+		if raw[i].Transaction != nil {
+			txns[i] = *raw[i].Transaction
+		}
+	}
+
+	return txns, nil
+}
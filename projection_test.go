@@ -0,0 +1,89 @@
+package intuit_test
+
+import (
+	"context"
+	"testing"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+func TestGetCustomerBalancesContext(t *testing.T) {
+	_, client := newTestServer(t)
+
+	balances, err := client.GetCustomerBalancesContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(balances) != 7 {
+		t.Fatalf("got %d balances, want 7", len(balances))
+	}
+	if got := balances[0]; got.ID != testBankingAccount || got.Balance.String() != "1520.35" {
+		t.Errorf("first balance %+v, want account %d with 1520.35", got, testBankingAccount)
+	}
+}
+
+func TestAccountTransactionsProjectedContext(t *testing.T) {
+	tests := []struct {
+		projection  intuit.Projection
+		wantPayee   string
+		wantContext int
+	}{
+		{intuit.ProjectFull, "Acme Corp", 1},
+		{intuit.ProjectWithoutCategorization, "", 0},
+	}
+
+	_, client := newTestServer(t)
+
+	for _, test := range tests {
+		list, err := client.AccountTransactionsProjectedContext(context.Background(), testBankingAccount, testStart, testEnd, test.projection)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		txns := list["bankingTransactions"]
+		if len(txns) != 3 {
+			t.Fatalf("projection %d: got %d transactions, want 3", test.projection, len(txns))
+		}
+
+		payroll := txns[1]
+		if payroll.ID != 900002 || payroll.Amount.String() != "2100.00" {
+			t.Errorf("projection %d: transaction %+v, want 900002 for 2100.00", test.projection, payroll)
+		}
+		if got := payroll.Categorization.Common.NormalizedPayeeName; got != test.wantPayee {
+			t.Errorf("projection %d: normalized payee %q, want %q", test.projection, got, test.wantPayee)
+		}
+		if got := len(payroll.Categorization.Context); got != test.wantContext {
+			t.Errorf("projection %d: %d categorization contexts, want %d", test.projection, got, test.wantContext)
+		}
+	}
+}
+
+func TestAllTransactionsProjected(t *testing.T) {
+	_, client := newTestServer(t)
+
+	const creditAccount int64 = 400107846789
+
+	results, err := client.AllTransactionsProjected(context.Background(), []int64{testBankingAccount, creditAccount}, testStart, testEnd, 2, intuit.ProjectWithoutCategorization)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for accountID, want := range map[int64]int{testBankingAccount: 3, creditAccount: 2} {
+		result := results[accountID]
+		if result.Err != nil {
+			t.Errorf("account %d: %v", accountID, result.Err)
+			continue
+		}
+
+		txns := result.Transactions.All()
+		if len(txns) != want {
+			t.Errorf("account %d: got %d transactions, want %d", accountID, len(txns), want)
+		}
+		for _, txn := range txns {
+			if txn.Categorization.Common.NormalizedPayeeName != "" {
+				t.Errorf("account %d: transaction %d is categorized", accountID, txn.ID)
+			}
+		}
+	}
+}
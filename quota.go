@@ -0,0 +1,122 @@
+package intuit
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultQuotaThresholds are the fractions of a quota at which warnings are
+// reported if no thresholds are given
+var DefaultQuotaThresholds = []float64{0.8, 0.95}
+
+// QuotaWarning is reported when usage within a quota window crosses one of the
+// quota's thresholds
+type QuotaWarning struct {
+	Threshold   float64
+	Used        int
+	Limit       int
+	WindowStart time.Time
+	Window      time.Duration
+}
+
+// Quota counts requests against a limit over fixed windows and reports a
+// warning the first time usage crosses each threshold in a window, so that
+// operators hear about it before Intuit starts throttling. A Quota may be
+// shared by several clients of the same application.
+type Quota struct {
+	Limit      int
+	Window     time.Duration
+	Thresholds []float64
+
+	// OnWarning is called synchronously from the request path, so it should
+	// return quickly
+	OnWarning func(QuotaWarning)
+
+	mu          sync.Mutex
+	windowStart time.Time
+	used        int
+	warned      int
+}
+
+// NewQuota returns a quota of `limit` requests per `window`. If no thresholds
+// are given, DefaultQuotaThresholds are used.
+func NewQuota(limit int, window time.Duration, onWarning func(QuotaWarning), thresholds ...float64) *Quota {
+	if len(thresholds) == 0 {
+		thresholds = DefaultQuotaThresholds
+	}
+
+	sorted := append([]float64(nil), thresholds...)
+	sort.Float64s(sorted)
+
+	return &Quota{
+		Limit:      limit,
+		Window:     window,
+		Thresholds: sorted,
+		OnWarning:  onWarning,
+	}
+}
+
+// WithQuota counts the client's requests against `quota`
+func WithQuota(quota *Quota) Option {
+	return func(c *Client) {
+		c.Quota = quota
+	}
+}
+
+// Record counts one request against the quota, reporting a warning if it
+// crosses a threshold
+func (q *Quota) Record() {
+	warnings := q.record(time.Now())
+
+	if q.OnWarning == nil {
+		return
+	}
+
+	for _, warning := range warnings {
+		q.OnWarning(warning)
+	}
+}
+
+// Usage returns the number of requests counted in the current window
+func (q *Quota) Usage() (used, limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if time.Since(q.windowStart) >= q.Window {
+		return 0, q.Limit
+	}
+
+	return q.used, q.Limit
+}
+
+func (q *Quota) record(now time.Time) []QuotaWarning {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if now.Sub(q.windowStart) >= q.Window {
+		q.windowStart = now
+		q.used = 0
+		q.warned = 0
+	}
+
+	q.used++
+
+	if q.Limit <= 0 {
+		return nil
+	}
+
+	var warnings []QuotaWarning
+	for q.warned < len(q.Thresholds) && float64(q.used) >= q.Thresholds[q.warned]*float64(q.Limit) {
+		warnings = append(warnings, QuotaWarning{
+			Threshold:   q.Thresholds[q.warned],
+			Used:        q.used,
+			Limit:       q.Limit,
+			WindowStart: q.windowStart,
+			Window:      q.Window,
+		})
+		q.warned++
+	}
+
+	return warnings
+}
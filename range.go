@@ -0,0 +1,202 @@
+package intuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AccountTransactionsRange returns the account's transactions between start and
+// end, inclusive, fetching them in windows of `chunk`, rounded down to whole
+// days, because CAD rejects or truncates long date ranges. A chunk shorter than
+// a day is an error, since CAD filters by date. A zero end date means today.
+// The windows are merged and transactions repeated across windows are dropped,
+// identified by their institution transaction ID, or by their ID if they have
+// none; transactions with neither are all kept.
+func (c *Client) AccountTransactionsRange(accountID int64, start, end Date, chunk time.Duration) (TransactionList, error) {
+	return c.AccountTransactionsRangeContext(context.Background(), accountID, start, end, chunk)
+}
+
+// AccountTransactionsRangeContext is like AccountTransactionsRange, but sends
+// the requests with ctx. When the windows are fetched one at a time, the time
+// remaining before ctx's deadline is divided evenly between them, and a window
+// starved of time fails with a DeadlineBudgetError. When they are fetched
+// concurrently and ctx is cancelled, the windows fetched so far are merged and
+// returned with a *CancelledError counting windows.
+func (c *Client) AccountTransactionsRangeContext(ctx context.Context, accountID int64, start, end Date, chunk time.Duration) (list TransactionList, err error) {
+	if end.IsZero() {
+		end = DateOf(c.now().UTC())
+	}
+
+	ctx, span := c.startSpan(ctx, "AccountTransactionsRange", SpanKindInternal, traceTransactions(accountID, start, end)...)
+	defer func() { endSpan(span, err) }()
+
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date %s is before start date %s", end, start)
+	}
+
+	if chunk < 24*time.Hour {
+		return nil, fmt.Errorf("chunk %s is shorter than a day", chunk)
+	}
+
+	windows := dateWindows(start, end, int(chunk/(24*time.Hour)))
+	lists := make([]TransactionList, len(windows))
+
+	if c.RangeConcurrency <= 1 {
+		budget := newDeadlineBudget(ctx, len(windows), c.minStepBudget())
+		for i, w := range windows {
+			name := fmt.Sprintf("transactions %s to %s", w[0], w[1])
+
+			stepCtx, cancel, budgetErr := budget.step(name)
+			if budgetErr != nil {
+				return nil, budgetErr
+			}
+
+			list, err := c.AccountTransactionsForDatesContext(stepCtx, accountID, w[0], w[1])
+			cancel()
+			if err != nil {
+				return nil, budget.wrap(name, err)
+			}
+
+			lists[i] = list
+		}
+
+		return mergeTransactionLists(lists), nil
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		done     = make([]bool, len(windows))
+	)
+
+	work := make(chan int)
+	for n := 0; n < c.RangeConcurrency && n < len(windows); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range work {
+				list, err := c.AccountTransactionsForDatesContext(ctx, accountID, windows[i][0], windows[i][1])
+				if err != nil && parent.Err() != nil && errors.Is(err, parent.Err()) {
+					// cut short by the cancellation; counted as remaining
+					continue
+				}
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("transactions %s to %s: %w", windows[i][0], windows[i][1], err)
+						cancel()
+					})
+					continue
+				}
+
+				lists[i], done[i] = list, true
+			}
+		}()
+	}
+
+	for i := range windows {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := parent.Err(); err != nil {
+		var completed int
+		for _, ok := range done {
+			if ok {
+				completed++
+			}
+		}
+
+		if completed < len(windows) {
+			return mergeTransactionLists(lists), &CancelledError{
+				Completed: completed,
+				Remaining: len(windows) - completed,
+				Err:       err,
+			}
+		}
+	}
+
+	return mergeTransactionLists(lists), nil
+}
+
+// WithRangeConcurrency sets the number of windows AccountTransactionsRange
+// fetches at once
+func WithRangeConcurrency(n int) Option {
+	return func(c *Client) {
+		c.RangeConcurrency = n
+	}
+}
+
+// dateWindows splits the range from start to end, inclusive, into consecutive
+// windows of at most `days` days
+func dateWindows(start, end Date, days int) [][2]Date {
+	if days < 1 {
+		days = 1
+	}
+
+	var windows [][2]Date
+	for from := start; !from.After(end); from = from.AddDays(days) {
+		to := from.AddDays(days - 1)
+		if to.After(end) {
+			to = end
+		}
+
+		windows = append(windows, [2]Date{from, to})
+	}
+
+	return windows
+}
+
+// mergeTransactionLists merges the lists in order, keeping the first of any
+// transactions under the same key with the same institution transaction ID, or
+// the same ID if the institution didn't provide one. Transactions with neither
+// can't be told apart from genuine repeats, so they are all kept.
+func mergeTransactionLists(lists []TransactionList) TransactionList {
+	merged := make(TransactionList)
+	seen := map[string]bool{}
+
+	for _, list := range lists {
+		for key, txns := range list {
+			if _, ok := merged[key]; !ok {
+				merged[key] = []Transaction{}
+			}
+
+			for _, t := range txns {
+				if t.InstitutionTransactionID == "" && t.ID == 0 {
+					merged[key] = append(merged[key], t)
+					continue
+				}
+
+				id := key + "/" + t.InstitutionTransactionID
+				if t.InstitutionTransactionID == "" {
+					id = fmt.Sprintf("%s/#%d", key, t.ID)
+				}
+
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+
+				merged[key] = append(merged[key], t)
+			}
+		}
+	}
+
+	return merged
+}
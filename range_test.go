@@ -0,0 +1,152 @@
+package intuit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+func TestAccountTransactionsRangeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the windows from 2014-04-24 hang until the request is cancelled
+	requested := make(chan struct{}, 2)
+	hang := func(next http.RoundTripper) http.RoundTripper {
+		return intuit.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Query().Get("txnStartDate") < "2014-04-24" {
+				return next.RoundTrip(req)
+			}
+
+			requested <- struct{}{}
+			<-req.Context().Done()
+
+			return nil, req.Context().Err()
+		})
+	}
+
+	_, client := newTestServer(t, intuit.WithMiddleware(hang), intuit.WithRangeConcurrency(2))
+
+	go func() {
+		// both workers are on the hanging windows, so the others are done
+		<-requested
+		<-requested
+		cancel()
+	}()
+
+	start := intuit.Date{Year: 2014, Month: 4, Day: 20}
+	end := intuit.Date{Year: 2014, Month: 4, Day: 25}
+	list, err := client.AccountTransactionsRangeContext(ctx, testBankingAccount, start, end, 24*time.Hour)
+
+	var cancelled *intuit.CancelledError
+	if !errors.As(err, &cancelled) {
+		t.Fatalf("AccountTransactionsRangeContext() error = %v, want a CancelledError", err)
+	}
+	if cancelled.Completed != 4 || cancelled.Remaining != 2 || !errors.Is(err, context.Canceled) {
+		t.Errorf("error %v, want 4 of 6 windows complete", err)
+	}
+
+	if want := []int64{900001}; !equalIDs(transactionIDs(list.All()), want) {
+		t.Errorf("partial result IDs %v, want %v", transactionIDs(list.All()), want)
+	}
+}
+
+func TestAccountTransactionsRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		chunk       time.Duration
+		concurrency int
+		windows     int
+	}{
+		{"daily", 24 * time.Hour, 1, 30},
+		{"weekly", 7 * 24 * time.Hour, 1, 5},
+		{"weekly concurrent", 7 * 24 * time.Hour, 3, 5},
+		{"partial days rounded down", 36 * time.Hour, 2, 30},
+		{"one window", 60 * 24 * time.Hour, 1, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := make(chan string, 64)
+			count := func(next http.RoundTripper) http.RoundTripper {
+				return intuit.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					if start := req.URL.Query().Get("txnStartDate"); start != "" {
+						requests <- start
+					}
+					return next.RoundTrip(req)
+				})
+			}
+
+			_, client := newTestServer(t, intuit.WithMiddleware(count), intuit.WithRangeConcurrency(test.concurrency))
+
+			list, err := client.AccountTransactionsRange(testBankingAccount, testStart, testEnd, test.chunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := []int64{900001, 900002, 900003}; !equalIDs(transactionIDs(list.All()), want) {
+				t.Errorf("got IDs %v, want %v", transactionIDs(list.All()), want)
+			}
+			if len(requests) != test.windows {
+				t.Errorf("sent %d requests, want %d windows", len(requests), test.windows)
+			}
+		})
+	}
+}
+
+func TestAccountTransactionsRangeShortChunk(t *testing.T) {
+	_, client := newTestServer(t)
+
+	if _, err := client.AccountTransactionsRange(testBankingAccount, testStart, testEnd, 12*time.Hour); err == nil {
+		t.Error("AccountTransactionsRange() with a chunk under a day succeeded")
+	}
+}
+
+// TestAccountTransactionsRangeRepeats checks how transactions returned by more
+// than one window are merged
+func TestAccountTransactionsRangeRepeats(t *testing.T) {
+	// every window reaches back to the start of the range, so each repeats
+	// the transactions of the windows before it
+	widen := func(next http.RoundTripper) http.RoundTripper {
+		return intuit.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			query.Set("txnStartDate", testStart.String())
+			req.URL.RawQuery = query.Encode()
+
+			return next.RoundTrip(req)
+		})
+	}
+
+	srv, client := newTestServer(t, intuit.WithMiddleware(widen))
+
+	fixture, err := client.AccountTransactionsForDates(testBankingAccount, testStart, testEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// two transactions without any ID, on the first day of the range
+	anonymous := fixture.All()[0]
+	anonymous.ID, anonymous.InstitutionTransactionID = 0, ""
+	anonymous.PostedDate = intuit.Timestamp(time.Date(2014, 4, 1, 12, 0, 0, 0, time.UTC))
+	srv.AddTransactions(testCustomer, testBankingAccount, "bankingTransactions", anonymous, anonymous)
+
+	list, err := client.AccountTransactionsRange(testBankingAccount, testStart, testEnd, 10*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the three windows return the anonymous transactions three times each,
+	// which are all kept, and the fixture transactions once or twice, which
+	// are kept once
+	want := []int64{0, 0, 0, 0, 0, 0, 900001, 900002, 900003}
+	got := transactionIDs(list.All())
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	if !equalIDs(got, want) {
+		t.Errorf("got IDs %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+package intuit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter paces outgoing API requests. Wait blocks until a request may be
+// sent, or returns an error if ctx is done first. Implementations must be safe
+// for concurrent use.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is a RateLimiter allowing a sustained rate of requests per second
+// with bursts of up to a fixed size
+type TokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a full bucket allowing `rps` requests per second with
+// bursts of up to `burst` requests. It panics if rps is not positive or burst
+// is less than 1.
+func NewTokenBucket(rps float64, burst int) *TokenBucket {
+	if !(rps > 0) {
+		panic(fmt.Sprintf("intuit: NewTokenBucket: rps must be positive, not %v", rps))
+	}
+	if burst < 1 {
+		panic(fmt.Sprintf("intuit: NewTokenBucket: burst must be at least 1, not %d", burst))
+	}
+
+	return &TokenBucket{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available and takes it
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		wait, ok := b.take()
+		if ok {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// take takes a token if one is available. Otherwise it returns how long until
+// one will be.
+func (b *TokenBucket) take() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second)), false
+}
+
+// WithRateLimit limits the client to `rps` requests per second with bursts of up
+// to `burst` requests. Retries count against the limit. It panics if rps is not
+// positive or burst is less than 1.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		c.RateLimiter = NewTokenBucket(rps, burst)
+	}
+}
+
+// WithRateLimiter paces the client's requests with `limiter`, which may be
+// shared between clients
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(c *Client) {
+		c.RateLimiter = limiter
+	}
+}
+
+// WithMaxConcurrency limits the number of requests the client has in flight at
+// once to `n`; see also WithAdaptiveConcurrency
+func WithMaxConcurrency(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.concurrency = make(semaphore, n)
+		}
+	}
+}
+
+// throttle waits for the client's rate limiter and a concurrency slot. The
+// returned function releases the slot and must be called with the outcome of
+// the request.
+func (c *Client) throttle(ctx context.Context) (func(*http.Response, error), error) {
+	if c.RateLimiter != nil {
+		if err := c.RateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.concurrency == nil {
+		return func(*http.Response, error) {}, nil
+	}
+
+	return c.concurrency.acquire(ctx)
+}
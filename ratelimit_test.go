@@ -0,0 +1,93 @@
+package intuit
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewTokenBucketPanics(t *testing.T) {
+	tests := []struct {
+		name  string
+		rps   float64
+		burst int
+	}{
+		{"zero rps", 0, 1},
+		{"negative rps", -1, 1},
+		{"NaN rps", math.NaN(), 1},
+		{"zero burst", 1, 0},
+		{"negative burst", 1, -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewTokenBucket(%v, %d) did not panic", test.rps, test.burst)
+				}
+			}()
+
+			NewTokenBucket(test.rps, test.burst)
+		})
+	}
+}
+
+func TestTokenBucketTake(t *testing.T) {
+	tests := []struct {
+		name  string
+		rps   float64
+		burst int
+	}{
+		{"burst of one", 10, 1},
+		{"burst of three", 10, 3},
+		{"slow", 0.5, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := NewTokenBucket(test.rps, test.burst)
+
+			for i := 0; i < test.burst; i++ {
+				if _, ok := b.take(); !ok {
+					t.Fatalf("take() %d of a full bucket of %d failed", i+1, test.burst)
+				}
+			}
+
+			wait, ok := b.take()
+			if ok {
+				t.Fatal("take() of an empty bucket succeeded")
+			}
+
+			want := time.Duration(float64(time.Second) / test.rps)
+			if wait < want*9/10 || wait > want {
+				t.Errorf("take() wait = %s, want about %s", wait, want)
+			}
+		})
+	}
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	b := NewTokenBucket(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := b.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	// two tokens are free and the next two take 10ms each
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("four waits took %s, want at least 20ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	slow := NewTokenBucket(0.001, 1)
+	slow.Wait(ctx)
+	if err := slow.Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait() with a cancelled context error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,304 @@
+package intuit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Defaults of RefreshCoordinator
+const (
+	DefaultRefreshConcurrency  = 4
+	DefaultRefreshPollInterval = time.Second * 30
+	DefaultRefreshTimeout      = time.Minute * 10
+)
+
+// RefreshResult is the outcome of refreshing one customer's accounts
+type RefreshResult struct {
+	CustomerID string
+
+	// Accounts are the customer's accounts as of the last poll
+	Accounts []Account
+
+	// Pending lists the accounts whose aggregation had not finished when the
+	// coordinator stopped polling
+	Pending []int64
+
+	// Held lists the accounts whose logins were not refreshed because the
+	// coordinator's policy holds them back: they await user action, or failed
+	// too recently to be retried yet
+	Held []int64
+
+	// Err is set if the customer's refresh could not be started or polled
+	Err error
+}
+
+// Failed returns the accounts whose aggregation finished with an error status
+func (r RefreshResult) Failed() []Account {
+	var failed []Account
+	for _, account := range r.Accounts {
+		if account.AggrStatusCode.IsError() {
+			failed = append(failed, account)
+		}
+	}
+
+	return failed
+}
+
+// RefreshCoordinator refreshes the accounts of many customers, e.g. for a
+// nightly sync. For each customer it acquires a client from the manager,
+// refreshes every login, and polls the customer's accounts until every account
+// has been aggregated since the refresh started, or Timeout passes. Logins
+// that the Policy holds back are not refreshed.
+type RefreshCoordinator struct {
+	Manager *Manager
+
+	// Concurrency is the number of customers refreshed at once. If zero,
+	// DefaultRefreshConcurrency is used.
+	Concurrency int
+
+	// RateLimiter, if set, paces the refresh requests of all customers, in
+	// addition to the rate limiting of the manager's clients
+	RateLimiter RateLimiter
+
+	// PollInterval is the delay between polls of a customer's accounts. If
+	// zero, DefaultRefreshPollInterval is used.
+	PollInterval time.Duration
+
+	// Timeout bounds the polling of each customer. If zero,
+	// DefaultRefreshTimeout is used.
+	Timeout time.Duration
+
+	// Clock times the polls. If nil, the manager's clock is used.
+	Clock Clock
+
+	// Policy decides which logins are refreshed: one with an account whose
+	// rule is ReaggregationUserAction is not, nor is one with an account
+	// whose rule is ReaggregationRetry until the rule's delay has passed since
+	// the account's last attempt. Scheduler also uses it to judge the outcome.
+	// If nil, DefaultReaggregationPolicy is used.
+	Policy *ReaggregationPolicy
+}
+
+// NewRefreshCoordinator returns a coordinator for the manager's customers with
+// the default settings
+func NewRefreshCoordinator(m *Manager) *RefreshCoordinator {
+	return &RefreshCoordinator{Manager: m}
+}
+
+// Run refreshes the customers and sends one result per customer on the
+// returned channel, which is closed once every customer is done. If ctx is
+// cancelled, customers not yet started are skipped and those in progress
+// report their accounts as of the last poll with a *CancelledError counting
+// accounts. The channel must be drained.
+func (rc *RefreshCoordinator) Run(ctx context.Context, customerIDs []string) <-chan RefreshResult {
+	workers := rc.Concurrency
+	if workers <= 0 {
+		workers = DefaultRefreshConcurrency
+	}
+
+	results := make(chan RefreshResult)
+	work := make(chan string)
+
+	var wg sync.WaitGroup
+	for n := 0; n < workers && n < len(customerIDs); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for customerID := range work {
+				results <- rc.refresh(ctx, customerID)
+			}
+		}()
+	}
+
+	go func() {
+	schedule:
+		for _, customerID := range customerIDs {
+			select {
+			case work <- customerID:
+			case <-ctx.Done():
+				break schedule
+			}
+		}
+		close(work)
+
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// refresh refreshes one customer's logins and polls their accounts
+func (rc *RefreshCoordinator) refresh(ctx context.Context, customerID string) RefreshResult {
+	result := RefreshResult{CustomerID: customerID}
+
+	var (
+		accounts []Account
+		started  time.Time
+		held     map[int64]bool
+	)
+
+	// fail records err. A refresh cut short by ctx reports a CancelledError
+	// counting the active accounts aggregated since it started and those
+	// still pending.
+	fail := func(err error) RefreshResult {
+		result.Err = err
+		if ctx.Err() == nil || !errors.Is(err, ctx.Err()) {
+			return result
+		}
+
+		result.Accounts = accounts
+		result.Pending, result.Held = pendingAccounts(accounts, started, held)
+
+		var completed int
+		for _, account := range accounts {
+			if account.IsActive() {
+				completed++
+			}
+		}
+		completed -= len(result.Pending) + len(result.Held)
+
+		result.Err = &CancelledError{Completed: completed, Remaining: len(result.Pending), Err: ctx.Err()}
+
+		return result
+	}
+
+	client, err := rc.Manager.ClientFor(customerID)
+	if err != nil {
+		return fail(err)
+	}
+
+	accounts, err = client.GetCustomerAccountsContext(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	clock := rc.clock()
+
+	// CAD timestamps aggregation attempts to the second
+	started = clock.Now().Truncate(time.Second)
+
+	held = heldLogins(accounts, rc.policy(), started)
+
+	refreshed := map[int64]bool{}
+	for _, account := range accounts {
+		if refreshed[account.LoginID] || held[account.LoginID] {
+			continue
+		}
+		refreshed[account.LoginID] = true
+
+		if rc.RateLimiter != nil {
+			if err := rc.RateLimiter.Wait(ctx); err != nil {
+				result.Accounts = accounts
+				return fail(err)
+			}
+		}
+
+		if _, err := client.RefreshLoginContext(ctx, account.LoginID); err != nil {
+			result.Accounts = accounts
+			return fail(err)
+		}
+	}
+
+	timeout := rc.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRefreshTimeout
+	}
+
+	interval := rc.PollInterval
+	if interval <= 0 {
+		interval = DefaultRefreshPollInterval
+	}
+
+	deadline := started.Add(timeout)
+	for {
+		polled, err := client.GetCustomerAccountsContext(ctx)
+		if err != nil {
+			return fail(err)
+		}
+
+		accounts = polled
+		result.Accounts = accounts
+		result.Pending, result.Held = pendingAccounts(accounts, started, held)
+		if len(result.Pending) == 0 || !clock.Now().Before(deadline) {
+			return result
+		}
+
+		if err := sleepClock(ctx, clock, interval); err != nil {
+			return fail(err)
+		}
+	}
+}
+
+func (rc *RefreshCoordinator) clock() Clock {
+	if rc.Clock != nil {
+		return rc.Clock
+	}
+
+	return clockOrSystem(rc.Manager.Clock)
+}
+
+func (rc *RefreshCoordinator) policy() ReaggregationPolicy {
+	if rc.Policy != nil {
+		return *rc.Policy
+	}
+
+	return DefaultReaggregationPolicy()
+}
+
+// heldLogins returns the logins that the policy holds back from refreshing at
+// `now`: those with an active account awaiting user action, or one whose
+// failure is to be retried after a delay that has not passed yet
+func heldLogins(accounts []Account, policy ReaggregationPolicy, now time.Time) map[int64]bool {
+	held := map[int64]bool{}
+	for _, account := range accounts {
+		if !account.IsActive() {
+			continue
+		}
+
+		switch rule := policy.ForAccount(account); rule.Action {
+		case ReaggregationUserAction:
+			held[account.LoginID] = true
+		case ReaggregationRetry:
+			if now.Before(account.AggrAttemptDate.Time().Add(rule.Delay)) {
+				held[account.LoginID] = true
+			}
+		}
+	}
+
+	return held
+}
+
+// pendingAccounts returns the IDs of the active accounts of refreshed logins
+// that have not been aggregated since `since`, and those of the active
+// accounts of held logins
+func pendingAccounts(accounts []Account, since time.Time, held map[int64]bool) (pending, heldAccounts []int64) {
+	for _, account := range accounts {
+		switch {
+		case !account.IsActive():
+		case held[account.LoginID]:
+			heldAccounts = append(heldAccounts, account.ID)
+		case account.AggrAttemptDate.Time().Before(since):
+			pending = append(pending, account.ID)
+		}
+	}
+
+	return pending, heldAccounts
+}
+
+// sleepClock waits for `d` on the clock, or until ctx is done
+func sleepClock(ctx context.Context, clock Clock, d time.Duration) error {
+	done := make(chan struct{})
+	timer := clock.AfterFunc(d, func() { close(done) })
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	}
+}
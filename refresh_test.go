@@ -0,0 +1,167 @@
+package intuit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/intuittest"
+)
+
+// setAggregationStatus changes the status of some of the customer's accounts
+// on the server, as if their last aggregation had ended with it at `at`
+func setAggregationStatus(t *testing.T, srv *intuittest.Server, client *intuit.Client, at time.Time, statuses map[int64]intuit.AggregationStatus) {
+	t.Helper()
+
+	accounts, err := client.GetCustomerAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, account := range accounts {
+		if status, ok := statuses[account.ID]; ok {
+			account.AggrStatusCode = status
+			account.AggrAttemptDate = intuit.Timestamp(at)
+			srv.AddAccounts(testCustomer, account)
+		}
+	}
+}
+
+func TestRefreshCoordinatorPolicy(t *testing.T) {
+	const (
+		mfaAccount   int64 = 400107846791
+		retryAccount int64 = 400107846788
+	)
+
+	tests := []struct {
+		name        string
+		retryAgo    time.Duration
+		policy      *intuit.ReaggregationPolicy
+		wantHeld    []int64
+		wantSuccess bool
+	}{
+		{
+			name:     "retry delay passed",
+			retryAgo: 2 * time.Hour,
+			wantHeld: []int64{400107846791, 400107846792},
+		},
+		{
+			name:     "retry delay not passed",
+			retryAgo: 10 * time.Minute,
+			wantHeld: []int64{400107846787, 400107846788, 400107846789, 400107846790, 400107846791, 400107846792},
+		},
+		{
+			name:        "custom policy",
+			retryAgo:    10 * time.Minute,
+			policy:      &intuit.ReaggregationPolicy{},
+			wantSuccess: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv, client := newTestServer(t)
+			setAggregationStatus(t, srv, client, time.Now().Add(-test.retryAgo), map[int64]intuit.AggregationStatus{
+				mfaAccount:   intuit.AggrStatusMFARequired,
+				retryAccount: intuit.AggrStatusAggrError,
+			})
+
+			m, err := srv.NewManager()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rc := intuit.NewRefreshCoordinator(m)
+			rc.Policy = test.policy
+
+			var (
+				results   []intuit.RefreshResult
+				successes int
+			)
+			s := intuit.NewScheduler(rc, intuit.Every(time.Hour), testCustomer)
+			s.OnSuccess = func(result intuit.RefreshResult) {
+				results = append(results, result)
+				successes++
+			}
+			s.OnFailure = func(result intuit.RefreshResult) { results = append(results, result) }
+			s.RunOnce(context.Background())
+
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			result := results[0]
+			if result.Err != nil {
+				t.Fatal(result.Err)
+			}
+
+			held := append([]int64(nil), result.Held...)
+			sort.Slice(held, func(i, j int) bool { return held[i] < held[j] })
+			if !equalIDs(held, test.wantHeld) {
+				t.Errorf("held accounts %v, want %v", held, test.wantHeld)
+			}
+			if len(result.Pending) > 0 {
+				t.Errorf("pending accounts %v, want none", result.Pending)
+			}
+
+			// held logins keep their status; refreshed ones succeed
+			for _, account := range result.Accounts {
+				wantOK := account.IsActive()
+				for _, id := range test.wantHeld {
+					wantOK = wantOK && account.ID != id
+				}
+				if wantOK && account.AggrStatusCode != intuit.AggrStatusOK {
+					t.Errorf("account %d status %s, want it refreshed", account.ID, account.AggrStatusCode)
+				}
+			}
+
+			if success := successes == 1; success != test.wantSuccess {
+				t.Errorf("scheduler reported success %v, want %v", success, test.wantSuccess)
+			}
+		})
+	}
+}
+
+func TestRefreshCoordinatorCancelled(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	m, err := srv.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the second login is refreshed only after the cancellation
+	m.Middleware = []intuit.Middleware{func(next http.RoundTripper) http.RoundTripper {
+		return intuit.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == "PUT" && req.URL.Path == "/logins/19034286" {
+				cancel()
+				return nil, req.Context().Err()
+			}
+
+			return next.RoundTrip(req)
+		})
+	}}
+
+	var results []intuit.RefreshResult
+	for result := range intuit.NewRefreshCoordinator(m).Run(ctx, []string{testCustomer}) {
+		results = append(results, result)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+
+	var cancelled *intuit.CancelledError
+	if err := results[0].Err; !errors.As(err, &cancelled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("result error = %v, want a CancelledError", err)
+	}
+	if cancelled.Completed+cancelled.Remaining != 6 || len(results[0].Accounts) != 7 {
+		t.Errorf("error %v with %d accounts, want 6 active accounts counted of 7", cancelled, len(results[0].Accounts))
+	}
+}
@@ -0,0 +1,99 @@
+package intuit
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// IdempotencyKeyHeader is the header set by WithIdempotencyKey
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// TrackingIDHeader is the header Intuit uses to correlate a request across its
+// systems; see WithTrackingID
+const TrackingIDHeader = "intuit_tid"
+
+// RequestOption customizes a single API call
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	header     http.Header
+	timeout    time.Duration
+	idempotent bool
+	metadata   *ResponseMetadata
+}
+
+// WithHeader adds a header to the requests of the call, e.g. a correlation ID
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.header.Add(key, value)
+	}
+}
+
+// WithTimeout bounds the call, including retries and reading the response, by
+// `timeout`. The deadline of the call's context still applies if it is
+// earlier.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithIdempotencyKey sends `key` in the Idempotency-Key header. The requests of
+// the call are then retried under the client's RetryPolicy even if their
+// method is not idempotent.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.header.Set(IdempotencyKeyHeader, key)
+		o.idempotent = true
+	}
+}
+
+// WithTrackingID sends `id` as the Intuit transaction ID of the call's
+// requests, so that it can be quoted in support cases
+func WithTrackingID(id string) RequestOption {
+	return WithHeader(TrackingIDHeader, id)
+}
+
+// requestOptionsKey is the context key of the options of the current call
+type requestOptionsKey struct{}
+
+// withRequestOptions returns a context carrying `opts`, in addition to the
+// options of any enclosing call, and bounded by their timeout. The cancel
+// function must be called when the call returns.
+func withRequestOptions(ctx context.Context, opts []RequestOption) (context.Context, context.CancelFunc) {
+	if len(opts) == 0 {
+		return ctx, func() {}
+	}
+
+	o := requestOptionsFrom(ctx)
+	o.header = o.header.Clone()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx = context.WithValue(ctx, requestOptionsKey{}, o)
+
+	if o.timeout > 0 {
+		return context.WithTimeout(ctx, o.timeout)
+	}
+
+	return ctx, func() {}
+}
+
+// requestOptionsFrom returns the options of the call `ctx` belongs to
+func requestOptionsFrom(ctx context.Context) requestOptions {
+	o, ok := ctx.Value(requestOptionsKey{}).(requestOptions)
+	if !ok || o.header == nil {
+		o.header = http.Header{}
+	}
+
+	return o
+}
+
+// applyRequestOptions sets the headers of the call's options on req
+func applyRequestOptions(req *http.Request) {
+	for key, values := range requestOptionsFrom(req.Context()).header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+}
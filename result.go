@@ -0,0 +1,49 @@
+package intuit
+
+import "fmt"
+
+// Constants representing warning codes
+const (
+	// WarningUnknownField means part of a payload was not recognized and was
+	// dropped
+	WarningUnknownField = "unknown-field"
+
+	// WarningPayloadError means the payload carried an error alongside the
+	// data that was returned
+	WarningPayloadError = "payload-error"
+
+	// WarningDuplicate means a duplicate record was dropped
+	WarningDuplicate = "duplicate"
+
+	// WarningDegraded means an optional subsystem failed and the result
+	// lacks the data it would have added
+	WarningDegraded = "degraded"
+)
+
+// Warning describes data an operation dropped or altered while still
+// succeeding
+type Warning struct {
+	Code    string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Code, w.Message)
+}
+
+// Result is embedded in the results of operations that can succeed partially.
+// Its warnings make data-quality problems visible to callers instead of
+// silently discarding data.
+type Result struct {
+	Warnings []Warning
+}
+
+// Warn records a warning
+func (r *Result) Warn(code, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, Warning{Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// HasWarnings reports whether any warnings were recorded
+func (r Result) HasWarnings() bool {
+	return len(r.Warnings) > 0
+}
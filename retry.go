@@ -0,0 +1,125 @@
+package intuit
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail transiently are retried.
+// Network errors and responses with status 408 (aggregation in progress), 429
+// and 5xx are considered transient; see IsRetryable. Errors initializing the
+// client, acquiring an OAuth token or signing the request are returned at
+// once.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Values
+	// <= 1 disable retries.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. Each subsequent retry
+	// doubles the delay, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// Jitter is the fraction (0 to 1) of each delay that is randomized, so that
+	// many clients failing at once don't retry in lockstep
+	Jitter float64
+
+	// RetryNonIdempotent allows methods other than GET and HEAD to be retried,
+	// even for calls without WithIdempotencyKey
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy is a reasonable policy for clients that want retries. It is
+// not applied unless configured with WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond * 500,
+	MaxDelay:    time.Second * 30,
+	Jitter:      0.2,
+}
+
+// WithRetryPolicy enables retries of transient failures using `policy`
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.RetryPolicy = &policy
+	}
+}
+
+// attempts returns the number of times req may be sent under the policy
+func (p *RetryPolicy) attempts(req *http.Request) int {
+	if p == nil || p.MaxAttempts <= 1 {
+		return 1
+	}
+
+	idempotent := req.Method == "GET" || req.Method == "HEAD" || requestOptionsFrom(req.Context()).idempotent
+	if !p.RetryNonIdempotent && !idempotent {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+// delay returns how long to wait before retrying after the given attempt
+// (starting at 1). A Retry-After header on resp is honored if it asks for a
+// longer wait.
+func (p *RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		delay -= time.Duration(p.Jitter * rand.Float64() * float64(delay))
+	}
+
+	if resp != nil {
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok && after > delay {
+			delay = after
+		}
+	}
+
+	return delay
+}
+
+// isTransient reports whether a request that produced resp and err may succeed
+// if it is retried
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		return IsRetryable(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout:
+		return true
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= 500:
+		return true
+	}
+
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,163 @@
+package intuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 10,
+		BaseDelay:   time.Second,
+		MaxDelay:    10 * time.Second,
+	}
+
+	tests := []struct {
+		attempt    int
+		retryAfter string
+		want       time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 8 * time.Second},
+		{attempt: 5, want: 10 * time.Second},
+		{attempt: 100, want: 10 * time.Second},
+		{attempt: 1, retryAfter: "5", want: 5 * time.Second},
+		{attempt: 4, retryAfter: "5", want: 8 * time.Second},
+		{attempt: 1, retryAfter: "soon", want: time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d/%s", test.attempt, test.retryAfter), func(t *testing.T) {
+			var resp *http.Response
+			if test.retryAfter != "" {
+				resp = &http.Response{Header: http.Header{"Retry-After": {test.retryAfter}}}
+			}
+
+			if got := policy.delay(test.attempt, resp); got != test.want {
+				t.Errorf("delay() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelayJitter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		if got := policy.delay(1, nil); got < 500*time.Millisecond || got > time.Second {
+			t.Fatalf("delay() = %s, want between 500ms and 1s", got)
+		}
+	}
+}
+
+func TestRetryPolicyAttempts(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     *RetryPolicy
+		method     string
+		idempotent bool
+		want       int
+	}{
+		{name: "no policy", method: "GET", want: 1},
+		{name: "retries disabled", policy: &RetryPolicy{MaxAttempts: 1}, method: "GET", want: 1},
+		{name: "GET", policy: &RetryPolicy{MaxAttempts: 3}, method: "GET", want: 3},
+		{name: "HEAD", policy: &RetryPolicy{MaxAttempts: 3}, method: "HEAD", want: 3},
+		{name: "POST", policy: &RetryPolicy{MaxAttempts: 3}, method: "POST", want: 1},
+		{name: "idempotent POST", policy: &RetryPolicy{MaxAttempts: 3}, method: "POST", idempotent: true, want: 3},
+		{name: "non-idempotent retries", policy: &RetryPolicy{MaxAttempts: 3, RetryNonIdempotent: true}, method: "DELETE", want: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.idempotent {
+				var cancel context.CancelFunc
+				ctx, cancel = withRequestOptions(ctx, []RequestOption{WithIdempotencyKey("key")})
+				defer cancel()
+			}
+
+			req, err := http.NewRequestWithContext(ctx, test.method, "https://example.com", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := test.policy.attempts(req); got != test.want {
+				t.Errorf("attempts() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+		want   bool
+	}{
+		{status: 200, want: false},
+		{status: 400, want: false},
+		{status: 401, want: false},
+		{status: 404, want: false},
+		{status: 408, want: true},
+		{status: 429, want: true},
+		{status: 500, want: true},
+		{status: 503, want: true},
+		{err: io.ErrUnexpectedEOF, want: true},
+		{err: context.Canceled, want: false},
+		{err: &signError{errors.New("bad key")}, want: false},
+		{err: ConfigErrors{{Field: "ConsumerKey", Problem: "is required"}}, want: false},
+	}
+
+	for _, test := range tests {
+		name := strconv.Itoa(test.status)
+		if test.err != nil {
+			name = test.err.Error()
+		}
+
+		t.Run(name, func(t *testing.T) {
+			var resp *http.Response
+			if test.err == nil {
+				resp = &http.Response{StatusCode: test.status}
+			}
+
+			if got := isTransient(resp, test.err); got != test.want {
+				t.Errorf("isTransient() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: ""},
+		{value: "0", want: 0, wantOK: true},
+		{value: "120", want: 2 * time.Minute, wantOK: true},
+		{value: "-1"},
+		{value: "soon"},
+	}
+
+	for _, test := range tests {
+		got, ok := parseRetryAfter(test.value)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %s, %v, want %s, %v", test.value, got, ok, test.want, test.wantOK)
+		}
+	}
+
+	// an HTTP date is relative to now
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got, ok := parseRetryAfter(date); !ok || got < 58*time.Minute || got > time.Hour {
+		t.Errorf("parseRetryAfter(%q) = %s, %v, want about an hour", date, got, ok)
+	}
+}
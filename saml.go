@@ -5,9 +5,13 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"hash"
 	"strings"
 	"time"
 
@@ -18,10 +22,24 @@ import (
 const (
 	C14N      = "http://www.w3.org/2001/10/xml-exc-c14n#"
 	RSASHA1   = "http://www.w3.org/2000/09/xmldsig#rsa-sha1"
+	RSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
 	XMLDSIGNS = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
 	SHA1      = "http://www.w3.org/2000/09/xmldsig#sha1"
+	SHA256    = "http://www.w3.org/2001/04/xmlenc#sha256"
 )
 
+// signatureAlgorithm describes the xmldsig algorithms used for a hash function
+type signatureAlgorithm struct {
+	signatureMethod string
+	digestMethod    string
+	newHash         func() hash.Hash
+}
+
+var signatureAlgorithms = map[crypto.Hash]signatureAlgorithm{
+	crypto.SHA1:   {RSASHA1, SHA1, sha1.New},
+	crypto.SHA256: {RSASHA256, SHA256, sha256.New},
+}
+
 // Constants for SAML 2.0
 const (
 	classUnspecified        = "urn:oasis:names:tc:SAML:2.0:ac:classes:unspecified"
@@ -29,6 +47,82 @@ const (
 	bearerToken             = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
 )
 
+// Bounds on the lifetime of SAML assertions. Intuit's token endpoint only
+// accepts short-lived assertions and rejects others with an opaque OAuth
+// problem, so the client enforces the window itself. DefaultAssertionLifetime
+// is the lifetime the client uses unless configured otherwise.
+const (
+	MinAssertionLifetime     = time.Second * 30
+	MaxAssertionLifetime     = time.Minute * 10
+	DefaultAssertionLifetime = MaxAssertionLifetime
+)
+
+// AssertionLifetimeError is returned for assertion lifetimes outside
+// [MinAssertionLifetime, MaxAssertionLifetime]
+type AssertionLifetimeError struct {
+	Lifetime time.Duration
+}
+
+func (e *AssertionLifetimeError) Error() string {
+	return fmt.Sprintf("assertion lifetime %s is outside the allowed range of %s to %s", e.Lifetime, MinAssertionLifetime, MaxAssertionLifetime)
+}
+
+// ValidateAssertionLifetime returns an AssertionLifetimeError if `lifetime` is
+// outside the range Intuit accepts
+func ValidateAssertionLifetime(lifetime time.Duration) error {
+	if lifetime < MinAssertionLifetime || lifetime > MaxAssertionLifetime {
+		return &AssertionLifetimeError{Lifetime: lifetime}
+	}
+
+	return nil
+}
+
+// clampAssertionLifetime limits `lifetime` to the range Intuit accepts
+func clampAssertionLifetime(lifetime time.Duration) time.Duration {
+	switch {
+	case lifetime < MinAssertionLifetime:
+		return MinAssertionLifetime
+	case lifetime > MaxAssertionLifetime:
+		return MaxAssertionLifetime
+	}
+
+	return lifetime
+}
+
+// AssertionTimeLayout is the layout used for timestamps in SAML assertions. Times
+// are converted to UTC before formatting. The default has millisecond
+// precision, as some validators reject Go's default nanosecond timestamps.
+var AssertionTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Instant is a timestamp in a SAML assertion. It is marshaled in UTC using
+// AssertionTimeLayout.
+type Instant time.Time
+
+// Time returns the instant as a time.Time
+func (i Instant) Time() time.Time {
+	return time.Time(i)
+}
+
+// MarshalXMLAttr implements the xml MarshalerAttr interface
+func (i Instant) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: time.Time(i).UTC().Format(AssertionTimeLayout)}, nil
+}
+
+// UnmarshalXMLAttr implements the xml UnmarshalerAttr interface. It accepts
+// AssertionTimeLayout as well as RFC 3339 timestamps of any precision.
+func (i *Instant) UnmarshalXMLAttr(attr xml.Attr) error {
+	t, err := time.Parse(AssertionTimeLayout, attr.Value)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339Nano, attr.Value); err != nil {
+			return err
+		}
+	}
+
+	*i = Instant(t)
+
+	return nil
+}
+
 func samlRequestID() string {
 	refID, err := uuid.NewV4()
 	if err != nil {
@@ -42,35 +136,108 @@ func samlRequestID() string {
 type Assertion struct {
 	XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Assertion"`
 
-	RefID        string    `xml:"ID,attr"`
-	IssueInstant time.Time `xml:"IssueInstant,attr"`
-	Version      string    `xml:"Version,attr"`
+	RefID        string  `xml:"ID,attr"`
+	IssueInstant Instant `xml:"IssueInstant,attr"`
+	Version      string  `xml:"Version,attr"`
 
 	Issuer         string         `xml:"Issuer"`
 	Signature      *signature     `xml:"http://www.w3.org/2000/09/xmldsig# Signature,omitempty"`
 	Subject        subject        `xml:"Subject"`
 	Conditions     conditions     `xml:"Conditions"`
 	AuthnStatement authnStatement `xml:"AuthnStatement"`
+
+	// SigningCert, if set, is embedded in the signature's KeyInfo element when
+	// the assertion is signed, for validators that require it
+	SigningCert *x509.Certificate `xml:"-"`
 }
 
-// NewAssertion creates a new SAML assertion
+// DefaultAssertionClockSkew is how far before the current time NotBefore is set
+// on new assertions, so that they are accepted by servers whose clocks are
+// slightly behind
+const DefaultAssertionClockSkew = time.Minute
+
+// AssertionOptions controls the SAML assertions a client creates. Zero fields
+// use the defaults noted below.
+type AssertionOptions struct {
+	// Lifetime is how long the assertion is valid from the time it is issued.
+	// Defaults to DefaultAssertionLifetime.
+	Lifetime time.Duration
+
+	// ClockSkew moves NotBefore into the past to tolerate clock drift. Defaults
+	// to DefaultAssertionClockSkew.
+	ClockSkew time.Duration
+
+	// Audience is the audience restriction. Defaults to the issuer.
+	Audience string
+
+	// Now and NewID supply the issue time and the assertion ID. They default
+	// to time.Now and a random UUID-based ID; tests can replace them to
+	// produce byte-identical assertions.
+	Now   func() time.Time
+	NewID func() string
+}
+
+// NewAssertion creates a new SAML assertion. The lifetime is clamped to
+// [MinAssertionLifetime, MaxAssertionLifetime]; use NewAssertionWithOptions to
+// reject out-of-range values instead.
 func NewAssertion(issuer, customerID string, lifetime time.Duration) Assertion {
+	return newAssertion(issuer, customerID, AssertionOptions{
+		Lifetime:  clampAssertionLifetime(lifetime),
+		ClockSkew: DefaultAssertionClockSkew,
+	})
+}
+
+// NewAssertionWithOptions creates a new SAML assertion configured by `opts`. It
+// returns an AssertionLifetimeError if the lifetime is out of range.
+func NewAssertionWithOptions(issuer, customerID string, opts AssertionOptions) (Assertion, error) {
+	if opts.Lifetime == 0 {
+		opts.Lifetime = DefaultAssertionLifetime
+	}
+
+	if err := ValidateAssertionLifetime(opts.Lifetime); err != nil {
+		return Assertion{}, err
+	}
+
+	if opts.ClockSkew < 0 {
+		return Assertion{}, fmt.Errorf("assertion clock skew %s must not be negative", opts.ClockSkew)
+	}
+
+	if opts.ClockSkew == 0 {
+		opts.ClockSkew = DefaultAssertionClockSkew
+	}
+
+	return newAssertion(issuer, customerID, opts), nil
+}
+
+func newAssertion(issuer, customerID string, opts AssertionOptions) Assertion {
 	now := time.Now()
-	expiration := now.Add(lifetime)
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+
+	expiration := now.Add(opts.Lifetime)
+
+	audience := opts.Audience
+	if audience == "" {
+		audience = issuer
+	}
 
 	refID := samlRequestID()
+	if opts.NewID != nil {
+		refID = opts.NewID()
+	}
 
 	return Assertion{
 		RefID:        refID,
-		IssueInstant: now,
+		IssueInstant: Instant(now),
 		Version:      "2.0",
 
 		Issuer: issuer,
 
 		Conditions: conditions{
-			NotBefore:           now,
-			NotOnOrAfter:        expiration,
-			AudienceRestriction: issuer,
+			NotBefore:           Instant(now.Add(-opts.ClockSkew)),
+			NotOnOrAfter:        Instant(expiration),
+			AudienceRestriction: audience,
 		},
 		Subject: subject{
 			NameID: nameID{
@@ -80,7 +247,7 @@ func NewAssertion(issuer, customerID string, lifetime time.Duration) Assertion {
 			SubjectConfirmation: subjectConfirmation{bearerToken},
 		},
 		AuthnStatement: authnStatement{
-			AuthnInstance: now,
+			AuthnInstance: Instant(now),
 			SessionIndex:  refID,
 			Context:       authnContext{classUnspecified},
 		},
@@ -88,28 +255,64 @@ func NewAssertion(issuer, customerID string, lifetime time.Duration) Assertion {
 }
 
 // Sign populates the assertion's xmldisg signature based on the assertion's
-// current state.
+// current state, using RSA-SHA1.
 func (a *Assertion) Sign(key *rsa.PrivateKey) error {
-	assertionStr, err := xml.Marshal(a)
+	return a.SignWith(key, crypto.SHA1)
+}
+
+// SignWith is like Sign, but uses the given hash function for the digest and
+// signature. crypto.SHA1 and crypto.SHA256 are supported.
+func (a *Assertion) SignWith(key *rsa.PrivateKey, hashFunc crypto.Hash) error {
+	if key == nil {
+		return errors.New("private key must not be nil")
+	}
+
+	return a.SignWithSigner(key, hashFunc)
+}
+
+// SignWithSigner is like SignWith, but signs with a crypto.Signer, so that the
+// private key can be held in an HSM or a cloud KMS. The signer's public key must
+// be an RSA key.
+func (a *Assertion) SignWithSigner(signer crypto.Signer, hashFunc crypto.Hash) error {
+	alg, ok := signatureAlgorithms[hashFunc]
+	if !ok {
+		return fmt.Errorf("unsupported signature hash %v", hashFunc)
+	}
+
+	if _, ok := signer.Public().(*rsa.PublicKey); !ok {
+		return fmt.Errorf("unsupported signer key type %T", signer.Public())
+	}
+
+	// the digest covers the assertion without its signature (the enveloped
+	// signature transform), in exclusive canonical form
+	unsigned := *a
+	unsigned.Signature = nil
+
+	assertionXML, err := xml.Marshal(unsigned)
 	if err != nil {
 		return err
 	}
 
-	hash := sha1.New()
-	hash.Write(assertionStr)
+	canonical, err := canonicalXML(assertionXML)
+	if err != nil {
+		return err
+	}
+
+	hash := alg.newHash()
+	hash.Write(canonical)
 
 	si := signedInfo{
 		CanonicalizationMethod: algorithm{C14N},
-		SignatureMethod:        algorithm{RSASHA1},
+		SignatureMethod:        algorithm{alg.signatureMethod},
 		Reference: reference{
 			URI:          fmt.Sprintf("#%s", a.RefID),
 			Transforms:   []algorithm{{XMLDSIGNS}, {C14N}},
-			DigestMethod: algorithm{SHA1},
+			DigestMethod: algorithm{alg.digestMethod},
 			DigestValue:  base64.StdEncoding.EncodeToString(hash.Sum(nil)),
 		},
 	}
 
-	sigStr, err := si.signatureValue(key)
+	sigStr, err := si.signatureValue(signer, hashFunc)
 	if err != nil {
 		return err
 	}
@@ -119,6 +322,12 @@ func (a *Assertion) Sign(key *rsa.PrivateKey) error {
 		SignatureValue: sigStr,
 	}
 
+	if a.SigningCert != nil {
+		signature.KeyInfo = &keyInfo{
+			X509Certificate: base64.StdEncoding.EncodeToString(a.SigningCert.Raw),
+		}
+	}
+
 	a.Signature = signature
 
 	return nil
@@ -131,17 +340,22 @@ type signedInfo struct {
 	Reference              reference `xml:"Reference"`
 }
 
-func (si signedInfo) signatureValue(key *rsa.PrivateKey) (string, error) {
+func (si signedInfo) signatureValue(signer crypto.Signer, hashFunc crypto.Hash) (string, error) {
 	signedInfoXML, err := xml.Marshal(si)
 	if err != nil {
 		return "", err
 	}
 
-	hash := sha1.New()
-	hash.Write(signedInfoXML)
+	canonical, err := canonicalXML(signedInfoXML)
+	if err != nil {
+		return "", err
+	}
+
+	hash := signatureAlgorithms[hashFunc].newHash()
+	hash.Write(canonical)
 	digest := hash.Sum(nil)
 
-	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA1, digest)
+	signature, err := signer.Sign(rand.Reader, digest, hashFunc)
 	if err != nil {
 		return "", err
 	}
@@ -152,6 +366,11 @@ func (si signedInfo) signatureValue(key *rsa.PrivateKey) (string, error) {
 type signature struct {
 	SignedInfo     signedInfo `xml:"http://www.w3.org/2000/09/xmldsig# SignedInfo"`
 	SignatureValue string     `xml:"SignatureValue"`
+	KeyInfo        *keyInfo   `xml:"KeyInfo,omitempty"`
+}
+
+type keyInfo struct {
+	X509Certificate string `xml:"X509Data>X509Certificate"`
 }
 
 type algorithm struct {
@@ -163,15 +382,15 @@ type authnContext struct {
 }
 
 type authnStatement struct {
-	AuthnInstance time.Time    `xml:"AuthnInstant,attr"`
+	AuthnInstance Instant      `xml:"AuthnInstant,attr"`
 	SessionIndex  string       `xml:"SessionIndex,attr"`
 	Context       authnContext `xml:"AuthnContext"`
 }
 
 type conditions struct {
-	NotBefore           time.Time `xml:"NotBefore,attr,omitempty"`
-	NotOnOrAfter        time.Time `xml:"NotOnOrAfter,attr,omitempty"`
-	AudienceRestriction string    `xml:"AudienceRestriction>Audience"`
+	NotBefore           Instant `xml:"NotBefore,attr,omitempty"`
+	NotOnOrAfter        Instant `xml:"NotOnOrAfter,attr,omitempty"`
+	AudienceRestriction string  `xml:"AudienceRestriction>Audience"`
 }
 
 type nameID struct {
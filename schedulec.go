@@ -0,0 +1,194 @@
+package intuit
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TaxCategory is a line of IRS Schedule C (Profit or Loss From Business), as
+// named by the scheduleC field of a categorization context
+type TaxCategory string
+
+// Constants representing the Schedule C lines, in form order
+const (
+	TaxCategoryGrossReceipts           TaxCategory = "Gross Receipts"
+	TaxCategoryReturnsAndAllowances    TaxCategory = "Returns and Allowances"
+	TaxCategoryOtherIncome             TaxCategory = "Other Income"
+	TaxCategoryAdvertising             TaxCategory = "Advertising"
+	TaxCategoryCarAndTruck             TaxCategory = "Car and Truck Expenses"
+	TaxCategoryCommissionsAndFees      TaxCategory = "Commissions and Fees"
+	TaxCategoryContractLabor           TaxCategory = "Contract Labor"
+	TaxCategoryDepletion               TaxCategory = "Depletion"
+	TaxCategoryDepreciation            TaxCategory = "Depreciation"
+	TaxCategoryEmployeeBenefits        TaxCategory = "Employee Benefit Programs"
+	TaxCategoryInsurance               TaxCategory = "Insurance"
+	TaxCategoryMortgageInterest        TaxCategory = "Mortgage Interest"
+	TaxCategoryOtherInterest           TaxCategory = "Other Interest"
+	TaxCategoryLegalAndProfessional    TaxCategory = "Legal and Professional Services"
+	TaxCategoryOfficeExpense           TaxCategory = "Office Expense"
+	TaxCategoryPensionAndProfitSharing TaxCategory = "Pension and Profit-Sharing Plans"
+	TaxCategoryRentVehicles            TaxCategory = "Rent or Lease of Vehicles, Machinery and Equipment"
+	TaxCategoryRentProperty            TaxCategory = "Rent or Lease of Other Business Property"
+	TaxCategoryRepairs                 TaxCategory = "Repairs and Maintenance"
+	TaxCategorySupplies                TaxCategory = "Supplies"
+	TaxCategoryTaxesAndLicenses        TaxCategory = "Taxes and Licenses"
+	TaxCategoryTravel                  TaxCategory = "Travel"
+	TaxCategoryMeals                   TaxCategory = "Meals"
+	TaxCategoryUtilities               TaxCategory = "Utilities"
+	TaxCategoryWages                   TaxCategory = "Wages"
+	TaxCategoryOtherExpenses           TaxCategory = "Other Expenses"
+	TaxCategoryBusinessUseOfHome       TaxCategory = "Business Use of Home"
+)
+
+// TaxCategories lists every Schedule C line, in form order
+var TaxCategories = []TaxCategory{
+	TaxCategoryGrossReceipts,
+	TaxCategoryReturnsAndAllowances,
+	TaxCategoryOtherIncome,
+	TaxCategoryAdvertising,
+	TaxCategoryCarAndTruck,
+	TaxCategoryCommissionsAndFees,
+	TaxCategoryContractLabor,
+	TaxCategoryDepletion,
+	TaxCategoryDepreciation,
+	TaxCategoryEmployeeBenefits,
+	TaxCategoryInsurance,
+	TaxCategoryMortgageInterest,
+	TaxCategoryOtherInterest,
+	TaxCategoryLegalAndProfessional,
+	TaxCategoryOfficeExpense,
+	TaxCategoryPensionAndProfitSharing,
+	TaxCategoryRentVehicles,
+	TaxCategoryRentProperty,
+	TaxCategoryRepairs,
+	TaxCategorySupplies,
+	TaxCategoryTaxesAndLicenses,
+	TaxCategoryTravel,
+	TaxCategoryMeals,
+	TaxCategoryUtilities,
+	TaxCategoryWages,
+	TaxCategoryOtherExpenses,
+	TaxCategoryBusinessUseOfHome,
+}
+
+var taxCategoryLines = map[TaxCategory]string{
+	TaxCategoryGrossReceipts:           "1",
+	TaxCategoryReturnsAndAllowances:    "2",
+	TaxCategoryOtherIncome:             "6",
+	TaxCategoryAdvertising:             "8",
+	TaxCategoryCarAndTruck:             "9",
+	TaxCategoryCommissionsAndFees:      "10",
+	TaxCategoryContractLabor:           "11",
+	TaxCategoryDepletion:               "12",
+	TaxCategoryDepreciation:            "13",
+	TaxCategoryEmployeeBenefits:        "14",
+	TaxCategoryInsurance:               "15",
+	TaxCategoryMortgageInterest:        "16a",
+	TaxCategoryOtherInterest:           "16b",
+	TaxCategoryLegalAndProfessional:    "17",
+	TaxCategoryOfficeExpense:           "18",
+	TaxCategoryPensionAndProfitSharing: "19",
+	TaxCategoryRentVehicles:            "20a",
+	TaxCategoryRentProperty:            "20b",
+	TaxCategoryRepairs:                 "21",
+	TaxCategorySupplies:                "22",
+	TaxCategoryTaxesAndLicenses:        "23",
+	TaxCategoryTravel:                  "24a",
+	TaxCategoryMeals:                   "24b",
+	TaxCategoryUtilities:               "25",
+	TaxCategoryWages:                   "26",
+	TaxCategoryOtherExpenses:           "27a",
+	TaxCategoryBusinessUseOfHome:       "30",
+}
+
+// taxCategoryAliases maps other names used for Schedule C lines, normalized
+// with normalizeTaxCategory, to their category
+var taxCategoryAliases = map[string]TaxCategory{
+	"car truck":                         TaxCategoryCarAndTruck,
+	"car and truck":                     TaxCategoryCarAndTruck,
+	"commissions":                       TaxCategoryCommissionsAndFees,
+	"employee benefits":                 TaxCategoryEmployeeBenefits,
+	"insurance other than health":       TaxCategoryInsurance,
+	"interest mortgage":                 TaxCategoryMortgageInterest,
+	"interest other":                    TaxCategoryOtherInterest,
+	"legal and professional":            TaxCategoryLegalAndProfessional,
+	"legal professional services":       TaxCategoryLegalAndProfessional,
+	"pension and profit sharing":        TaxCategoryPensionAndProfitSharing,
+	"rent or lease vehicles":            TaxCategoryRentVehicles,
+	"rent or lease other":               TaxCategoryRentProperty,
+	"repairs":                           TaxCategoryRepairs,
+	"taxes":                             TaxCategoryTaxesAndLicenses,
+	"meals and entertainment":           TaxCategoryMeals,
+	"deductible meals":                  TaxCategoryMeals,
+	"wages less employment credits":     TaxCategoryWages,
+	"other":                             TaxCategoryOtherExpenses,
+	"expenses for business use of home": TaxCategoryBusinessUseOfHome,
+}
+
+var taxCategoriesByName = func() map[string]TaxCategory {
+	byName := make(map[string]TaxCategory, len(TaxCategories)+len(taxCategoryAliases))
+	for _, category := range TaxCategories {
+		byName[normalizeTaxCategory(string(category))] = category
+	}
+	for alias, category := range taxCategoryAliases {
+		byName[alias] = category
+	}
+
+	return byName
+}()
+
+// ParseTaxCategory returns the Schedule C line named `name`, compared
+// case-insensitively and ignoring punctuation, or false if `name` names none
+func ParseTaxCategory(name string) (TaxCategory, bool) {
+	category, ok := taxCategoriesByName[normalizeTaxCategory(name)]
+	return category, ok
+}
+
+// normalizeTaxCategory lower-cases `name` and collapses punctuation and
+// spacing to single spaces
+func normalizeTaxCategory(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(fields, " ")
+}
+
+// Line returns the category's line number on Schedule C, such as "24a", or ""
+// if the category is not a known line
+func (c TaxCategory) Line() string {
+	return taxCategoryLines[c]
+}
+
+// IsIncome reports whether the category is a line of Part I (Income)
+func (c TaxCategory) IsIncome() bool {
+	switch c {
+	case TaxCategoryGrossReceipts, TaxCategoryReturnsAndAllowances, TaxCategoryOtherIncome:
+		return true
+	}
+
+	return false
+}
+
+// IsExpense reports whether the category is a line of Part II (Expenses)
+func (c TaxCategory) IsExpense() bool {
+	return c.Line() != "" && !c.IsIncome()
+}
+
+// TaxCategory returns the Schedule C line of the context's scheduleC field,
+// or false if it is empty or names no known line
+func (c CategorizationContext) TaxCategory() (TaxCategory, bool) {
+	return ParseTaxCategory(c.ScheduleC)
+}
+
+// TaxCategory returns the Schedule C line assigned by the first context that
+// assigns a known one, or false if there is none
+func (c Categorization) TaxCategory() (TaxCategory, bool) {
+	for _, context := range c.Context {
+		if category, ok := context.TaxCategory(); ok {
+			return category, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,290 @@
+package intuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Schedule decides when a Scheduler runs
+type Schedule interface {
+	// Next returns the first run time after `t`
+	Next(t time.Time) time.Time
+}
+
+// ScheduleFunc adapts a function to the Schedule interface
+type ScheduleFunc func(time.Time) time.Time
+
+// Next implements Schedule
+func (f ScheduleFunc) Next(t time.Time) time.Time {
+	return f(t)
+}
+
+// Every returns a schedule that runs at multiples of `d` since the zero time,
+// e.g. on the hour for time.Hour. `d` must be positive.
+func Every(d time.Duration) Schedule {
+	return ScheduleFunc(func(t time.Time) time.Time {
+		return t.Truncate(d).Add(d)
+	})
+}
+
+// Daily returns a schedule that runs once a day at the given time in `loc`, or
+// in UTC if loc is nil
+func Daily(hour, minute int, loc *time.Location) Schedule {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return ScheduleFunc(func(t time.Time) time.Time {
+		t = t.In(loc)
+
+		next := time.Date(t.Year(), t.Month(), t.Day(), hour, minute, 0, 0, loc)
+		if !next.After(t) {
+			next = time.Date(t.Year(), t.Month(), t.Day()+1, hour, minute, 0, 0, loc)
+		}
+
+		return next
+	})
+}
+
+// LastSuccessStore persists when each customer was last refreshed
+// successfully, so that a Scheduler can skip recently refreshed customers
+// across process restarts. Implementations must be safe for concurrent use.
+type LastSuccessStore interface {
+	// Load returns the customer's last successful refresh, or the zero time
+	// if there was none
+	Load(customerID string) (time.Time, error)
+
+	// Save records a successful refresh of the customer
+	Save(customerID string, at time.Time) error
+}
+
+// MemoryLastSuccessStore is a LastSuccessStore holding timestamps in memory
+type MemoryLastSuccessStore struct {
+	mu    sync.Mutex
+	times map[string]time.Time
+}
+
+// NewMemoryLastSuccessStore returns an empty store
+func NewMemoryLastSuccessStore() *MemoryLastSuccessStore {
+	return &MemoryLastSuccessStore{times: map[string]time.Time{}}
+}
+
+// Load implements LastSuccessStore
+func (s *MemoryLastSuccessStore) Load(customerID string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.times[customerID], nil
+}
+
+// Save implements LastSuccessStore
+func (s *MemoryLastSuccessStore) Save(customerID string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.times[customerID] = at
+
+	return nil
+}
+
+// ErrCustomerPaused is returned by Syncer.Sync for a customer paused in its
+// PauseStore
+var ErrCustomerPaused = errors.New("customer is paused")
+
+// PauseStore persists which customers are paused, so that support can stop
+// refreshing and syncing a connection, e.g. while it is reviewed for fraud,
+// without removing it. MemoryStore and the boltstore package implement it
+// alongside Store. Implementations must be safe for concurrent use.
+type PauseStore interface {
+	// Paused reports whether the customer is paused
+	Paused(customerID string) (bool, error)
+
+	// SetPaused pauses or resumes the customer
+	SetPaused(customerID string, paused bool) error
+}
+
+// Scheduler refreshes a set of customers periodically with a
+// RefreshCoordinator. A refresh succeeds if it returns no error, no account is
+// pending, and the coordinator's policy calls for no action on any account.
+type Scheduler struct {
+	Coordinator *RefreshCoordinator
+	Schedule    Schedule
+
+	// Customers returns the customers to refresh on each run
+	Customers func() []string
+
+	// Store, if set, records successful refreshes
+	Store LastSuccessStore
+
+	// MinAge skips customers whose last successful refresh, according to
+	// Store, is more recent than this
+	MinAge time.Duration
+
+	// Pauses, if set, holds the customers paused with Pause, which are
+	// skipped until they are resumed
+	Pauses PauseStore
+
+	// OnSuccess and OnFailure, if set, are called with the result of each
+	// customer's refresh. They are called from the scheduler's goroutine, one
+	// at a time.
+	OnSuccess func(RefreshResult)
+	OnFailure func(RefreshResult)
+
+	// OnBackgroundError receives errors from Store and Pauses and panics
+	// from the callbacks. If nil, the manager's handler is used.
+	OnBackgroundError func(error)
+}
+
+// NewScheduler returns a scheduler that refreshes `customerIDs` with the
+// coordinator on `schedule`
+func NewScheduler(rc *RefreshCoordinator, schedule Schedule, customerIDs ...string) *Scheduler {
+	customers := append([]string(nil), customerIDs...)
+
+	return &Scheduler{
+		Coordinator: rc,
+		Schedule:    schedule,
+		Customers:   func() []string { return customers },
+	}
+}
+
+// Run runs the scheduler until ctx is done, and returns ctx's error. Runs
+// happen on the coordinator's clock; a run that overlaps the next scheduled
+// time delays it rather than running concurrently.
+func (s *Scheduler) Run(ctx context.Context) error {
+	clock := s.Coordinator.clock()
+
+	for {
+		now := clock.Now()
+		if err := sleepClock(ctx, clock, s.Schedule.Next(now).Sub(now)); err != nil {
+			return err
+		}
+
+		s.RunOnce(ctx)
+	}
+}
+
+// RunOnce refreshes the customers immediately, calling the callbacks with each
+// result
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	clock := s.Coordinator.clock()
+
+	var customerIDs []string
+	for _, customerID := range s.Customers() {
+		if s.paused(customerID) || s.recentlyRefreshed(customerID, clock.Now()) {
+			continue
+		}
+
+		customerIDs = append(customerIDs, customerID)
+	}
+
+	policy := s.Coordinator.policy()
+	m := s.Coordinator.Manager
+
+	for result := range s.Coordinator.Run(ctx, customerIDs) {
+		succeeded := refreshSucceeded(result, policy)
+		if metrics, ok := m.metrics().(CohortMetrics); ok {
+			metrics.ObserveRefresh(m.cohort(result.CustomerID), succeeded)
+		}
+
+		if !succeeded {
+			s.callback("scheduler failure callback", s.OnFailure, result)
+			continue
+		}
+
+		if s.Store != nil {
+			if err := s.Store.Save(result.CustomerID, clock.Now()); err != nil {
+				s.backgroundError(fmt.Errorf("saving last success of %s: %w", s.Coordinator.Manager.customerLabel(result.CustomerID), err))
+			}
+		}
+
+		s.callback("scheduler success callback", s.OnSuccess, result)
+	}
+}
+
+// refreshSucceeded reports whether the refresh finished with every account
+// needing no action under the policy
+func refreshSucceeded(result RefreshResult, policy ReaggregationPolicy) bool {
+	if result.Err != nil || len(result.Pending) > 0 {
+		return false
+	}
+
+	for _, account := range result.Accounts {
+		if account.IsActive() && policy.ForAccount(account).Action != ReaggregationNone {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Pause stops refreshing the customer, from the next run on, until Resume is
+// called. It records the pause in Pauses, which must be set.
+func (s *Scheduler) Pause(customerID string) error {
+	return s.setPaused(customerID, true)
+}
+
+// Resume refreshes the customer again after Pause
+func (s *Scheduler) Resume(customerID string) error {
+	return s.setPaused(customerID, false)
+}
+
+func (s *Scheduler) setPaused(customerID string, paused bool) error {
+	if s.Pauses == nil {
+		return errors.New("scheduler has no pause store")
+	}
+
+	return s.Pauses.SetPaused(customerID, paused)
+}
+
+// paused reports whether the customer is paused in Pauses. A customer whose
+// state can't be loaded is treated as paused, since pauses are used to stop
+// refreshes that shouldn't happen.
+func (s *Scheduler) paused(customerID string) bool {
+	if s.Pauses == nil {
+		return false
+	}
+
+	paused, err := s.Pauses.Paused(customerID)
+	if err != nil {
+		s.backgroundError(fmt.Errorf("loading pause state of %s: %w", s.Coordinator.Manager.customerLabel(customerID), err))
+		return true
+	}
+
+	return paused
+}
+
+// recentlyRefreshed reports whether the customer was refreshed successfully
+// within MinAge of `now`
+func (s *Scheduler) recentlyRefreshed(customerID string, now time.Time) bool {
+	if s.Store == nil || s.MinAge <= 0 {
+		return false
+	}
+
+	last, err := s.Store.Load(customerID)
+	if err != nil {
+		s.backgroundError(fmt.Errorf("loading last success of %s: %w", s.Coordinator.Manager.customerLabel(customerID), err))
+		return false
+	}
+
+	return !last.IsZero() && now.Sub(last) < s.MinAge
+}
+
+func (s *Scheduler) callback(task string, fn func(RefreshResult), result RefreshResult) {
+	if fn == nil {
+		return
+	}
+
+	runBackground(task, s.backgroundError, func() { fn(result) })
+}
+
+func (s *Scheduler) backgroundError(err error) {
+	if s.OnBackgroundError != nil {
+		s.OnBackgroundError(err)
+		return
+	}
+
+	s.Coordinator.Manager.backgroundError(err)
+}
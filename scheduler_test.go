@@ -0,0 +1,143 @@
+package intuit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+func TestSchedulerPause(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	m, err := srv.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var refreshed []string
+	s := intuit.NewScheduler(intuit.NewRefreshCoordinator(m), intuit.Every(time.Hour), testCustomer)
+	s.OnSuccess = func(result intuit.RefreshResult) { refreshed = append(refreshed, result.CustomerID) }
+	s.OnFailure = s.OnSuccess
+
+	if err := s.Pause(testCustomer); err == nil {
+		t.Error("Pause() without a pause store succeeded")
+	}
+
+	s.Pauses = intuit.NewMemoryStore()
+	if err := s.Pause(testCustomer); err != nil {
+		t.Fatal(err)
+	}
+
+	s.RunOnce(context.Background())
+	if len(refreshed) != 0 {
+		t.Fatalf("refreshed %v while paused, want none", refreshed)
+	}
+
+	if err := s.Resume(testCustomer); err != nil {
+		t.Fatal(err)
+	}
+
+	s.RunOnce(context.Background())
+	if len(refreshed) != 1 || refreshed[0] != testCustomer {
+		t.Errorf("refreshed %v after resuming, want [%s]", refreshed, testCustomer)
+	}
+}
+
+// newScheduler returns a scheduler of testCustomer on the clock, whose
+// refreshes succeed despite the fixture's MFA account, and a channel of the
+// customers it refreshes
+func newScheduler(t *testing.T, clock intuit.Clock) (*intuit.Scheduler, <-chan string) {
+	t.Helper()
+
+	srv, _ := newTestServer(t)
+
+	m, err := srv.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Clock = clock
+
+	rc := intuit.NewRefreshCoordinator(m)
+	rc.Policy = &intuit.ReaggregationPolicy{}
+
+	refreshed := make(chan string, 10)
+	s := intuit.NewScheduler(rc, intuit.Every(time.Hour), testCustomer)
+	s.OnSuccess = func(result intuit.RefreshResult) { refreshed <- result.CustomerID }
+	s.OnFailure = func(result intuit.RefreshResult) { t.Errorf("refreshing %s failed: %v", result.CustomerID, result.Err) }
+
+	return s, refreshed
+}
+
+func TestSchedulerRun(t *testing.T) {
+	clock := intuit.NewManualClock(time.Date(2014, 4, 25, 0, 0, 0, 0, time.UTC))
+	s, refreshed := newScheduler(t, clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	// each run happens once the clock reaches the next hour
+	for i := 0; i < 2; i++ {
+	wait:
+		for {
+			select {
+			case customerID := <-refreshed:
+				if customerID != testCustomer {
+					t.Fatalf("refreshed %s, want %s", customerID, testCustomer)
+				}
+				break wait
+			case <-time.After(time.Millisecond):
+				clock.Advance(time.Minute)
+			}
+		}
+	}
+
+	if now, want := clock.Now(), time.Date(2014, 4, 25, 2, 0, 0, 0, time.UTC); now.Before(want) {
+		t.Errorf("second run at %v, want it no earlier than %v", now, want)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSchedulerMinAge(t *testing.T) {
+	clock := intuit.NewManualClock(time.Date(2014, 4, 25, 0, 0, 0, 0, time.UTC))
+	s, refreshed := newScheduler(t, clock)
+	s.Store = intuit.NewMemoryLastSuccessStore()
+	s.MinAge = 90 * time.Minute
+
+	runs := []struct {
+		advance time.Duration
+		want    int
+	}{
+		{0, 1},
+		{time.Hour, 0},
+		{time.Hour, 1},
+	}
+
+	for i, run := range runs {
+		clock.Advance(run.advance)
+		s.RunOnce(context.Background())
+
+		if got := len(refreshed); got != run.want {
+			t.Errorf("run %d refreshed %d times, want %d", i, got, run.want)
+		}
+		for len(refreshed) > 0 {
+			<-refreshed
+		}
+	}
+
+	last, err := s.Store.Load(testCustomer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := clock.Now(); !last.Equal(want) {
+		t.Errorf("last success %v, want %v", last, want)
+	}
+}
@@ -0,0 +1,255 @@
+// Package server exposes CAD data through a small REST service, so that
+// services not written in Go can read accounts and transactions without
+// implementing the SAML and OAuth 1.0a exchange themselves.
+//
+// Every route is scoped to a customer:
+//
+//	GET  /v1/customers/{customerID}/accounts
+//	GET  /v1/customers/{customerID}/accounts/{accountID}/transactions?start=YYYY-MM-DD&end=YYYY-MM-DD
+//	POST /v1/customers/{customerID}/logins/{loginID}/refresh
+//
+// Requests must carry one of the server's API keys, as "Authorization: Bearer
+// KEY" or in the X-API-Key header. A key grants access to every customer, so
+// keys should only be given to trusted internal services. Responses are JSON;
+// errors are returned as {"error": {"status": ..., "message": ...}}.
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+// APIKeyHeader is the header carrying the API key, as an alternative to the
+// Authorization header
+const APIKeyHeader = "X-API-Key"
+
+// DefaultTransactionDays is the number of days of transactions returned when
+// a request gives no start date
+const DefaultTransactionDays = 30
+
+// Server is an http.Handler serving the REST routes. Clients are acquired from
+// Manager, so customers share its client cache and rate limiting. Create
+// servers with New.
+type Server struct {
+	Manager *intuit.Manager
+
+	// APIKeys are the keys accepted from callers. A server without keys
+	// rejects every request.
+	APIKeys []string
+
+	// Logger, if set, receives a record of every request that fails. Records
+	// name the route rather than the path, so customer IDs are not logged.
+	Logger *slog.Logger
+
+	// Clock, if set, is used to compute default date ranges
+	Clock intuit.Clock
+
+	mux *http.ServeMux
+}
+
+// New returns a server for the manager's customers accepting `apiKeys`
+func New(manager *intuit.Manager, apiKeys ...string) *Server {
+	s := &Server{Manager: manager, APIKeys: apiKeys}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /v1/customers/{customerID}/accounts", s.handleAccounts)
+	s.mux.HandleFunc("GET /v1/customers/{customerID}/accounts/{accountID}/transactions", s.handleTransactions)
+	s.mux.HandleFunc("POST /v1/customers/{customerID}/logins/{loginID}/refresh", s.handleRefresh)
+
+	return s
+}
+
+// ServeHTTP authenticates the request and routes it
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, "missing or invalid API key")
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// authorized reports whether the request carries one of the API keys
+func (s *Server) authorized(r *http.Request) bool {
+	key := r.Header.Get(APIKeyHeader)
+	if auth := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+		key = strings.TrimPrefix(auth, "Bearer ")
+	}
+
+	if key == "" {
+		return false
+	}
+
+	var ok bool
+	for _, apiKey := range s.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+			ok = true
+		}
+	}
+
+	return ok
+}
+
+func (s *Server) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	client, ok := s.client(w, r)
+	if !ok {
+		return
+	}
+
+	accounts, err := client.GetCustomerAccountsContext(r.Context())
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"accounts": nonNil(accounts)})
+}
+
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	accountID, err := strconv.ParseInt(r.PathValue("accountID"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "unknown account")
+		return
+	}
+
+	query := r.URL.Query()
+
+	end := intuit.DateOf(s.now().UTC())
+	if value := query.Get("end"); value != "" {
+		if end, err = intuit.ParseDate(value); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("bad end date: %v", err))
+			return
+		}
+	}
+
+	start := end.AddDays(-DefaultTransactionDays)
+	if value := query.Get("start"); value != "" {
+		if start, err = intuit.ParseDate(value); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("bad start date: %v", err))
+			return
+		}
+	}
+
+	if end.Before(start) {
+		writeError(w, http.StatusBadRequest, "end date is before start date")
+		return
+	}
+
+	client, ok := s.client(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := client.AccountTransactionsForDatesContext(r.Context(), accountID, start, end)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	loginID, err := strconv.ParseInt(r.PathValue("loginID"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "unknown login")
+		return
+	}
+
+	client, ok := s.client(w, r)
+	if !ok {
+		return
+	}
+
+	accounts, err := client.RefreshLoginContext(r.Context(), loginID)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{"accounts": nonNil(accounts)})
+}
+
+// client returns the client of the request's customer, writing an error
+// response if it can't be initialized
+func (s *Server) client(w http.ResponseWriter, r *http.Request) (*intuit.Client, bool) {
+	client, err := s.Manager.ClientFor(r.PathValue("customerID"))
+	if err != nil {
+		s.fail(w, r, err)
+		return nil, false
+	}
+
+	return client, true
+}
+
+// fail writes the error response for a failed CAD call. Errors returned by
+// CAD for the caller's request keep their status; other failures are
+// reported as a bad gateway.
+func (s *Server) fail(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusBadGateway
+
+	var apiErr *intuit.APIError
+	switch {
+	case errors.Is(err, intuit.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, intuit.ErrThrottled):
+		status = http.StatusTooManyRequests
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest:
+		status = http.StatusBadRequest
+	}
+
+	if r.Context().Err() != nil {
+		status = http.StatusGatewayTimeout
+	}
+
+	if s.Logger != nil {
+		s.Logger.ErrorContext(r.Context(), "intuit server: request failed",
+			slog.String("route", r.Pattern),
+			slog.Int("status", status),
+			slog.Any("error", err))
+	}
+
+	writeError(w, status, err.Error())
+}
+
+func (s *Server) now() time.Time {
+	if s.Clock == nil {
+		return intuit.SystemClock.Now()
+	}
+
+	return s.Clock.Now()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"status":  status,
+			"message": message,
+		},
+	})
+}
+
+func nonNil(accounts []intuit.Account) []intuit.Account {
+	if accounts == nil {
+		return []intuit.Account{}
+	}
+
+	return accounts
+}
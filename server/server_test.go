@@ -0,0 +1,123 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/intuittest"
+	"github.com/bodetree/intuit-cad/server"
+)
+
+const (
+	testCustomer = "customer-1"
+	testKey      = "test-key"
+)
+
+// newTestServer starts a fake CAD server seeded with the fixtures for
+// testCustomer and a REST server in front of it accepting testKey
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	cad := intuittest.NewServer()
+	t.Cleanup(cad.Close)
+
+	if err := cad.Seed(testCustomer); err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := cad.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := server.New(manager, testKey)
+	s.Clock = intuit.NewManualClock(time.Date(2014, 4, 25, 0, 0, 0, 0, time.UTC))
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestServer(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := []struct {
+		method, path string
+		header, key  string
+		status       int
+
+		// accounts or transactions is the number expected in the response
+		accounts, transactions int
+	}{
+		{method: "GET", path: "/v1/customers/customer-1/accounts", status: http.StatusUnauthorized},
+		{method: "GET", path: "/v1/customers/customer-1/accounts", header: "Authorization", key: "Bearer wrong-key", status: http.StatusUnauthorized},
+		{method: "GET", path: "/v1/customers/customer-1/accounts", header: "Authorization", key: "Bearer " + testKey, status: http.StatusOK, accounts: 7},
+		{method: "GET", path: "/v1/customers/customer-1/accounts", header: server.APIKeyHeader, key: testKey, status: http.StatusOK, accounts: 7},
+
+		// the default range is the 30 days to the server's clock
+		{method: "GET", path: "/v1/customers/customer-1/accounts/400107846787/transactions", status: http.StatusOK, transactions: 3},
+		{method: "GET", path: "/v1/customers/customer-1/accounts/400107846787/transactions?start=2014-04-24&end=2014-04-30", status: http.StatusOK, transactions: 2},
+		{method: "GET", path: "/v1/customers/customer-1/accounts/400107846787/transactions?start=2014-04-30&end=2014-04-01", status: http.StatusBadRequest},
+		{method: "GET", path: "/v1/customers/customer-1/accounts/400107846787/transactions?start=April", status: http.StatusBadRequest},
+		{method: "GET", path: "/v1/customers/customer-1/accounts/999/transactions", status: http.StatusNotFound},
+		{method: "GET", path: "/v1/customers/customer-1/accounts/checking/transactions", status: http.StatusNotFound},
+
+		{method: "POST", path: "/v1/customers/customer-1/logins/19034285/refresh", status: http.StatusAccepted, accounts: 4},
+		{method: "POST", path: "/v1/customers/customer-1/logins/999/refresh", status: http.StatusNotFound},
+		{method: "GET", path: "/v1/customers/customer-1/logins/19034285/refresh", status: http.StatusMethodNotAllowed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			req, err := http.NewRequest(test.method, srv.URL+test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			header, key := test.header, test.key
+			if header == "" && test.status != http.StatusUnauthorized {
+				header, key = server.APIKeyHeader, testKey
+			}
+			if header != "" {
+				req.Header.Set(header, key)
+			}
+
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var body struct {
+				Accounts []intuit.Account `json:"accounts"`
+				Error    struct {
+					Status  int    `json:"status"`
+					Message string `json:"message"`
+				} `json:"error"`
+
+				Banking []intuit.Transaction `json:"bankingTransactions"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil && resp.StatusCode != http.StatusMethodNotAllowed {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != test.status {
+				t.Fatalf("status %d (%q), want %d", resp.StatusCode, body.Error.Message, test.status)
+			}
+			if resp.StatusCode >= 400 && resp.StatusCode != http.StatusMethodNotAllowed && body.Error.Status != resp.StatusCode {
+				t.Errorf("error body status %d, want %d", body.Error.Status, resp.StatusCode)
+			}
+			if len(body.Accounts) != test.accounts {
+				t.Errorf("got %d accounts, want %d", len(body.Accounts), test.accounts)
+			}
+			if len(body.Banking) != test.transactions {
+				t.Errorf("got %d transactions, want %d", len(body.Banking), test.transactions)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package intuit
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SnapshotAlgorithm is the signature algorithm of signed snapshots
+const SnapshotAlgorithm = "Ed25519"
+
+// Snapshot is a point-in-time copy of a customer's accounts and transactions,
+// for storage outside CAD
+type Snapshot struct {
+	CustomerID   string                  `json:"customerId"`
+	TakenAt      time.Time               `json:"takenAt"`
+	Accounts     []Account               `json:"accounts"`
+	Transactions map[int64][]Transaction `json:"transactions"`
+}
+
+// Snapshot returns a snapshot of the accounts and transactions of the run
+func (r *PipelineRun) Snapshot() Snapshot {
+	return Snapshot{
+		CustomerID:   r.Client.CustomerID,
+		TakenAt:      r.Client.now().UTC(),
+		Accounts:     r.Accounts,
+		Transactions: r.Transactions,
+	}
+}
+
+// SignedEnvelope holds a JSON payload, such as a Snapshot, with an Ed25519
+// signature over its exact bytes
+type SignedEnvelope struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"keyId,omitempty"`
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+// SignSnapshot marshals `v`, typically a Snapshot or a changeset, to JSON and
+// returns a JSON SignedEnvelope holding it signed with `key`. `keyID`, if
+// non-empty, is recorded so verifiers can select the public key, e.g. after a
+// rotation.
+func SignSnapshot(key ed25519.PrivateKey, keyID string, v interface{}) ([]byte, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid Ed25519 private key")
+	}
+
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal snapshot: %w", err)
+	}
+
+	return json.Marshal(SignedEnvelope{
+		Algorithm: SnapshotAlgorithm,
+		KeyID:     keyID,
+		Payload:   payload,
+		Signature: ed25519.Sign(key, payload),
+	})
+}
+
+// VerifySnapshot checks the signature of a JSON SignedEnvelope against `pub`
+// and, if it is valid and `out` is non-nil, decodes the payload into out. It
+// returns the envelope's key ID.
+func VerifySnapshot(pub ed25519.PublicKey, envelope []byte, out interface{}) (keyID string, err error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return "", errors.New("invalid Ed25519 public key")
+	}
+
+	var e SignedEnvelope
+	if err := json.Unmarshal(envelope, &e); err != nil {
+		return "", fmt.Errorf("unable to parse envelope: %w", err)
+	}
+
+	if e.Algorithm != SnapshotAlgorithm {
+		return e.KeyID, fmt.Errorf("unsupported signature algorithm %q", e.Algorithm)
+	}
+
+	if !ed25519.Verify(pub, e.Payload, e.Signature) {
+		return e.KeyID, errors.New("snapshot signature does not verify")
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(e.Payload, out); err != nil {
+			return e.KeyID, fmt.Errorf("unable to decode snapshot: %w", err)
+		}
+	}
+
+	return e.KeyID, nil
+}
@@ -0,0 +1,205 @@
+// Package sqlitemirror keeps an offline copy of the CAD institution catalog in
+// a SQLite file, with a full-text index on institution names for pickers.
+//
+//	if err := sqlitemirror.MirrorInstitutions(ctx, client, "institutions.db"); err != nil {
+//		return err
+//	}
+//
+//	mirror, err := sqlitemirror.Open("institutions.db")
+//	if err != nil {
+//		return err
+//	}
+//	defer mirror.Close()
+//
+//	matches, err := mirror.Search(ctx, "first nat", 20)
+//
+// The package uses github.com/mattn/go-sqlite3, so it needs cgo. The index is
+// an FTS4 table, which that driver builds without extra tags.
+package sqlitemirror
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	intuit "github.com/bodetree/intuit-cad"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS institutions (
+	id       INTEGER PRIMARY KEY,
+	name     TEXT NOT NULL,
+	home_url TEXT NOT NULL,
+	details  TEXT NOT NULL
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS institutions_fts USING fts4(name, home_url);
+CREATE TABLE IF NOT EXISTS mirror (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+const refreshedAtKey = "refreshed_at"
+
+// MirrorInstitutions downloads the institution catalog with `client` and
+// writes it to the SQLite file at `path`, creating it if needed. The old
+// catalog is replaced in a single transaction, so readers see either it or the
+// new one, and the refresh time is recorded for RefreshedAt.
+func MirrorInstitutions(ctx context.Context, client *intuit.Client, path string) error {
+	institutions, err := client.InstitutionsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	mirror, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer mirror.Close()
+
+	return mirror.replace(ctx, institutions, time.Now())
+}
+
+// Mirror is an institution catalog mirrored by MirrorInstitutions
+type Mirror struct {
+	db *sql.DB
+}
+
+// Open opens or creates the mirror at `path`
+func Open(path string) (*Mirror, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Mirror{db: db}, nil
+}
+
+// Close closes the database
+func (m *Mirror) Close() error {
+	return m.db.Close()
+}
+
+func (m *Mirror) replace(ctx context.Context, institutions []intuit.InstitutionDetails, refreshedAt time.Time) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range []string{"DELETE FROM institutions", "DELETE FROM institutions_fts"} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	insert, err := tx.PrepareContext(ctx, "INSERT INTO institutions (id, name, home_url, details) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	index, err := tx.PrepareContext(ctx, "INSERT INTO institutions_fts (docid, name, home_url) VALUES (?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+
+	for _, institution := range institutions {
+		details, err := json.Marshal(institution)
+		if err != nil {
+			return err
+		}
+
+		if _, err := insert.ExecContext(ctx, institution.ID, institution.Name, institution.HomeURL, string(details)); err != nil {
+			return err
+		}
+		if _, err := index.ExecContext(ctx, institution.ID, institution.Name, institution.HomeURL); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO mirror (key, value) VALUES (?, ?)", refreshedAtKey, refreshedAt.UTC().Format(time.RFC3339Nano)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RefreshedAt returns when the catalog was last mirrored, or the zero time if
+// it never was
+func (m *Mirror) RefreshedAt(ctx context.Context) (time.Time, error) {
+	var value string
+	err := m.db.QueryRowContext(ctx, "SELECT value FROM mirror WHERE key = ?", refreshedAtKey).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// Search returns up to `limit` institutions whose name or home URL has words
+// starting with each word of `query`, ordered by name. A query with no words
+// matches nothing.
+func (m *Mirror) Search(ctx context.Context, query string, limit int) ([]intuit.InstitutionDetails, error) {
+	match := matchExpression(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT institutions.details
+		FROM institutions_fts
+		JOIN institutions ON institutions.id = institutions_fts.docid
+		WHERE institutions_fts MATCH ?
+		ORDER BY institutions.name, institutions.id
+		LIMIT ?`, match, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var institutions []intuit.InstitutionDetails
+	for rows.Next() {
+		var details string
+		if err := rows.Scan(&details); err != nil {
+			return nil, err
+		}
+
+		var institution intuit.InstitutionDetails
+		if err := json.Unmarshal([]byte(details), &institution); err != nil {
+			return nil, err
+		}
+		institutions = append(institutions, institution)
+	}
+
+	return institutions, rows.Err()
+}
+
+// matchExpression turns free text into an FTS prefix query, e.g. "First Nat."
+// into `first* nat*`. Anything but letters and digits separates words, so user
+// input can't inject FTS operators.
+func matchExpression(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + "*"
+	}
+
+	return strings.Join(words, " ")
+}
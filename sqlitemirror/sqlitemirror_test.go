@@ -0,0 +1,96 @@
+package sqlitemirror_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+	"github.com/bodetree/intuit-cad/intuittest"
+	"github.com/bodetree/intuit-cad/sqlitemirror"
+)
+
+func TestMirrorInstitutions(t *testing.T) {
+	srv := intuittest.NewServer()
+	defer srv.Close()
+
+	if err := srv.Seed("customer-1"); err != nil {
+		t.Fatal(err)
+	}
+	srv.AddInstitutions(
+		intuit.InstitutionDetails{ID: 100001, Name: "First National Bank", HomeURL: "https://fnb.example.com"},
+		intuit.InstitutionDetails{ID: 100002, Name: "First Credit Union", HomeURL: "https://fcu.example.com"},
+	)
+
+	client, err := srv.NewClient("customer-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "institutions.db")
+
+	// mirroring twice replaces the catalog rather than duplicating it
+	for i := 0; i < 2; i++ {
+		if err := sqlitemirror.MirrorInstitutions(ctx, client, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mirror, err := sqlitemirror.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mirror.Close()
+
+	refreshedAt, err := mirror.RefreshedAt(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if since := time.Since(refreshedAt); since < 0 || since > time.Minute {
+		t.Errorf("refreshed at %v, want about now", refreshedAt)
+	}
+
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"first", []int64{100002, 100001}},
+		{"First Nat", []int64{100001}},
+		{"cc", []int64{100000}},
+		{"fcu.example", []int64{100002}},
+		{`bank" OR "x`, nil},
+		{"  ", nil},
+	}
+
+	for _, test := range tests {
+		institutions, err := mirror.Search(ctx, test.query, 10)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", test.query, err)
+		}
+
+		var got []int64
+		for _, institution := range institutions {
+			got = append(got, institution.ID)
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+				break
+			}
+		}
+	}
+
+	institutions, err := mirror.Search(ctx, "cc bank", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(institutions) != 1 || institutions[0].Address.City != "Mountain View" {
+		t.Errorf("Search(cc bank) = %+v, want the fixture institution with its details", institutions)
+	}
+}
@@ -0,0 +1,312 @@
+package intuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults of Syncer
+const (
+	DefaultSyncLookback = time.Hour * 24 * 90
+	DefaultSyncOverlap  = time.Hour * 24 * 7
+)
+
+// Store persists customers' accounts and transactions for incremental syncing
+// with a Syncer. Implementations must be safe for concurrent use; see the
+// boltstore package for one backed by a file.
+type Store interface {
+	// UpsertAccounts stores the accounts, replacing any stored with the same
+	// IDs
+	UpsertAccounts(customerID string, accounts []Account) error
+
+	// UpsertTransactions stores the account's transactions, replacing any
+	// stored with the same Key, and records `syncedAt` as the account's last
+	// sync time. Pending transactions in `txns` replace all of the account's
+	// stored pending transactions, since pending transactions are fetched in
+	// full on every sync and change keys once they post.
+	UpsertTransactions(customerID string, accountID int64, txns []Transaction, syncedAt time.Time) error
+
+	// LastSyncTime returns the account's last sync time, or the zero time if
+	// it has not been synced
+	LastSyncTime(customerID string, accountID int64) (time.Time, error)
+}
+
+// MemoryStore is a Store holding everything in memory, mostly for tests
+type MemoryStore struct {
+	mu        sync.Mutex
+	customers map[string]*memoryCustomer
+}
+
+type memoryCustomer struct {
+	paused       bool
+	accounts     map[int64]Account
+	transactions map[int64]map[string]Transaction
+	synced       map[int64]time.Time
+}
+
+// NewMemoryStore returns an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{customers: map[string]*memoryCustomer{}}
+}
+
+func (s *MemoryStore) customer(customerID string) *memoryCustomer {
+	c, ok := s.customers[customerID]
+	if !ok {
+		c = &memoryCustomer{
+			accounts:     map[int64]Account{},
+			transactions: map[int64]map[string]Transaction{},
+			synced:       map[int64]time.Time{},
+		}
+		s.customers[customerID] = c
+	}
+
+	return c
+}
+
+// UpsertAccounts implements Store
+func (s *MemoryStore) UpsertAccounts(customerID string, accounts []Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.customer(customerID)
+	for _, account := range accounts {
+		c.accounts[account.ID] = account
+	}
+
+	return nil
+}
+
+// UpsertTransactions implements Store
+func (s *MemoryStore) UpsertTransactions(customerID string, accountID int64, txns []Transaction, syncedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.customer(customerID)
+
+	stored, ok := c.transactions[accountID]
+	if !ok {
+		stored = map[string]Transaction{}
+		c.transactions[accountID] = stored
+	}
+
+	for key, t := range stored {
+		if t.Pending {
+			delete(stored, key)
+		}
+	}
+
+	for _, t := range txns {
+		stored[t.Key()] = t
+	}
+
+	c.synced[accountID] = syncedAt
+
+	return nil
+}
+
+// LastSyncTime implements Store
+func (s *MemoryStore) LastSyncTime(customerID string, accountID int64) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.customer(customerID).synced[accountID], nil
+}
+
+// Paused implements PauseStore
+func (s *MemoryStore) Paused(customerID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.customer(customerID).paused, nil
+}
+
+// SetPaused implements PauseStore
+func (s *MemoryStore) SetPaused(customerID string, paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.customer(customerID).paused = paused
+
+	return nil
+}
+
+// Accounts returns the customer's stored accounts ordered by ID
+func (s *MemoryStore) Accounts(customerID string) []Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var accounts []Account
+	for _, account := range s.customer(customerID).accounts {
+		accounts = append(accounts, account)
+	}
+
+	sortAccounts(accounts)
+
+	return accounts
+}
+
+// Transactions returns the account's stored transactions ordered by posted
+// date
+func (s *MemoryStore) Transactions(customerID string, accountID int64) Transactions {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var txns Transactions
+	for _, t := range s.customer(customerID).transactions[accountID] {
+		txns = append(txns, t)
+	}
+
+	sort.SliceStable(txns, func(i, j int) bool { return txns[i].PostedDate.Before(txns[j].PostedDate) })
+
+	return txns
+}
+
+// SyncResult is the outcome of Syncer.Sync
+type SyncResult struct {
+	// Accounts is the number of accounts stored
+	Accounts int
+
+	// Transactions is the number of transactions fetched and stored
+	Transactions int
+
+	// Failed holds the error of each account that could not be synced
+	Failed map[int64]error
+}
+
+// Syncer incrementally copies a customer's accounts and transactions into a
+// Store. Each sync fetches an account's transactions from shortly before its
+// last sync time, so that transactions posting late are still picked up, or
+// from Lookback ago if it has never been synced.
+type Syncer struct {
+	Client *Client
+	Store  Store
+
+	// Lookback is how far back the first sync of an account reaches. If zero,
+	// DefaultSyncLookback is used.
+	Lookback time.Duration
+
+	// Overlap is how far before the last sync time later syncs start. If
+	// zero, DefaultSyncOverlap is used.
+	Overlap time.Duration
+
+	// Projection selects the parts of each transaction that are decoded and
+	// stored. The zero value, ProjectFull, decodes them all.
+	Projection Projection
+
+	// Pauses, if set, is checked before syncing; a paused customer's sync
+	// fails with ErrCustomerPaused. NewSyncer sets it to the store if the
+	// store is also a PauseStore.
+	Pauses PauseStore
+}
+
+// NewSyncer returns a syncer for the client's customer with the default
+// settings
+func NewSyncer(c *Client, store Store) *Syncer {
+	pauses, _ := store.(PauseStore)
+
+	return &Syncer{Client: c, Store: store, Pauses: pauses}
+}
+
+// Sync stores the customer's accounts and the new transactions of their active
+// accounts. An account that fails to sync is reported in the result's Failed
+// map and does not stop the others; an error is returned only if the accounts
+// themselves cannot be fetched or stored, the customer is paused, or ctx is
+// done. If ctx is done before every active account is synced, the result so
+// far is returned with a *CancelledError counting active accounts.
+func (s *Syncer) Sync(ctx context.Context) (result *SyncResult, err error) {
+	if metrics, ok := s.Client.Metrics.(CohortMetrics); ok {
+		defer func() { metrics.ObserveSync(s.Client.Cohort, result, err) }()
+	}
+
+	customerID := s.Client.CustomerID
+
+	if s.Pauses != nil {
+		paused, err := s.Pauses.Paused(customerID)
+		if err != nil {
+			return nil, fmt.Errorf("loading pause state: %w", err)
+		}
+		if paused {
+			return nil, ErrCustomerPaused
+		}
+	}
+
+	accounts, err := s.Client.GetCustomerAccountsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Store.UpsertAccounts(customerID, accounts); err != nil {
+		return nil, fmt.Errorf("storing accounts: %w", err)
+	}
+
+	var active []int64
+	for _, account := range accounts {
+		if account.IsActive() {
+			active = append(active, account.ID)
+		}
+	}
+
+	result = &SyncResult{Accounts: len(accounts), Failed: map[int64]error{}}
+	for i, accountID := range active {
+		if err := ctx.Err(); err != nil {
+			return result, &CancelledError{Completed: i, Remaining: len(active) - i, Err: err}
+		}
+
+		n, err := s.syncAccount(ctx, customerID, accountID)
+		if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+			// cut short by the cancellation; counted as remaining
+			return result, &CancelledError{Completed: i, Remaining: len(active) - i, Err: ctx.Err()}
+		}
+
+		if err != nil {
+			result.Failed[accountID] = err
+			continue
+		}
+
+		result.Transactions += n
+	}
+
+	return result, nil
+}
+
+// syncAccount fetches and stores the account's new transactions, returning
+// how many there were
+func (s *Syncer) syncAccount(ctx context.Context, customerID string, accountID int64) (int, error) {
+	syncedAt := s.Client.now()
+
+	last, err := s.Store.LastSyncTime(customerID, accountID)
+	if err != nil {
+		return 0, fmt.Errorf("loading last sync time: %w", err)
+	}
+
+	lookback := s.Lookback
+	if lookback <= 0 {
+		lookback = DefaultSyncLookback
+	}
+
+	overlap := s.Overlap
+	if overlap <= 0 {
+		overlap = DefaultSyncOverlap
+	}
+
+	start := syncedAt.Add(-lookback)
+	if !last.IsZero() {
+		start = last.Add(-overlap)
+	}
+
+	list, err := s.Client.AccountTransactionsProjectedContext(ctx, accountID, DateOf(start.UTC()), DateOf(syncedAt.UTC()), s.Projection)
+	if err != nil {
+		return 0, err
+	}
+
+	txns := list.All()
+	if err := s.Store.UpsertTransactions(customerID, accountID, txns, syncedAt); err != nil {
+		return 0, fmt.Errorf("storing transactions: %w", err)
+	}
+
+	return len(txns), nil
+}
@@ -0,0 +1,153 @@
+package intuit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+func TestSyncerCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the third active account's transactions are never fetched
+	cancelOn := func(next http.RoundTripper) http.RoundTripper {
+		return intuit.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/accounts/400107846789/transactions" {
+				cancel()
+				return nil, req.Context().Err()
+			}
+
+			return next.RoundTrip(req)
+		})
+	}
+
+	clock := intuit.NewManualClock(time.Date(2014, 4, 25, 0, 0, 0, 0, time.UTC))
+	_, client := newTestServer(t, intuit.WithMiddleware(cancelOn), intuit.WithClock(clock))
+
+	store := intuit.NewMemoryStore()
+	result, err := intuit.NewSyncer(client, store).Sync(ctx)
+
+	var cancelled *intuit.CancelledError
+	if !errors.As(err, &cancelled) {
+		t.Fatalf("Sync() error = %v, want a CancelledError", err)
+	}
+	if cancelled.Completed != 2 || cancelled.Remaining != 4 {
+		t.Errorf("error %v, want 2 of 6 accounts complete", err)
+	}
+
+	if result == nil || result.Accounts != 7 || len(result.Failed) != 0 {
+		t.Fatalf("result %+v, want 7 accounts and no failures", result)
+	}
+	if result.Transactions != 3 {
+		t.Errorf("synced %d transactions, want the 3 of the first account", result.Transactions)
+	}
+}
+
+func TestSyncerPaused(t *testing.T) {
+	clock := intuit.NewManualClock(time.Date(2014, 4, 25, 0, 0, 0, 0, time.UTC))
+	_, client := newTestServer(t, intuit.WithClock(clock))
+
+	store := intuit.NewMemoryStore()
+	if err := store.SetPaused(testCustomer, true); err != nil {
+		t.Fatal(err)
+	}
+
+	syncer := intuit.NewSyncer(client, store)
+	if _, err := syncer.Sync(context.Background()); !errors.Is(err, intuit.ErrCustomerPaused) {
+		t.Fatalf("Sync() error = %v, want ErrCustomerPaused", err)
+	}
+	if accounts := store.Accounts(testCustomer); len(accounts) != 0 {
+		t.Errorf("stored %d accounts while paused, want none", len(accounts))
+	}
+
+	if err := store.SetPaused(testCustomer, false); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := syncer.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Accounts != 7 {
+		t.Errorf("synced %d accounts after resuming, want 7", result.Accounts)
+	}
+}
+
+// storedIDs returns the IDs of the stored transactions of testBankingAccount
+// in order, since those posted on the same day are stored in no set order
+func storedIDs(store *intuit.MemoryStore) []int64 {
+	ids := transactionIDs(store.Transactions(testCustomer, testBankingAccount))
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func TestSyncerIncremental(t *testing.T) {
+	var mu sync.Mutex
+	starts := map[string][]string{}
+	record := func(next http.RoundTripper) http.RoundTripper {
+		return intuit.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if start := req.URL.Query().Get("txnStartDate"); start != "" {
+				mu.Lock()
+				starts[req.URL.Path] = append(starts[req.URL.Path], start)
+				mu.Unlock()
+			}
+
+			return next.RoundTrip(req)
+		})
+	}
+
+	clock := intuit.NewManualClock(time.Date(2014, 4, 25, 0, 0, 0, 0, time.UTC))
+	srv, client := newTestServer(t, intuit.WithMiddleware(record), intuit.WithClock(clock))
+
+	store := intuit.NewMemoryStore()
+	syncer := intuit.NewSyncer(client, store)
+
+	if _, err := syncer.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := storedIDs(store), []int64{900001, 900002, 900003}; !equalIDs(got, want) {
+		t.Fatalf("stored %v after the first sync, want %v", got, want)
+	}
+
+	clock.Advance(48 * time.Hour)
+	srv.AddTransactions(testCustomer, testBankingAccount, "bankingTransactions", intuit.Transaction{
+		ID:                       900004,
+		InstitutionTransactionID: "INTUIT-BANK-0004",
+		PostedDate:               intuit.Timestamp(time.Date(2014, 4, 26, 9, 0, 0, 0, time.UTC)),
+	})
+
+	result, err := syncer.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Accounts != 7 || len(result.Failed) != 0 {
+		t.Errorf("result %+v, want 7 accounts and no failures", result)
+	}
+
+	// the second sync starts an overlap before the first, not a lookback ago
+	path := fmt.Sprintf("/accounts/%d/transactions", testBankingAccount)
+	if want := []string{"2014-01-25", "2014-04-18"}; fmt.Sprint(starts[path]) != fmt.Sprint(want) {
+		t.Errorf("fetched from %v, want %v", starts[path], want)
+	}
+
+	if got, want := storedIDs(store), []int64{900001, 900002, 900003, 900004}; !equalIDs(got, want) {
+		t.Errorf("stored %v after the second sync, want %v", got, want)
+	}
+
+	last, err := store.LastSyncTime(testCustomer, testBankingAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := clock.Now(); !last.Equal(want) {
+		t.Errorf("last sync time %v, want %v", last, want)
+	}
+}
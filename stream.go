@@ -0,0 +1,242 @@
+package intuit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// TransactionIterator yields the transactions of a payload one at a time.
+//
+//	it, err := c.StreamAccountTransactions(ctx, accountID, start, end)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//
+//	for it.Next() {
+//		process(it.Key(), it.Transaction())
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type TransactionIterator interface {
+	// Next advances to the next transaction, returning false when there are
+	// no more or an error occurred
+	Next() bool
+
+	// Transaction returns the current transaction
+	Transaction() Transaction
+
+	// Key returns the payload key of the current transaction, e.g.
+	// bankingTransactions
+	Key() string
+
+	// Err returns the error that stopped iteration, if any
+	Err() error
+
+	// Close releases the underlying response
+	Close() error
+}
+
+// StreamAccountTransactions is like AccountTransactionsForDatesContext, but
+// decodes the payload incrementally, holding only one transaction in memory at
+// a time. Use it for accounts with very long histories. The call's span, and
+// any timeout set with WithTimeout, last until the iterator is closed.
+func (c *Client) StreamAccountTransactions(ctx context.Context, accountID int64, start, end Date, opts ...RequestOption) (_ TransactionIterator, err error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	ctx, span := c.startSpan(ctx, "StreamAccountTransactions", SpanKindInternal, traceTransactions(accountID, start, end)...)
+
+	finish := func(err error) {
+		endSpan(span, err)
+		cancel()
+	}
+
+	// once the iterator is returned, its Close finishes the call
+	defer func() {
+		if err != nil {
+			finish(err)
+		}
+	}()
+
+	req, err := c.transactionsRequest(accountID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := newAPIError(resp)
+		resp.Body.Close()
+		return nil, apiErr
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	if err := expectDelim(decoder, '{'); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return &transactionStream{body: resp.Body, decoder: decoder, finish: finish}, nil
+}
+
+// transactionStream implements TransactionIterator over a JSON object whose
+// transaction keys hold arrays of transactions
+type transactionStream struct {
+	body    io.Closer
+	decoder *json.Decoder
+	finish  func(error)
+
+	key     string
+	newFn   func() interface{}
+	inArray bool
+	done    bool
+
+	current Transaction
+	err     error
+}
+
+func (s *transactionStream) Next() bool {
+	if s.done {
+		return false
+	}
+
+	for {
+		if s.inArray {
+			if s.decoder.More() {
+				return s.decodeTransaction()
+			}
+
+			// consume the closing bracket
+			if err := expectDelim(s.decoder, ']'); err != nil {
+				return s.fail(err)
+			}
+			s.inArray = false
+		}
+
+		if !s.decoder.More() {
+			if err := expectDelim(s.decoder, '}'); err != nil {
+				return s.fail(err)
+			}
+
+			s.done = true
+			return false
+		}
+
+		token, err := s.decoder.Token()
+		if err != nil {
+			return s.fail(err)
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return s.fail(fmt.Errorf("unexpected token %v", token))
+		}
+
+		if !includesTransactionKey(key) {
+			var skipped json.RawMessage
+			if err := s.decoder.Decode(&skipped); err != nil {
+				return s.fail(err)
+			}
+			continue
+		}
+
+		// a null list, which CAD sends for accounts without transactions, is
+		// empty
+		token, err = s.decoder.Token()
+		if err != nil {
+			return s.fail(fmt.Errorf("decoding %s: %w", key, err))
+		}
+		if token == nil {
+			continue
+		}
+		if d, ok := token.(json.Delim); !ok || d != '[' {
+			return s.fail(fmt.Errorf("decoding %s: expected [, got %v", key, token))
+		}
+
+		s.key = key
+		s.newFn = transactionType(key)
+		s.inArray = true
+	}
+}
+
+func (s *transactionStream) decodeTransaction() bool {
+	var raw json.RawMessage
+	if err := s.decoder.Decode(&raw); err != nil {
+		return s.fail(err)
+	}
+
+	var t Transaction
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return s.fail(fmt.Errorf("decoding %s: %w", s.key, err))
+	}
+
+	if s.newFn != nil {
+		details := s.newFn()
+		if err := json.Unmarshal(raw, details); err != nil {
+			return s.fail(fmt.Errorf("decoding %s: %w", s.key, err))
+		}
+		t.Details = details
+	}
+
+	s.current = t
+
+	return true
+}
+
+func (s *transactionStream) fail(err error) bool {
+	s.err = err
+	s.done = true
+
+	return false
+}
+
+func (s *transactionStream) Transaction() Transaction {
+	return s.current
+}
+
+func (s *transactionStream) Key() string {
+	return s.key
+}
+
+func (s *transactionStream) Err() error {
+	return s.err
+}
+
+func (s *transactionStream) Close() error {
+	s.done = true
+	err := s.body.Close()
+
+	if s.finish != nil {
+		s.finish(s.err)
+		s.finish = nil
+	}
+
+	return err
+}
+
+// expectDelim reads the next token, failing unless it is the delimiter `delim`
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		if err == io.EOF {
+			return errors.New("unexpected end of payload")
+		}
+		return err
+	}
+
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package intuit_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+func TestStreamAccountTransactions(t *testing.T) {
+	_, client := newTestServer(t)
+
+	it, err := client.StreamAccountTransactions(context.Background(), testBankingAccount, testStart, testEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+
+	var ids []int64
+	for it.Next() {
+		if it.Key() != "bankingTransactions" {
+			t.Errorf("Key() = %q, want bankingTransactions", it.Key())
+		}
+		ids = append(ids, it.Transaction().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []int64{900001, 900002, 900003}; !equalIDs(ids, want) {
+		t.Errorf("streamed IDs %v, want %v", ids, want)
+	}
+}
+
+func TestStreamAccountTransactionsPayloads(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    []int64
+		wantErr string
+	}{
+		{"empty", `{}`, nil, ""},
+		{"null list", `{"bankingTransactions": null, "creditCardTransactions": [{"id": 1}]}`, []int64{1}, ""},
+		{"other keys", `{"notes": {"a": [1]}, "bankingTransactions": [{"id": 1}, {"id": 2}]}`, []int64{1, 2}, ""},
+		{"not a list", `{"bankingTransactions": 3}`, nil, "decoding bankingTransactions: expected [, got 3"},
+		{"truncated", `{"bankingTransactions": [{"id": 1}`, []int64{1}, "unexpected end of JSON input"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, client := newTestServer(t, intuit.WithMiddleware(respondToTransactions(test.payload)))
+
+			it, err := client.StreamAccountTransactions(context.Background(), testBankingAccount, testStart, testEnd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer it.Close()
+
+			var ids []int64
+			for it.Next() {
+				ids = append(ids, it.Transaction().ID)
+			}
+
+			if !equalIDs(ids, test.want) {
+				t.Errorf("streamed IDs %v, want %v", ids, test.want)
+			}
+
+			var gotErr string
+			if err := it.Err(); err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != test.wantErr {
+				t.Errorf("Err() = %q, want %q", gotErr, test.wantErr)
+			}
+		})
+	}
+}
+
+// respondToTransactions returns middleware that answers transaction requests
+// with `payload`
+func respondToTransactions(payload string) intuit.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return intuit.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasSuffix(req.URL.Path, "/transactions") {
+				return next.RoundTrip(req)
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       ioutil.NopCloser(strings.NewReader(payload)),
+				Request:    req,
+			}, nil
+		})
+	}
+}
+
+func equalIDs(got, want []int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+
+	return true
+}
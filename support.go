@@ -0,0 +1,170 @@
+package intuit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RequestHistorySize is the number of recent requests each client remembers
+// for support bundles
+const RequestHistorySize = 100
+
+// redactedHeaders are replaced with "REDACTED" in request records
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// RequestRecord describes one HTTP request sent by a client
+type RequestRecord struct {
+	Time      time.Time         `json:"time"`
+	Duration  time.Duration     `json:"durationNanos"`
+	Method    string            `json:"method"`
+	Endpoint  string            `json:"endpoint"`
+	Status    int               `json:"status,omitempty"`
+	IntuitTID string            `json:"intuitTid,omitempty"`
+	Headers   map[string]string `json:"headers"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// requestHistory is a ring buffer of the most recent request records
+type requestHistory struct {
+	mu      sync.Mutex
+	records []RequestRecord
+	next    int
+}
+
+func (h *requestHistory) add(r RequestRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.records) < RequestHistorySize {
+		h.records = append(h.records, r)
+		return
+	}
+
+	h.records[h.next] = r
+	h.next = (h.next + 1) % RequestHistorySize
+}
+
+func (h *requestHistory) since(t time.Time) []RequestRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var records []RequestRecord
+	for _, r := range h.records {
+		if !r.Time.Before(t) {
+			records = append(records, r)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+
+	return records
+}
+
+// RequestHistory returns the client's recent requests sent at or after `since`,
+// oldest first. Up to RequestHistorySize requests are kept.
+func (c *Client) RequestHistory(since time.Time) []RequestRecord {
+	return c.history.since(since)
+}
+
+// roundTrip sends the request through the client's HTTP client and records it
+// in the request history
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	start := c.now()
+	resp, err := c.httpClient().Do(req)
+
+	record := RequestRecord{
+		Time:     start,
+		Duration: c.now().Sub(start),
+		Method:   req.Method,
+		Endpoint: req.URL.Path,
+		Headers:  make(map[string]string, len(req.Header)),
+	}
+
+	for name, values := range req.Header {
+		if redactedHeaders[name] {
+			record.Headers[name] = "REDACTED"
+		} else if len(values) > 0 {
+			record.Headers[name] = values[0]
+		}
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.Status = resp.StatusCode
+		record.IntuitTID = resp.Header.Get("intuit_tid")
+	}
+
+	c.history.add(record)
+	c.logResponse(req, "CAD request", resp, err, record.Duration)
+	c.observeRequest(req, resp, err, record.Duration)
+	c.debugFailure(req, resp, err, start, record.Duration)
+
+	return resp, err
+}
+
+// SupportBundle is the information Intuit support asks for when investigating
+// a customer's failing requests
+type SupportBundle struct {
+	GeneratedAt time.Time       `json:"generatedAt"`
+	CustomerID  string          `json:"customerId"`
+	Environment SupportEnv      `json:"environment"`
+	Requests    []RequestRecord `json:"requests"`
+}
+
+// SupportEnv describes the environment a support bundle was generated in
+type SupportEnv struct {
+	BaseURL        string `json:"baseUrl"`
+	TokenURL       string `json:"tokenUrl"`
+	SAMLProviderID string `json:"samlProviderId"`
+	ConsumerKey    string `json:"consumerKey"`
+	GoVersion      string `json:"goVersion"`
+	Platform       string `json:"platform"`
+}
+
+// SupportBundle returns a JSON support bundle for the customer holding the
+// requests their cached client sent at or after `since`, with credentials
+// redacted. It fails if the manager has no cached client for the customer.
+func (m *Manager) SupportBundle(ctx context.Context, customerID string, since time.Time) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	cache := m.cache()
+	if cache == nil {
+		return nil, errors.New("manager has no client cache to collect request history from")
+	}
+
+	c, ok := cache.Get(customerID)
+	if !ok {
+		return nil, fmt.Errorf("no cached client for customer %s", m.customerLabel(customerID))
+	}
+
+	bundle := SupportBundle{
+		GeneratedAt: c.now().UTC(),
+		CustomerID:  customerID,
+		Environment: SupportEnv{
+			BaseURL:        c.url(""),
+			TokenURL:       c.tokenURL(),
+			SAMLProviderID: c.SAMLProviderID,
+			ConsumerKey:    c.ConsumerKey,
+			GoVersion:      runtime.Version(),
+			Platform:       runtime.GOOS + "/" + runtime.GOARCH,
+		},
+		Requests: c.RequestHistory(since),
+	}
+
+	return json.MarshalIndent(bundle, "", "  ")
+}
@@ -0,0 +1,72 @@
+package intuit
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTokenNotFound is returned by TokenStore.Load when no token is stored for
+// the customer
+var ErrTokenNotFound = errors.New("intuit: token not found")
+
+// TokenStore persists the OAuth tokens acquired through the SAML exchange, e.g.
+// in Redis or a database, so that they survive process restarts. Tokens are
+// secrets and should be stored accordingly. Implementations must be safe for
+// concurrent use.
+type TokenStore interface {
+	// Load returns the stored token for the customer, or ErrTokenNotFound
+	Load(customerID string) (token, secret string, issuedAt time.Time, err error)
+
+	// Save stores a newly acquired token for the customer, replacing any
+	// existing one
+	Save(customerID, token, secret string, issuedAt time.Time) error
+}
+
+// WithTokenStore sets the store the client loads and saves OAuth tokens with
+func WithTokenStore(store TokenStore) Option {
+	return func(c *Client) {
+		c.TokenStore = store
+	}
+}
+
+// MemoryTokenStore is a TokenStore holding tokens in memory. It is mostly
+// useful for sharing tokens between managers in one process and for tests.
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]storedToken
+}
+
+type storedToken struct {
+	token    string
+	secret   string
+	issuedAt time.Time
+}
+
+// NewMemoryTokenStore returns an empty MemoryTokenStore
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: map[string]storedToken{}}
+}
+
+// Load implements TokenStore
+func (s *MemoryTokenStore) Load(customerID string) (string, string, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.tokens[customerID]
+	if !ok {
+		return "", "", time.Time{}, ErrTokenNotFound
+	}
+
+	return stored.token, stored.secret, stored.issuedAt, nil
+}
+
+// Save implements TokenStore
+func (s *MemoryTokenStore) Save(customerID, token, secret string, issuedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[customerID] = storedToken{token: token, secret: secret, issuedAt: issuedAt}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package intuit
+
+import (
+	"context"
+	"net/http"
+)
+
+// SpanKind says whether a span covers work inside the client or a request to
+// a remote server
+type SpanKind int
+
+// Constants representing span kinds
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindClient
+)
+
+// SpanAttribute is an attribute of a span. Value is a string, int or int64.
+type SpanAttribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer records spans for a client's API calls, token exchanges and
+// requests. Implementations must be safe for concurrent use; see the
+// intuitotel package for an OpenTelemetry adapter.
+type Tracer interface {
+	// Start starts a span as a child of any span in ctx, and returns a
+	// context holding the new span
+	Start(ctx context.Context, name string, kind SpanKind, attrs ...SpanAttribute) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer
+type Span interface {
+	SetAttributes(attrs ...SpanAttribute)
+
+	// Fail marks the span as failed without an error, e.g. for an error
+	// response
+	Fail(description string)
+
+	// End records err on the span, if it is not nil, and ends it
+	End(err error)
+}
+
+// WithTracer records spans for the client's API calls, token exchanges and
+// requests with `tracer`. Spans carry the customer's CustomerLabel, so enable
+// privacy mode to keep raw customer IDs out of traces. A nil tracer disables
+// tracing.
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) {
+		c.Tracer = tracer
+	}
+}
+
+// nopTracer starts spans that record nothing
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, _ string, _ SpanKind, _ ...SpanAttribute) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...SpanAttribute) {}
+func (nopSpan) Fail(string)                    {}
+func (nopSpan) End(error)                      {}
+
+// startSpan starts a span named `name` with the customer's label
+func (c *Client) startSpan(ctx context.Context, name string, kind SpanKind, attrs ...SpanAttribute) (context.Context, Span) {
+	var tracer Tracer = nopTracer{}
+	if c.Tracer != nil {
+		tracer = c.Tracer
+	}
+
+	attrs = append(attrs, SpanAttribute{"intuit.customer", c.CustomerLabel()})
+
+	return tracer.Start(ctx, name, kind, attrs...)
+}
+
+// endSpan records err on the span, if any, and ends it
+func endSpan(span Span, err error) {
+	span.End(err)
+}
+
+// traceTransactions returns the span attributes of a transactions call
+func traceTransactions(accountID int64, start, end Date) []SpanAttribute {
+	return []SpanAttribute{
+		{"intuit.account_id", accountID},
+		{"intuit.start_date", start.String()},
+		{"intuit.end_date", end.String()},
+	}
+}
+
+// traceRequest starts the span of one request sent by do
+func (c *Client) traceRequest(req *http.Request) (*http.Request, Span) {
+	ctx, span := c.startSpan(req.Context(), "CAD "+req.Method, SpanKindClient,
+		SpanAttribute{"http.request.method", req.Method},
+		SpanAttribute{"intuit.endpoint", req.URL.Path},
+	)
+
+	return req.WithContext(ctx), span
+}
+
+// endRequestSpan records the outcome of a request and the number of times it
+// was retried, and ends its span
+func endRequestSpan(span Span, resp *http.Response, retries int, err error) {
+	span.SetAttributes(SpanAttribute{"intuit.retries", retries})
+
+	if resp != nil {
+		span.SetAttributes(SpanAttribute{"http.response.status_code", resp.StatusCode})
+		if err == nil && resp.StatusCode >= 400 {
+			span.Fail(resp.Status)
+		}
+	}
+
+	endSpan(span, err)
+}
@@ -1,25 +1,56 @@
 package intuit
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
+var (
+	transactionTypesMu sync.RWMutex
+	transactionTypes   = map[string]func() interface{}{}
+)
+
+// RegisterTransactionType associates a top-level key in the transactions
+// payload (e.g. "cryptoTransactions") with a Go type, so that payload additions
+// can be decoded without waiting for a new release of this package.
+//
+// When a payload contains the key, each of its transactions is decoded into
+// the common Transaction fields as usual and additionally into a value returned
+// by `newFn`, which is stored in Transaction.Details. `newFn` must return a
+// pointer. Registered keys are decoded even if they do not end with
+// "Transactions".
+func RegisterTransactionType(key string, newFn func() interface{}) {
+	transactionTypesMu.Lock()
+	defer transactionTypesMu.Unlock()
+
+	transactionTypes[key] = newFn
+}
+
+func transactionType(key string) func() interface{} {
+	transactionTypesMu.RLock()
+	defer transactionTypesMu.RUnlock()
+
+	return transactionTypes[key]
+}
+
 // TransactionList is a map of transaction types to a slice of transactions
 type TransactionList map[string][]Transaction
 
 // UnmarshalJSON implements the json Unmarshaler interface. It will inspect all
 // of the top-level JSON object keys in the object. If a key ends with "Transactions"
 // (e.g. bankingTransactions), the key will be included in the TransactionList and
-// its value will be unmarshaled into a []Transaction
+// its value will be unmarshaled into a []Transaction. Keys registered with
+// RegisterTransactionType are included as well.
 //
-// TODO: this payload can contain an error key. Providing this back to the user
-// (without returning an error from UnmarshalJSON) will likely require breaking
-// changes to the TransactionList type.
+// Other keys, including the error key the payload can contain, are dropped.
+// Use FetchAccountTransactions to receive them as warnings.
 func (t TransactionList) UnmarshalJSON(data []byte) error {
 	var payload map[string]json.RawMessage
 
@@ -28,71 +59,150 @@ func (t TransactionList) UnmarshalJSON(data []byte) error {
 	}
 
 	for key, rawMessage := range payload {
-		if !strings.HasSuffix(key, "Transactions") {
+		if !includesTransactionKey(key) {
 			continue
 		}
 
+		newFn := transactionType(key)
+
 		var txns []Transaction
 		if err := json.Unmarshal(rawMessage, &txns); err != nil {
 			return err
 		}
 
+		if newFn != nil {
+			if err := decodeTransactionDetails(rawMessage, txns, newFn); err != nil {
+				return fmt.Errorf("decoding %s: %w", key, err)
+			}
+		}
+
 		t[key] = txns
 	}
 
 	return nil
 }
 
+// includesTransactionKey reports whether UnmarshalJSON keeps the key
+func includesTransactionKey(key string) bool {
+	return strings.HasSuffix(key, "Transactions") || transactionType(key) != nil
+}
+
+// decodeTransactionDetails decodes each element of the JSON array `data` into a
+// value from `newFn` and stores it in the Details field of the matching
+// transaction
+func decodeTransactionDetails(data []byte, txns []Transaction, newFn func() interface{}) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for i := range raw {
+		details := newFn()
+		if err := json.Unmarshal(raw[i], details); err != nil {
+			return err
+		}
+
+		txns[i].Details = details
+	}
+
+	return nil
+}
+
 // Transaction represents an individual transaction in a financial institution
 // account.
 type Transaction struct {
-	ID                       int64               `json:"id"`
-	InstitutionTransactionID string              `json:"institutionTransactionId"`
-	UserDate                 unixTimestampMillis `json:"userDate"`
-	PostedDate               unixTimestampMillis `json:"postedDate"`
-	CurrencyType             string              `json:"currencyType"`
-	PayeeName                string              `json:"payeeName"`
-	Amount                   float64             `json:"amount"`
-	Pending                  bool                `json:"pending"`
-
-	Categorization struct {
-		Common struct {
-			NormalizedPayeeName string `json:"normalizedPayeeName"`
-		} `json:"common"`
-
-		Context []struct {
-			Source       string `json:"source"`
-			CategoryName string `json:"categoryName"`
-			ScheduleC    string `json:"scheduleC"`
-		} `json:"context"`
-	} `json:"categorization"`
+	ID                       int64     `json:"id"`
+	InstitutionTransactionID string    `json:"institutionTransactionId"`
+	UserDate                 Timestamp `json:"userDate"`
+	PostedDate               Timestamp `json:"postedDate"`
+	CurrencyType             string    `json:"currencyType"`
+	PayeeName                string    `json:"payeeName"`
+	Amount                   Money     `json:"amount"`
+	Pending                  bool      `json:"pending"`
+
+	Categorization Categorization `json:"categorization"`
+
+	// Details holds the transaction decoded into the type registered for its
+	// payload key with RegisterTransactionType, if any
+	Details interface{} `json:"-"`
 }
 
-func (c *Client) AccountTransactions(accountID int64, startDate time.Time, endDate *time.Time) (TransactionList, error) {
-	req, err := c.request("GET", fmt.Sprintf("/accounts/%d/transactions", accountID), nil)
-	if err != nil {
-		return nil, err
+// Key identifies the transaction within its account: its institution
+// transaction ID, or its ID prefixed with "#" if the institution didn't
+// provide one
+func (t Transaction) Key() string {
+	if t.InstitutionTransactionID != "" {
+		return t.InstitutionTransactionID
 	}
 
-	const dateFormat = "2006-01-02"
-	query := url.Values{}
-	query.Set("txnStartDate", startDate.Format(dateFormat))
+	return fmt.Sprintf("#%d", t.ID)
+}
+
+// PostedDay returns the UTC date on which the transaction posted
+func (t Transaction) PostedDay() Date {
+	return DateOf(t.PostedDate.Time().UTC())
+}
+
+// UserDay returns the UTC date the user assigned to the transaction
+func (t Transaction) UserDay() Date {
+	return DateOf(t.UserDate.Time().UTC())
+}
+
+// ByPostedDay buckets every transaction in the list by the date it posted
+func (t TransactionList) ByPostedDay() map[Date][]Transaction {
+	days := map[Date][]Transaction{}
+	for _, txn := range t.All() {
+		day := txn.PostedDay()
+		days[day] = append(days[day], txn)
+	}
+
+	return days
+}
+
+// AccountTransactions returns the account's transactions starting on the date
+// of startDate and, if endDate is non-nil, ending on the date of endDate. Dates
+// are taken in the location of each time.Time; use AccountTransactionsForDates
+// to avoid zone handling altogether.
+func (c *Client) AccountTransactions(accountID int64, startDate time.Time, endDate *time.Time) (TransactionList, error) {
+	var end Date
 	if endDate != nil {
-		query.Set("txnEndDate", endDate.Format(dateFormat))
+		end = DateOf(*endDate)
 	}
-	req.URL.RawQuery = query.Encode()
 
-	resp, err := c.do(req)
+	return c.AccountTransactionsForDates(accountID, DateOf(startDate), end)
+}
+
+// AccountTransactionsForDates returns the account's transactions between start
+// and end, inclusive. A zero end date leaves the range open-ended.
+func (c *Client) AccountTransactionsForDates(accountID int64, start, end Date, opts ...RequestOption) (TransactionList, error) {
+	return c.AccountTransactionsForDatesContext(context.Background(), accountID, start, end, opts...)
+}
+
+// AccountTransactionsForDatesContext is like AccountTransactionsForDates, but
+// sends the request with ctx
+func (c *Client) AccountTransactionsForDatesContext(ctx context.Context, accountID int64, start, end Date, opts ...RequestOption) (list TransactionList, err error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	ctx, span := c.startSpan(ctx, "AccountTransactions", SpanKindInternal, traceTransactions(accountID, start, end)...)
+	defer func() { endSpan(span, err) }()
+
+	req, err := c.transactionsRequest(accountID, start, end)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("CAD API returned status code %d", resp.StatusCode)
+	resp, err := c.do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
 	}
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
 	decoder := json.NewDecoder(resp.Body)
 	decoder.UseNumber()
 
@@ -103,3 +213,97 @@ func (c *Client) AccountTransactions(accountID int64, startDate time.Time, endDa
 
 	return payload, nil
 }
+
+// TransactionsResult is the result of FetchAccountTransactions
+type TransactionsResult struct {
+	Transactions TransactionList
+	Result
+}
+
+// FetchAccountTransactions is like AccountTransactionsForDates, but reports the
+// parts of the payload that a TransactionList drops as warnings: an error
+// carried in the payload becomes a WarningPayloadError, and any other
+// unrecognized key a WarningUnknownField.
+func (c *Client) FetchAccountTransactions(accountID int64, start, end Date, opts ...RequestOption) (*TransactionsResult, error) {
+	return c.FetchAccountTransactionsContext(context.Background(), accountID, start, end, opts...)
+}
+
+// FetchAccountTransactionsContext is like FetchAccountTransactions, but sends
+// the request with ctx
+func (c *Client) FetchAccountTransactionsContext(ctx context.Context, accountID int64, start, end Date, opts ...RequestOption) (_ *TransactionsResult, err error) {
+	ctx, cancel := withRequestOptions(ctx, opts)
+	defer cancel()
+
+	ctx, span := c.startSpan(ctx, "FetchAccountTransactions", SpanKindInternal, traceTransactions(accountID, start, end)...)
+	defer func() { endSpan(span, err) }()
+
+	req, err := c.transactionsRequest(accountID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	var payload map[string]json.RawMessage
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	result := &TransactionsResult{Transactions: make(TransactionList)}
+	for key, rawMessage := range payload {
+		switch {
+		case includesTransactionKey(key):
+			continue
+		case key == "error" || key == "status":
+			result.Warn(WarningPayloadError, "%s: %s", key, rawMessage)
+		default:
+			result.Warn(WarningUnknownField, "dropped key %q", key)
+		}
+
+		delete(payload, key)
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder = json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&result.Transactions); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// transactionsRequest builds a request for the account's transactions between
+// start and end. A zero end date leaves the range open-ended.
+func (c *Client) transactionsRequest(accountID int64, start, end Date) (*http.Request, error) {
+	req, err := c.request("GET", fmt.Sprintf("/accounts/%d/transactions", accountID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("txnStartDate", start.String())
+	if !end.IsZero() {
+		query.Set("txnEndDate", end.String())
+	}
+	req.URL.RawQuery = query.Encode()
+
+	return req, nil
+}
@@ -1,19 +1,67 @@
 package intuit
 
 import (
+	"bytes"
 	"strconv"
 	"time"
 )
 
-type unixTimestampMillis time.Time
+// Timestamp is a time encoded by the CAD API as milliseconds since the Unix
+// epoch. A null or absent timestamp decodes to, and the zero time encodes as,
+// null.
+type Timestamp time.Time
+
+// Time returns the timestamp as a time.Time
+func (t Timestamp) Time() time.Time {
+	return time.Time(t)
+}
+
+// IsZero reports whether the timestamp is unset
+func (t Timestamp) IsZero() bool {
+	return time.Time(t).IsZero()
+}
+
+// Before reports whether t is before u
+func (t Timestamp) Before(u Timestamp) bool {
+	return time.Time(t).Before(time.Time(u))
+}
+
+// After reports whether t is after u
+func (t Timestamp) After(u Timestamp) bool {
+	return time.Time(t).After(time.Time(u))
+}
+
+// Equal reports whether t and u are the same instant
+func (t Timestamp) Equal(u Timestamp) bool {
+	return time.Time(t).Equal(time.Time(u))
+}
+
+func (t Timestamp) String() string {
+	return time.Time(t).String()
+}
+
+// UnmarshalJSON implements the json Unmarshaler interface
+func (t *Timestamp) UnmarshalJSON(strTime []byte) error {
+	if bytes.Equal(strTime, []byte("null")) {
+		*t = Timestamp{}
+		return nil
+	}
 
-func (t *unixTimestampMillis) UnmarshalJSON(strTime []byte) error {
 	intTime, err := strconv.ParseInt(string(strTime), 10, 64)
 	if err != nil {
 		return err
 	}
 
-	*t = unixTimestampMillis(time.Unix(intTime/1000, 0))
+	*t = Timestamp(time.UnixMilli(intTime))
 
 	return nil
 }
+
+// MarshalJSON implements the json Marshaler interface
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return strconv.AppendInt(nil, time.Time(t).UnixMilli(), 10), nil
+}
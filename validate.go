@@ -0,0 +1,108 @@
+package intuit
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ConfigFieldError describes an invalid client setting
+type ConfigFieldError struct {
+	Field   string
+	Problem string
+}
+
+func (e ConfigFieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Problem)
+}
+
+// ConfigErrors lists every invalid setting of a client
+type ConfigErrors []ConfigFieldError
+
+func (e ConfigErrors) Error() string {
+	problems := make([]string, len(e))
+	for i, fieldErr := range e {
+		problems[i] = fieldErr.Error()
+	}
+
+	return "invalid client configuration: " + strings.Join(problems, "; ")
+}
+
+// Validate checks the client's credentials and settings before any request is
+// signed, so that a missing key is reported instead of failing deep inside
+// assertion signing. It returns ConfigErrors listing every problem, or nil.
+// Init calls Validate.
+func (c *Client) Validate() error {
+	var errs ConfigErrors
+	problem := func(field, format string, args ...interface{}) {
+		errs = append(errs, ConfigFieldError{Field: field, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	if msg := customerIDProblem(c.CustomerID); msg != "" {
+		problem("CustomerID", "%s", msg)
+	}
+
+	if c.ConsumerKey == "" {
+		problem("ConsumerKey", "is required")
+	}
+	if c.ConsumerSecret == "" {
+		problem("ConsumerSecret", "is required")
+	}
+	if c.SAMLProviderID == "" {
+		problem("SAMLProviderID", "is required")
+	}
+
+	switch {
+	case c.KeyRing != nil:
+	case c.Signer != nil:
+		if _, ok := c.Signer.Public().(*rsa.PublicKey); !ok {
+			problem("Signer", "must have an RSA public key, not %T", c.Signer.Public())
+		}
+	case c.PrivateKey == nil:
+		problem("PrivateKey", "is required unless Signer or KeyRing is set")
+	}
+
+	if c.SigningHash != 0 {
+		if _, ok := signatureAlgorithms[c.SigningHash]; !ok {
+			problem("SigningHash", "%v is not supported", c.SigningHash)
+		}
+	}
+
+	if lifetime := c.AssertionOptions.Lifetime; lifetime != 0 {
+		if err := ValidateAssertionLifetime(lifetime); err != nil {
+			problem("AssertionOptions.Lifetime", "%s is outside the allowed range of %s to %s", lifetime, MinAssertionLifetime, MaxAssertionLifetime)
+		}
+	}
+
+	if c.AssertionOptions.ClockSkew < 0 {
+		problem("AssertionOptions.ClockSkew", "must not be negative")
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// customerIDProblem describes what is wrong with a customer ID, or returns ""
+// if it is well formed. Customer IDs become the NameID of SAML assertions, so
+// they must be non-empty and free of spaces and control characters.
+func customerIDProblem(customerID string) string {
+	switch {
+	case customerID == "":
+		return "is required"
+	case !utf8.ValidString(customerID):
+		return "is not valid UTF-8"
+	}
+
+	for _, r := range customerID {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return "contains spaces or control characters"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,159 @@
+package intuit
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	samlNamespace    = "urn:oasis:names:tc:SAML:2.0:assertion"
+	xmldsigNamespace = "http://www.w3.org/2000/09/xmldsig#"
+)
+
+// VerifyAssertion checks the enveloped xmldsig signature of a serialized SAML
+// assertion against `pub`. It canonicalizes the assertion and its SignedInfo
+// the same way Sign does, recomputes the reference digest and verifies the
+// signature value, returning an error describing the first mismatch. It is
+// meant for debugging assertions rejected by Intuit; it does not check the
+// assertion's conditions or lifetime.
+func VerifyAssertion(xmlBytes []byte, pub *rsa.PublicKey) error {
+	root, err := parseXML(xmlBytes)
+	if err != nil {
+		return fmt.Errorf("unable to parse assertion: %w", err)
+	}
+
+	if root.name.Local != "Assertion" || root.namespace() != samlNamespace {
+		return fmt.Errorf("root element is %s, not a SAML 2.0 Assertion", qualifiedName(root.name))
+	}
+
+	var sig *xmlElement
+	for _, child := range root.children {
+		if elem, ok := child.(*xmlElement); ok && elem.name.Local == "Signature" && elem.namespace() == xmldsigNamespace {
+			sig = elem
+			break
+		}
+	}
+	if sig == nil {
+		return errors.New("assertion is not signed")
+	}
+
+	si := sig.find(xmldsigNamespace, "SignedInfo")
+	if si == nil {
+		return errors.New("signature has no SignedInfo")
+	}
+
+	signatureMethod := attrValue(si.find(xmldsigNamespace, "SignatureMethod"), "Algorithm")
+	sigHash, ok := hashForAlgorithm(signatureMethod, func(alg signatureAlgorithm) string { return alg.signatureMethod })
+	if !ok {
+		return fmt.Errorf("unsupported signature method %q", signatureMethod)
+	}
+
+	canonicalizationMethod := attrValue(si.find(xmldsigNamespace, "CanonicalizationMethod"), "Algorithm")
+	if canonicalizationMethod != C14N {
+		return fmt.Errorf("unsupported canonicalization method %q", canonicalizationMethod)
+	}
+
+	ref := si.find(xmldsigNamespace, "Reference")
+	if ref == nil {
+		return errors.New("SignedInfo has no Reference")
+	}
+
+	if uri, id := attrValue(ref, "URI"), attrValue(root, "ID"); uri != "#"+id {
+		return fmt.Errorf("reference URI %q does not match assertion ID %q", uri, id)
+	}
+
+	if transforms := ref.find(xmldsigNamespace, "Transforms"); transforms != nil {
+		for _, child := range transforms.children {
+			if elem, ok := child.(*xmlElement); ok {
+				if alg := attrValue(elem, "Algorithm"); alg != XMLDSIGNS && alg != C14N {
+					return fmt.Errorf("unsupported transform %q", alg)
+				}
+			}
+		}
+	}
+
+	digestMethod := attrValue(ref.find(xmldsigNamespace, "DigestMethod"), "Algorithm")
+	digestHash, ok := hashForAlgorithm(digestMethod, func(alg signatureAlgorithm) string { return alg.digestMethod })
+	if !ok {
+		return fmt.Errorf("unsupported digest method %q", digestMethod)
+	}
+
+	expectedDigest := strings.TrimSpace(textContent(ref.find(xmldsigNamespace, "DigestValue")))
+
+	signatureValue, err := base64.StdEncoding.DecodeString(strings.TrimSpace(textContent(sig.find(xmldsigNamespace, "SignatureValue"))))
+	if err != nil {
+		return fmt.Errorf("unable to decode signature value: %w", err)
+	}
+
+	// canonicalize SignedInfo in place, before the signature is detached, so
+	// that namespace lookups see the same ancestors as the signer
+	signedInfoCanonical := si.canonicalize()
+
+	// apply the enveloped signature transform and compute the digest
+	root.remove(sig)
+	digest := digestHash.New()
+	digest.Write(root.canonicalize())
+
+	if computed := base64.StdEncoding.EncodeToString(digest.Sum(nil)); computed != expectedDigest {
+		return fmt.Errorf("digest mismatch: computed %s, assertion has %s", computed, expectedDigest)
+	}
+
+	hashed := sigHash.New()
+	hashed.Write(signedInfoCanonical)
+
+	if err := rsa.VerifyPKCS1v15(pub, sigHash, hashed.Sum(nil), signatureValue); err != nil {
+		return fmt.Errorf("signature value does not verify: %w", err)
+	}
+
+	return nil
+}
+
+// hashForAlgorithm returns the hash whose xmldsig algorithm URI, as selected by
+// `uri`, matches `alg`
+func hashForAlgorithm(alg string, uri func(signatureAlgorithm) string) (crypto.Hash, bool) {
+	for hashFunc, candidate := range signatureAlgorithms {
+		if uri(candidate) == alg {
+			return hashFunc, true
+		}
+	}
+
+	return 0, false
+}
+
+// attrValue returns the value of the element's unprefixed attribute `name`
+func attrValue(e *xmlElement, name string) string {
+	if e == nil {
+		return ""
+	}
+
+	for _, attr := range e.attrs {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+// textContent returns the concatenated character data of the element's subtree
+func textContent(e *xmlElement) string {
+	if e == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, child := range e.children {
+		switch c := child.(type) {
+		case string:
+			b.WriteString(c)
+		case *xmlElement:
+			b.WriteString(textContent(c))
+		}
+	}
+
+	return b.String()
+}
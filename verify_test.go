@@ -0,0 +1,208 @@
+package intuit
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testPrivateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := LoadPrivateKeyFromFile(filepath.Join("testdata", "keys", "pkcs8.pem"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+// signedAssertion returns a serialized assertion for customer-1, signed with
+// `key` using `hashFunc`
+func signedAssertion(t *testing.T, key *rsa.PrivateKey, hashFunc crypto.Hash) string {
+	t.Helper()
+
+	assertion := NewAssertion("issuer", "customer-1", DefaultAssertionLifetime)
+	if err := assertion.SignWith(key, hashFunc); err != nil {
+		t.Fatalf("SignWith() error = %v", err)
+	}
+
+	data, err := xml.Marshal(assertion)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	key := testPrivateKey(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tamper  func(string) string
+		pub     *rsa.PublicKey
+		wantErr string
+	}{
+		{
+			name: "unchanged",
+		},
+		{
+			name:   "reindented",
+			tamper: func(s string) string { return strings.Replace(s, "<Issuer", "\n<Issuer", 1) },
+			// whitespace is content, so it changes the digest
+			wantErr: "digest mismatch",
+		},
+		{
+			name:    "customer changed",
+			tamper:  func(s string) string { return strings.Replace(s, "customer-1", "customer-2", 1) },
+			wantErr: "digest mismatch",
+		},
+		{
+			name:    "signature value changed",
+			tamper:  func(s string) string { return strings.Replace(s, "<SignatureValue>", "<SignatureValue>AAAA", 1) },
+			wantErr: "signature value does not verify",
+		},
+		{
+			name:    "other key",
+			pub:     &otherKey.PublicKey,
+			wantErr: "signature value does not verify",
+		},
+		{
+			name: "reference changed",
+			tamper: func(s string) string {
+				return strings.Replace(s, `URI="#`, `URI="#x`, 1)
+			},
+			wantErr: "does not match assertion ID",
+		},
+		{
+			name: "unsigned",
+			tamper: func(s string) string {
+				return s[:strings.Index(s, "<Signature")] + s[strings.Index(s, "</Signature>")+len("</Signature>"):]
+			},
+			wantErr: "not signed",
+		},
+		{
+			name:    "not an assertion",
+			tamper:  func(string) string { return "<Response/>" },
+			wantErr: "not a SAML 2.0 Assertion",
+		},
+	}
+
+	for _, hashFunc := range []crypto.Hash{crypto.SHA1, crypto.SHA256} {
+		signed := signedAssertion(t, key, hashFunc)
+
+		for _, test := range tests {
+			t.Run(hashFunc.String()+"/"+test.name, func(t *testing.T) {
+				data := signed
+				if test.tamper != nil {
+					data = test.tamper(data)
+				}
+
+				pub := &key.PublicKey
+				if test.pub != nil {
+					pub = test.pub
+				}
+
+				err := VerifyAssertion([]byte(data), pub)
+				if test.wantErr == "" {
+					if err != nil {
+						t.Fatalf("VerifyAssertion() error = %v", err)
+					}
+					return
+				}
+
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("VerifyAssertion() error = %v, want %q", err, test.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func TestSignWithAlgorithms(t *testing.T) {
+	key := testPrivateKey(t)
+
+	tests := []struct {
+		hashFunc        crypto.Hash
+		signatureMethod string
+		digestMethod    string
+	}{
+		{crypto.SHA1, RSASHA1, SHA1},
+		{crypto.SHA256, RSASHA256, SHA256},
+	}
+
+	for _, test := range tests {
+		t.Run(test.hashFunc.String(), func(t *testing.T) {
+			signed := signedAssertion(t, key, test.hashFunc)
+
+			for _, want := range []string{
+				`<SignatureMethod Algorithm="` + test.signatureMethod + `">`,
+				`<DigestMethod Algorithm="` + test.digestMethod + `">`,
+				`<CanonicalizationMethod Algorithm="` + C14N + `">`,
+			} {
+				if !strings.Contains(signed, want) {
+					t.Errorf("signed assertion does not contain %s", want)
+				}
+			}
+		})
+	}
+
+	assertion := NewAssertion("issuer", "customer-1", time.Minute)
+	if err := assertion.SignWith(key, crypto.SHA512); err == nil {
+		t.Error("SignWith(SHA512) error = nil, want an error")
+	}
+	if err := assertion.SignWith(nil, crypto.SHA256); err == nil {
+		t.Error("SignWith(nil) error = nil, want an error")
+	}
+}
+
+// TestSignDeterministic checks that fixing the issue time and ID gives
+// byte-identical signed assertions, as RSA PKCS #1 v1.5 signatures are
+// deterministic
+func TestSignDeterministic(t *testing.T) {
+	key := testPrivateKey(t)
+
+	opts := AssertionOptions{
+		Now:   func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) },
+		NewID: func() string { return "_fixed" },
+	}
+
+	var serialized []string
+	for i := 0; i < 2; i++ {
+		assertion, err := NewAssertionWithOptions("issuer", "customer-1", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := assertion.SignWith(key, crypto.SHA256); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := xml.Marshal(assertion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyAssertion(data, &key.PublicKey); err != nil {
+			t.Fatalf("VerifyAssertion() error = %v", err)
+		}
+
+		serialized = append(serialized, string(data))
+	}
+
+	if serialized[0] != serialized[1] {
+		t.Errorf("signed assertions differ:\n%s\n%s", serialized[0], serialized[1])
+	}
+	if !strings.Contains(serialized[0], `ID="_fixed" IssueInstant="2024-01-02T03:04:05`) {
+		t.Errorf("signed assertion does not use the fixed ID and time:\n%s", serialized[0])
+	}
+}
@@ -0,0 +1,130 @@
+package intuit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// waitMaxFailures is the number of consecutive failed polls after which
+// WaitForNewTransactions gives up
+const waitMaxFailures = 5
+
+// TransactionCursor marks the posted transactions of an account that have
+// already been seen. The zero cursor has seen nothing; WaitForNewTransactions
+// treats the transactions present at its first poll as seen. Cursors can be
+// persisted as JSON.
+type TransactionCursor struct {
+	// Posted is the latest posted day seen
+	Posted Date `json:"posted"`
+
+	// Seen holds the keys of the transactions posted on that day
+	Seen []string `json:"seen"`
+}
+
+// advance returns the posted transactions in `txns` not covered by the cursor,
+// along with the cursor updated to cover them
+func (cur TransactionCursor) advance(txns Transactions) (Transactions, TransactionCursor) {
+	seen := make(map[string]bool, len(cur.Seen))
+	for _, key := range cur.Seen {
+		seen[key] = true
+	}
+
+	next := TransactionCursor{Posted: cur.Posted, Seen: append([]string(nil), cur.Seen...)}
+
+	var fresh Transactions
+	for _, t := range txns.Where(FilterPosted()) {
+		day := t.PostedDay()
+		if day.Before(cur.Posted) || (day == cur.Posted && seen[t.Key()]) {
+			continue
+		}
+
+		fresh = append(fresh, t)
+
+		switch {
+		case day.After(next.Posted):
+			next.Posted = day
+			next.Seen = []string{t.Key()}
+		case day == next.Posted:
+			next.Seen = append(next.Seen, t.Key())
+		}
+	}
+
+	return fresh, next
+}
+
+// WaitForNewTransactions polls the account every `pollInterval` until posted
+// transactions not covered by `since` appear, and returns them with a cursor
+// covering them to pass to the next call. Only the days from the cursor's
+// posted day to today are fetched on each poll, so polling stays cheap;
+// transactions that post on days before the cursor's are not reported.
+//
+// A zero cursor reports nothing from the first poll, and waits for
+// transactions that post after it. If pollInterval is not positive,
+// DefaultWatchInterval is used; polls are timed by the client's Clock. Failed
+// polls are retried with exponential backoff; after several consecutive
+// failures the last error is returned. The wait ends with ctx's error if ctx
+// is done first, or with a DeadlineBudgetError if less than the client's
+// MinStepBudget is left before ctx's deadline when a poll is due.
+func (c *Client) WaitForNewTransactions(ctx context.Context, accountID int64, since TransactionCursor, pollInterval time.Duration) (Transactions, TransactionCursor, error) {
+	clock := clockOrSystem(c.Clock)
+	if pollInterval <= 0 {
+		pollInterval = DefaultWatchInterval
+	}
+
+	baseline := since.Posted.IsZero()
+
+	var failures int
+	for {
+		today := DateOf(clock.Now().UTC())
+
+		start := since.Posted
+		if baseline {
+			start = today
+		}
+
+		// a poll is not started without MinStepBudget left before the
+		// deadline
+		budget := newDeadlineBudget(ctx, 1, c.minStepBudget())
+		pollCtx, cancel, budgetErr := budget.step("poll")
+		if budgetErr != nil {
+			return nil, since, budgetErr
+		}
+
+		list, err := c.AccountTransactionsForDatesContext(pollCtx, accountID, start, today)
+		cancel()
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return nil, since, ctx.Err()
+		case err != nil:
+			failures++
+			if failures >= waitMaxFailures {
+				return nil, since, fmt.Errorf("polling transactions: %w", err)
+			}
+		default:
+			failures = 0
+
+			fresh, next := since.advance(list.All())
+			if baseline {
+				// the first poll of a zero cursor only establishes what has
+				// been seen
+				baseline = false
+				since = next
+				if since.Posted.IsZero() {
+					since.Posted = today
+				}
+			} else if len(fresh) > 0 {
+				return fresh, next, nil
+			}
+		}
+
+		wait := pollInterval
+		for i := 0; i < failures; i++ {
+			wait *= 2
+		}
+
+		if err := sleepClock(ctx, clock, wait); err != nil {
+			return nil, since, err
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package intuit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+func TestWaitForNewTransactionsCursor(t *testing.T) {
+	clock := intuit.NewManualClock(time.Date(2014, 4, 24, 12, 0, 0, 0, time.UTC))
+	_, client := newTestServer(t, intuit.WithClock(clock))
+
+	since := intuit.TransactionCursor{
+		Posted: intuit.Date{Year: 2014, Month: 4, Day: 23},
+		Seen:   []string{"INTUIT-BANK-0001"},
+	}
+
+	fresh, next, err := client.WaitForNewTransactions(context.Background(), testBankingAccount, since, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 900003 is pending
+	if want := []int64{900002}; !equalIDs(transactionIDs(fresh), want) {
+		t.Errorf("fresh IDs %v, want %v", transactionIDs(fresh), want)
+	}
+
+	want := intuit.TransactionCursor{
+		Posted: intuit.Date{Year: 2014, Month: 4, Day: 24},
+		Seen:   []string{"INTUIT-BANK-0002"},
+	}
+	if next.Posted != want.Posted || len(next.Seen) != 1 || next.Seen[0] != want.Seen[0] {
+		t.Errorf("next cursor %+v, want %+v", next, want)
+	}
+}
+
+func TestWaitForNewTransactionsPolls(t *testing.T) {
+	clock := intuit.NewManualClock(time.Date(2014, 4, 24, 12, 0, 0, 0, time.UTC))
+	srv, client := newTestServer(t, intuit.WithClock(clock))
+
+	// posted tomorrow, so the first poll of the zero cursor does not see it
+	srv.AddTransactions(testCustomer, testBankingAccount, "bankingTransactions", intuit.Transaction{
+		ID:                       900004,
+		InstitutionTransactionID: "INTUIT-BANK-0004",
+		PostedDate:               intuit.Timestamp(time.Date(2014, 4, 25, 9, 0, 0, 0, time.UTC)),
+	})
+
+	type result struct {
+		fresh intuit.Transactions
+		next  intuit.TransactionCursor
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		// a zero interval polls every DefaultWatchInterval
+		fresh, next, err := client.WaitForNewTransactions(context.Background(), testBankingAccount, intuit.TransactionCursor{}, 0)
+		done <- result{fresh, next, err}
+	}()
+
+	var res result
+wait:
+	for {
+		select {
+		case res = <-done:
+			break wait
+		case <-time.After(time.Millisecond):
+			clock.Advance(intuit.DefaultWatchInterval)
+		}
+	}
+
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	if want := []int64{900004}; !equalIDs(transactionIDs(res.fresh), want) {
+		t.Errorf("fresh IDs %v, want %v", transactionIDs(res.fresh), want)
+	}
+	if want := (intuit.Date{Year: 2014, Month: 4, Day: 25}); res.next.Posted != want {
+		t.Errorf("next cursor posted on %s, want %s", res.next.Posted, want)
+	}
+}
+
+func TestWaitForNewTransactionsCancelled(t *testing.T) {
+	clock := intuit.NewManualClock(time.Date(2014, 4, 24, 12, 0, 0, 0, time.UTC))
+	_, client := newTestServer(t, intuit.WithClock(clock), intuit.WithMinStepBudget(time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// the clock never moves, so only the context ends the wait
+	since := intuit.TransactionCursor{Posted: intuit.Date{Year: 2014, Month: 4, Day: 24}}
+	if _, _, err := client.WaitForNewTransactions(ctx, testBankingAccount, since, time.Hour); err != nil {
+		t.Fatalf("WaitForNewTransactions() error = %v, want fresh transactions", err)
+	}
+
+	since.Seen = []string{"INTUIT-BANK-0002"}
+	if _, _, err := client.WaitForNewTransactions(ctx, testBankingAccount, since, time.Hour); err != context.DeadlineExceeded {
+		t.Errorf("WaitForNewTransactions() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWaitForNewTransactionsBudget(t *testing.T) {
+	_, client := newTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// less than DefaultMinStepBudget is left for the first poll
+	_, _, err := client.WaitForNewTransactions(ctx, testBankingAccount, intuit.TransactionCursor{}, time.Hour)
+
+	var budgetErr *intuit.DeadlineBudgetError
+	if !errors.As(err, &budgetErr) {
+		t.Errorf("WaitForNewTransactions() error = %v, want a DeadlineBudgetError", err)
+	}
+}
+
+func transactionIDs(txns intuit.Transactions) []int64 {
+	ids := make([]int64, len(txns))
+	for i, t := range txns {
+		ids[i] = t.ID
+	}
+
+	return ids
+}
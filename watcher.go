@@ -0,0 +1,225 @@
+package intuit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval is the polling interval of a Watcher without one
+const DefaultWatchInterval = time.Minute * 15
+
+// Event is a change detected by a Watcher: a NewTransaction, BalanceChanged,
+// AggregationFailed or CredentialsNeeded
+type Event interface {
+	// EventAccount returns the account the event concerns, as of the poll
+	// that detected it
+	EventAccount() Account
+}
+
+// NewTransaction is emitted for each transaction that posts to an account
+type NewTransaction struct {
+	Account     Account
+	Transaction Transaction
+}
+
+// BalanceChanged is emitted when an account's balance changes
+type BalanceChanged struct {
+	Account  Account
+	Previous Money
+}
+
+// AggregationFailed is emitted when an account's aggregation starts failing
+// for a reason the end user cannot fix
+type AggregationFailed struct {
+	Account  Account
+	Previous AggregationStatus
+}
+
+// CredentialsNeeded is emitted when an account's aggregation starts failing
+// until the end user updates their credentials or answers a challenge
+type CredentialsNeeded struct {
+	Account  Account
+	Previous AggregationStatus
+}
+
+// EventAccount implements Event
+func (e NewTransaction) EventAccount() Account { return e.Account }
+
+// EventAccount implements Event
+func (e BalanceChanged) EventAccount() Account { return e.Account }
+
+// EventAccount implements Event
+func (e AggregationFailed) EventAccount() Account { return e.Account }
+
+// EventAccount implements Event
+func (e CredentialsNeeded) EventAccount() Account { return e.Account }
+
+// Watcher polls a customer's accounts and transactions and reports the
+// changes between polls as events, since CAD has no webhooks. The first poll
+// only records the current state; so does the first poll after an account
+// appears.
+type Watcher struct {
+	Client *Client
+
+	// Interval is the delay between polls in Run. If zero,
+	// DefaultWatchInterval is used.
+	Interval time.Duration
+
+	// Clock times the polls in Run. If nil, the client's clock is used.
+	Clock Clock
+
+	// Projection selects the parts of each new transaction that are decoded.
+	// The zero value, ProjectFull, decodes them all.
+	Projection Projection
+
+	// Policy decides which aggregation status changes are reported: a change
+	// to a status whose rule is ReaggregationUserAction is reported as
+	// CredentialsNeeded, and one to any other status that needs action as
+	// AggregationFailed. If nil, DefaultReaggregationPolicy is used.
+	Policy *ReaggregationPolicy
+
+	mu       sync.Mutex
+	accounts []Account
+	cursors  map[int64]TransactionCursor
+}
+
+// NewWatcher returns a watcher for the client's customer
+func NewWatcher(c *Client, interval time.Duration) *Watcher {
+	return &Watcher{Client: c, Interval: interval}
+}
+
+// Poll fetches the customer's accounts and new transactions and returns the
+// changes since the previous poll. If it fails, the watcher's state is left
+// unchanged, so the next poll reports the changes instead.
+func (w *Watcher) Poll(ctx context.Context) ([]Event, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	accounts, err := w.Client.GetCustomerAccountsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := accountEvents(DiffAccounts(w.accounts, accounts), w.policy())
+
+	today := DateOf(w.Client.now().UTC())
+	nextCursors := make(map[int64]TransactionCursor, len(accounts))
+
+	for _, account := range accounts {
+		cursor, tracked := w.cursors[account.ID]
+		if !account.IsActive() {
+			if tracked {
+				nextCursors[account.ID] = cursor
+			}
+			continue
+		}
+
+		start := cursor.Posted
+		if !tracked {
+			start = today
+		}
+
+		list, err := w.Client.AccountTransactionsProjectedContext(ctx, account.ID, start, today, w.Projection)
+		if err != nil {
+			return nil, fmt.Errorf("polling transactions of account %d: %w", account.ID, err)
+		}
+
+		fresh, next := cursor.advance(list.All())
+		if next.Posted.IsZero() {
+			next.Posted = today
+		}
+		nextCursors[account.ID] = next
+
+		if !tracked {
+			continue
+		}
+
+		for _, t := range fresh {
+			events = append(events, NewTransaction{Account: account, Transaction: t})
+		}
+	}
+
+	w.accounts, w.cursors = accounts, nextCursors
+
+	return events, nil
+}
+
+func (w *Watcher) policy() ReaggregationPolicy {
+	if w.Policy != nil {
+		return *w.Policy
+	}
+
+	return DefaultReaggregationPolicy()
+}
+
+// accountEvents returns the events for the changes between two snapshots of
+// the accounts. Added accounts have no events until their next poll.
+func accountEvents(changes AccountChanges, policy ReaggregationPolicy) []Event {
+	var events []Event
+
+	for _, change := range changes.BalanceChanges {
+		events = append(events, BalanceChanged{Account: change.Account, Previous: change.Previous})
+	}
+
+	for _, change := range changes.StatusChanges {
+		if change.Account.AggrStatusCode == change.PreviousAggrStatus {
+			continue
+		}
+
+		switch policy.ForAccount(change.Account).Action {
+		case ReaggregationNone:
+		case ReaggregationUserAction:
+			events = append(events, CredentialsNeeded{Account: change.Account, Previous: change.PreviousAggrStatus})
+		default:
+			events = append(events, AggregationFailed{Account: change.Account, Previous: change.PreviousAggrStatus})
+		}
+	}
+
+	return events
+}
+
+// Run polls every Interval until ctx is done, passing each event to `handle`.
+// Failed polls are retried with exponential backoff; after several
+// consecutive failures the last error is returned. Otherwise Run returns ctx's
+// error.
+func (w *Watcher) Run(ctx context.Context, handle func(Event)) error {
+	clock := w.Clock
+	if clock == nil {
+		clock = clockOrSystem(w.Client.Clock)
+	}
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	var failures int
+	for {
+		events, err := w.Poll(ctx)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			failures++
+			if failures >= waitMaxFailures {
+				return fmt.Errorf("watching customer %s: %w", w.Client.CustomerLabel(), err)
+			}
+		default:
+			failures = 0
+			for _, event := range events {
+				handle(event)
+			}
+		}
+
+		wait := interval
+		for i := 0; i < failures; i++ {
+			wait *= 2
+		}
+
+		if err := sleepClock(ctx, clock, wait); err != nil {
+			return err
+		}
+	}
+}
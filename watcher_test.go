@@ -0,0 +1,141 @@
+package intuit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	intuit "github.com/bodetree/intuit-cad"
+)
+
+func TestWatcherPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *intuit.ReaggregationPolicy
+		want   []string
+	}{
+		{
+			name: "default",
+			want: []string{
+				"intuit.CredentialsNeeded 400107846791",
+				"intuit.AggregationFailed 400107846787",
+			},
+		},
+		{
+			name: "custom",
+			policy: &intuit.ReaggregationPolicy{
+				Rules: map[intuit.AggregationStatus]intuit.ReaggregationRule{
+					intuit.AggrStatusFinancialInstitutionError: {Action: intuit.ReaggregationUserAction},
+				},
+			},
+			want: []string{"intuit.CredentialsNeeded 400107846787"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv, client := newTestServer(t)
+
+			w := intuit.NewWatcher(client, time.Hour)
+			w.Policy = test.policy
+
+			if _, err := w.Poll(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			setAggregationStatus(t, srv, client, time.Now(), map[int64]intuit.AggregationStatus{
+				400107846791:       intuit.AggrStatusMFARequired,
+				testBankingAccount: intuit.AggrStatusFinancialInstitutionError,
+			})
+
+			events, err := w.Poll(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]bool, len(events))
+			for _, event := range events {
+				got[fmt.Sprintf("%T %d", event, event.EventAccount().ID)] = true
+			}
+			if len(got) != len(test.want) {
+				t.Errorf("got events %v, want %v", got, test.want)
+			}
+			for _, want := range test.want {
+				if !got[want] {
+					t.Errorf("missing event %s among %v", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWatcherEvents(t *testing.T) {
+	clock := intuit.NewManualClock(time.Date(2014, 4, 24, 12, 0, 0, 0, time.UTC))
+	srv, client := newTestServer(t, intuit.WithClock(clock))
+
+	w := intuit.NewWatcher(client, time.Hour)
+
+	// the first poll only records the current state
+	events, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("first poll returned %d events, want none", len(events))
+	}
+
+	accounts, err := client.GetCustomerAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var account intuit.Account
+	for _, a := range accounts {
+		if a.ID == testBankingAccount {
+			account = a
+		}
+	}
+
+	previous := account.Balance
+	account.Balance += 1250
+	srv.AddAccounts(testCustomer, account)
+	srv.AddTransactions(testCustomer, testBankingAccount, "bankingTransactions", intuit.Transaction{
+		ID:                       900005,
+		InstitutionTransactionID: "INTUIT-BANK-0005",
+		PostedDate:               intuit.Timestamp(time.Date(2014, 4, 24, 10, 0, 0, 0, time.UTC)),
+		Amount:                   1250,
+	})
+
+	events, err = w.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fresh []int64
+	var balances []intuit.BalanceChanged
+	for _, event := range events {
+		switch event := event.(type) {
+		case intuit.NewTransaction:
+			if event.Account.ID != testBankingAccount {
+				t.Errorf("new transaction %d on account %d, want %d", event.Transaction.ID, event.Account.ID, testBankingAccount)
+			}
+			fresh = append(fresh, event.Transaction.ID)
+		case intuit.BalanceChanged:
+			balances = append(balances, event)
+		default:
+			t.Errorf("unexpected event %T for account %d", event, event.EventAccount().ID)
+		}
+	}
+
+	if want := []int64{900005}; !equalIDs(fresh, want) {
+		t.Errorf("new transactions %v, want %v", fresh, want)
+	}
+	if len(balances) != 1 || balances[0].Account.ID != testBankingAccount || balances[0].Previous != previous || balances[0].Account.Balance != account.Balance {
+		t.Errorf("balance changes %+v, want %s to %s on account %d", balances, previous, account.Balance, testBankingAccount)
+	}
+
+	// nothing changed since
+	if events, err := w.Poll(context.Background()); err != nil || len(events) != 0 {
+		t.Errorf("third poll returned %d events and error %v, want none", len(events), err)
+	}
+}
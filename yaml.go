@@ -0,0 +1,179 @@
+package intuit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// yamlToJSON converts a YAML document to JSON for decoding into `target`.
+// Scalars read into string fields of target keep their text as written, so
+// that e.g. an all-digit consumer key is not a number and a customer ID with
+// leading zeros keeps them.
+func yamlToJSON(data []byte, target interface{}) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) == 0 {
+		return []byte("{}"), nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: the document must be a mapping", root.Line)
+	}
+
+	value, err := yamlNodeValue(root, reflect.TypeOf(target))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+// yamlNodeValue returns the value of the node read into a field of type t,
+// which is nil if the field is unknown
+func yamlNodeValue(n *yaml.Node, t reflect.Type) (interface{}, error) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch n.Kind {
+	case yaml.AliasNode:
+		return yamlNodeValue(n.Alias, t)
+
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			keyNode, valueNode := n.Content[i], n.Content[i+1]
+			if keyNode.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: mapping keys must be scalars", keyNode.Line)
+			}
+
+			key := keyNode.Value
+			if _, ok := m[key]; ok {
+				return nil, fmt.Errorf("line %d: duplicate key %q", keyNode.Line, key)
+			}
+
+			var fieldType reflect.Type
+			if t != nil && t.Kind() == reflect.Map {
+				fieldType = t.Elem()
+			} else {
+				fieldType = jsonFieldType(t, key)
+			}
+
+			value, err := yamlNodeValue(valueNode, fieldType)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+
+		return m, nil
+
+	case yaml.SequenceNode:
+		var elemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elemType = t.Elem()
+		}
+
+		list := make([]interface{}, len(n.Content))
+		for i, item := range n.Content {
+			value, err := yamlNodeValue(item, elemType)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+
+		return list, nil
+
+	case yaml.ScalarNode:
+		return yamlScalarValue(n, t)
+	}
+
+	return nil, fmt.Errorf("line %d: unsupported YAML node", n.Line)
+}
+
+// yamlScalarValue returns the value of a scalar read into a field of type t
+func yamlScalarValue(n *yaml.Node, t reflect.Type) (interface{}, error) {
+	tag := n.ShortTag()
+	if tag == "!!null" {
+		return nil, nil
+	}
+
+	if t != nil && t.Kind() == reflect.String {
+		return n.Value, nil
+	}
+
+	switch tag {
+	case "!!int", "!!float":
+		// numbers are kept as written if they are valid JSON numbers
+		var number json.Number
+		if err := json.Unmarshal([]byte(n.Value), &number); err == nil {
+			return number, nil
+		}
+		fallthrough
+	case "!!bool":
+		var value interface{}
+		if err := n.Decode(&value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		if f, ok := value.(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+			return nil, fmt.Errorf("line %d: %s is not a finite number", n.Line, n.Value)
+		}
+
+		return value, nil
+	}
+
+	return n.Value, nil
+}
+
+// jsonFieldType returns the type of the field of struct type t that
+// encoding/json decodes `name` into, with pointers dereferenced, or nil if
+// there is none
+func jsonFieldType(t reflect.Type, name string) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var match reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		fieldName := strings.Split(field.Tag.Get("json"), ",")[0]
+		switch fieldName {
+		case "-":
+			continue
+		case "":
+			fieldName = field.Name
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		// like encoding/json, prefer an exact match to a case-insensitive one
+		if fieldName == name {
+			return fieldType
+		}
+		if match == nil && strings.EqualFold(fieldName, name) {
+			match = fieldType
+		}
+	}
+
+	return match
+}